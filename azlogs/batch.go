@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+	"github.com/codyseavey/tools/azlogs/internal/ui"
+)
+
+// batchDelimiter separates queries in a batch file. Files without it are
+// split on blank lines instead.
+const batchDelimiter = ";;"
+
+// batchQuery is one query from a batch file
+type batchQuery struct {
+	Label string
+	Query string
+}
+
+// splitBatch splits a batch file into its queries. A query whose first line
+// is a comment is labeled with that comment, otherwise with its position.
+func splitBatch(content string) []batchQuery {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var blocks []string
+	var current []string
+	hasDelimiter := false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == batchDelimiter {
+			hasDelimiter = true
+			break
+		}
+	}
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if (hasDelimiter && trimmed == batchDelimiter) || (!hasDelimiter && trimmed == "") {
+			blocks = append(blocks, strings.Join(current, "\n"))
+			current = nil
+			continue
+		}
+		current = append(current, line)
+	}
+	blocks = append(blocks, strings.Join(current, "\n"))
+
+	var queries []batchQuery
+	for _, block := range blocks {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+
+		label := fmt.Sprintf("query %d", len(queries)+1)
+		first, _, _ := strings.Cut(block, "\n")
+		if comment, ok := strings.CutPrefix(strings.TrimSpace(first), "//"); ok && strings.TrimSpace(comment) != "" {
+			label = strings.TrimSpace(comment)
+		}
+
+		// A block of nothing but comments has no query to run
+		if strings.TrimSpace(ui.StripKQLComments(block)) == "" {
+			continue
+		}
+		queries = append(queries, batchQuery{Label: label, Query: block})
+	}
+	return queries
+}
+
+// unsafeFileChars matches characters not used in batch output file names
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// batchFileName returns the output file name for the i-th (zero-based)
+// query, with the output format as the extension
+func batchFileName(i int, label, format string) string {
+	slug := strings.Trim(unsafeFileChars.ReplaceAllString(strings.ToLower(label), "-"), "-")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	ext := format
+	switch format {
+	case formatMarkdown:
+		ext = "md"
+	case formatTemplate:
+		ext = "txt"
+	}
+	return fmt.Sprintf("%02d-%s.%s", i+1, slug, ext)
+}
+
+// runBatch runs every query in a batch file in order. Results go to stdout
+// under a header per query, or to one file per query when outDir is set.
+// Failures are collected and reported at the end unless failFast is set.
+func runBatch(workspaceID, path, outDir string, output outputOptions, failFast bool, params map[string]interface{}, authMethod azure.AuthMethod, config *azure.Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read batch file: %w", err)
+	}
+	queries := splitBatch(string(data))
+	if len(queries) == 0 {
+		return fmt.Errorf("no queries found in %s", path)
+	}
+
+	if outDir != "" {
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	client, err := newQueryClient(workspaceID, authMethod, config)
+	if err != nil {
+		return err
+	}
+
+	var failures []string
+	for i, q := range queries {
+		fmt.Fprintf(os.Stderr, "[%d/%d] %s...\n", i+1, len(queries), q.Label)
+		if err := runBatchQuery(client, i, q, outDir, output, params); err != nil {
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s failed: %v\n", i+1, len(queries), q.Label, err)
+			failures = append(failures, fmt.Sprintf("%s: %v", q.Label, err))
+			if failFast {
+				break
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "\n%d of %d queries succeeded\n", len(queries)-len(failures), len(queries))
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// runBatchQuery runs one batch query and writes its first result table
+func runBatchQuery(client azure.QueryClient, i int, q batchQuery, outDir string, output outputOptions, params map[string]interface{}) error {
+	result, err := client.QueryWithParameters(context.Background(), q.Query, nil, params)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if outDir != "" {
+		f, err := os.Create(filepath.Join(outDir, batchFileName(i, q.Label, output.format)))
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	} else if output.format == formatTSV {
+		// JSON output stays machine-readable; labels are on stderr
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "=== %s ===\n", q.Label)
+	} else if output.format == formatMarkdown {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "### %s\n\n", q.Label)
+	}
+
+	if len(result.Tables) > 0 {
+		if err := writeTable(out, result.Tables[0], output); err != nil {
+			return fmt.Errorf("failed to write results: %w", err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d rows returned in %s\n", result.RowCount, result.Duration)
+	printResultWarnings(result)
+	return nil
+}
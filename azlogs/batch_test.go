@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestSplitBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []batchQuery
+	}{
+		{
+			name:    "delimiter keeps blank lines inside queries",
+			content: "// Errors\nAppExceptions\n\n| take 5\n;;\nAppRequests | count\n;;\n",
+			expected: []batchQuery{
+				{Label: "Errors", Query: "// Errors\nAppExceptions\n\n| take 5"},
+				{Label: "query 2", Query: "AppRequests | count"},
+			},
+		},
+		{
+			name:    "blank lines without delimiter",
+			content: "A | take 1\r\n\r\n\r\nB\n| take 2\n",
+			expected: []batchQuery{
+				{Label: "query 1", Query: "A | take 1"},
+				{Label: "query 2", Query: "B\n| take 2"},
+			},
+		},
+		{
+			name:     "comment-only blocks are skipped",
+			content:  "// just a note\n\nT",
+			expected: []batchQuery{{Label: "query 1", Query: "T"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitBatch(tt.content)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %d queries, got %d: %+v", len(tt.expected), len(got), got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Query %d: expected %+v, got %+v", i, tt.expected[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBatchFileName(t *testing.T) {
+	if got := batchFileName(0, "Top talkers (by bytes)", "tsv"); got != "01-top-talkers-by-bytes.tsv" {
+		t.Errorf("Expected '01-top-talkers-by-bytes.tsv', got '%s'", got)
+	}
+	if got := batchFileName(11, "query 12", "jsonl"); got != "12-query-12.jsonl" {
+		t.Errorf("Expected '12-query-12.jsonl', got '%s'", got)
+	}
+	if got := batchFileName(0, "errors", formatMarkdown); got != "01-errors.md" {
+		t.Errorf("Expected '01-errors.md', got '%s'", got)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// checkTimeout limits each --check step
+const checkTimeout = 30 * time.Second
+
+// Check outcomes that aren't errors from Azure
+var (
+	errSkipped     = errors.New("skipped") // The check doesn't apply to the current setup
+	errNoWorkspace = errors.New("no workspace ID")
+)
+
+// check is one step of --check. Steps after a failed critical step are
+// skipped, since they depend on it.
+type check struct {
+	name     string
+	critical bool
+	run      func(ctx context.Context) error
+	hint     func(err error) string // Remediation for a failure, may be nil
+}
+
+// runChecks runs the checks in order, writing PASS, FAIL, WARN or SKIP for
+// each, and returns the exit code: 1 if a critical check failed
+func runChecks(ctx context.Context, w io.Writer, checks []check) int {
+	code := exitOK
+	for _, c := range checks {
+		if code != exitOK {
+			fmt.Fprintf(w, "[SKIP] %s\n", c.name)
+			continue
+		}
+
+		stepCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := c.run(stepCtx)
+		cancel()
+		switch {
+		case err == nil:
+			fmt.Fprintf(w, "[PASS] %s\n", c.name)
+			continue
+		case errors.Is(err, errSkipped):
+			fmt.Fprintf(w, "[SKIP] %s: %v\n", c.name, err)
+			continue
+		case c.critical:
+			fmt.Fprintf(w, "[FAIL] %s: %v\n", c.name, err)
+			code = exitQueryError
+		default:
+			fmt.Fprintf(w, "[WARN] %s: %v\n", c.name, err)
+		}
+		if c.hint != nil {
+			if hint := c.hint(err); hint != "" {
+				fmt.Fprintf(w, "       Hint: %s\n", hint)
+			}
+		}
+	}
+	return code
+}
+
+// connectivityChecks returns the --check steps: signing in, getting a token
+// for Log Analytics, running a trivial query and reaching Azure OpenAI.
+// Later steps use what earlier ones set up.
+func connectivityChecks(workspaceID string, authMethod azure.AuthMethod, config *azure.Config) []check {
+	var auth *azure.Authenticator
+	cloud := config.AzureCloud()
+	return []check{
+		{
+			name:     fmt.Sprintf("Credential (%s)", authMethod),
+			critical: true,
+			run: func(ctx context.Context) error {
+				var err error
+				auth, err = azure.NewCloudAuthenticator(authMethod, cloud)
+				return err
+			},
+			hint: func(error) string {
+				return "Sign in with 'az login', or choose another method with --auth"
+			},
+		},
+		{
+			name:     "Sign in for Log Analytics",
+			critical: true,
+			run: func(ctx context.Context) error {
+				if config.Cluster != "" {
+					return fmt.Errorf("%w: a Data Explorer cluster is queried instead", errSkipped)
+				}
+				return auth.Validate(ctx)
+			},
+			hint: func(error) string {
+				return fmt.Sprintf("Check that the account can sign in to the %s cloud (--cloud), and run 'az login' again if the session expired", cloud.Name)
+			},
+		},
+		{
+			name:     "Query the workspace",
+			critical: true,
+			run: func(ctx context.Context) error {
+				if workspaceID == "" {
+					return errNoWorkspace
+				}
+				client, err := azure.NewQueryClient(auth.GetCredential(), workspaceID, config)
+				if err != nil {
+					return err
+				}
+				_, err = client.Query(ctx, "print 1", nil)
+				return err
+			},
+			hint: queryCheckHint,
+		},
+		{
+			name: "Azure OpenAI (AI suggestions)",
+			run: func(ctx context.Context) error {
+				endpoint, err := config.AIEndpoint()
+				if err != nil {
+					return fmt.Errorf("%w: %v", errSkipped, err)
+				}
+				client := azure.NewOpenAIClient(auth.GetCredential(), endpoint, config.OpenAIDeployment)
+				client.SetCloud(auth.Cloud())
+				_, err = client.Complete(ctx, []azure.ChatMessage{{Role: "user", Content: "Reply with OK"}}, 5)
+				return err
+			},
+			hint: func(error) string {
+				return "Queries still work; only AI suggestions, explanations and fixes are unavailable"
+			},
+		},
+	}
+}
+
+// queryCheckHint suggests a fix for a failed test query
+func queryCheckHint(err error) string {
+	switch {
+	case errors.Is(err, errNoWorkspace):
+		return "Pass -w, set AZURE_LOG_ANALYTICS_WORKSPACE_ID or default_workspace in config, or use --cluster and --database"
+	case errors.Is(err, azure.ErrUnauthorized):
+		return "Ask for the Log Analytics Reader role on the workspace"
+	case errors.Is(err, azure.ErrWorkspaceNotFound):
+		return "Check the workspace ID: it's the GUID on the workspace's Overview page in the portal"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "Check network access to the Log Analytics endpoint, including proxies and firewalls"
+	}
+	return ""
+}
+
+// runCheck runs the connectivity checks, returning the exit code
+func runCheck(workspaceID string, authMethod azure.AuthMethod, config *azure.Config, w io.Writer) int {
+	fmt.Fprintf(w, "Checking the %s cloud, signing in with %s\n\n", config.AzureCloud().Name, authMethod)
+	return runChecks(context.Background(), w, connectivityChecks(workspaceID, authMethod, config))
+}
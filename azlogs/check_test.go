@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestRunChecks(t *testing.T) {
+	pass := func(context.Context) error { return nil }
+	fail := func(err error) func(context.Context) error {
+		return func(context.Context) error { return err }
+	}
+	hint := func(error) string { return "Try again" }
+
+	tests := []struct {
+		name     string
+		checks   []check
+		expected string
+		code     int
+	}{
+		{
+			"all pass",
+			[]check{{name: "Sign in", critical: true, run: pass}, {name: "Query", critical: true, run: pass}},
+			"[PASS] Sign in\n[PASS] Query\n",
+			exitOK,
+		},
+		{
+			"critical failure skips the rest",
+			[]check{
+				{name: "Sign in", critical: true, run: fail(errors.New("no credential")), hint: hint},
+				{name: "Query", critical: true, run: pass},
+			},
+			"[FAIL] Sign in: no credential\n       Hint: Try again\n[SKIP] Query\n",
+			exitQueryError,
+		},
+		{
+			"optional failure warns",
+			[]check{
+				{name: "Skipped", critical: true, run: fail(fmt.Errorf("%w: not used", errSkipped))},
+				{name: "OpenAI", run: fail(errors.New("timeout")), hint: hint},
+				{name: "Query", critical: true, run: pass},
+			},
+			"[SKIP] Skipped: skipped: not used\n[WARN] OpenAI: timeout\n       Hint: Try again\n[PASS] Query\n",
+			exitOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if code := runChecks(context.Background(), &buf, tt.checks); code != tt.code {
+				t.Errorf("Expected exit code %d, got %d", tt.code, code)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestQueryCheckHint(t *testing.T) {
+	err := &azure.QueryError{Kind: azure.ErrWorkspaceNotFound, Err: errors.New("not found")}
+	if hint := queryCheckHint(err); hint == "" {
+		t.Error("Expected a hint for a missing workspace")
+	}
+	if hint := queryCheckHint(errNoWorkspace); hint == "" {
+		t.Error("Expected a hint when no workspace ID is set")
+	}
+}
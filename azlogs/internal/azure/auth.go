@@ -3,6 +3,8 @@ package azure
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
@@ -21,6 +23,9 @@ const (
 	AuthBrowser
 	// AuthManagedIdentity uses Azure Managed Identity
 	AuthManagedIdentity
+	// AuthEnvironment uses a service principal or user from AZURE_*
+	// environment variables
+	AuthEnvironment
 )
 
 // String returns the string representation of the auth method
@@ -34,6 +39,8 @@ func (a AuthMethod) String() string {
 		return "Interactive Browser"
 	case AuthManagedIdentity:
 		return "Managed Identity"
+	case AuthEnvironment:
+		return "Environment"
 	default:
 		return "Unknown"
 	}
@@ -43,36 +50,75 @@ func (a AuthMethod) String() string {
 type Authenticator struct {
 	credential azcore.TokenCredential
 	method     AuthMethod
+	cloud      Cloud
 }
 
 // NewAuthenticator creates a new authenticator with the specified method
+// for the public cloud
 func NewAuthenticator(method AuthMethod) (*Authenticator, error) {
+	return NewCloudAuthenticator(method, CloudPublic)
+}
+
+// NewCloudAuthenticator creates an authenticator that signs in to the given
+// cloud. Azure CLI credentials use the cloud set with `az cloud set`.
+func NewCloudAuthenticator(method AuthMethod, c Cloud) (*Authenticator, error) {
 	var cred azcore.TokenCredential
 	var err error
+	clientOptions := azcore.ClientOptions{Cloud: c.Configuration}
 
 	switch method {
 	case AuthDefault:
-		cred, err = azidentity.NewDefaultAzureCredential(nil)
+		cred, err = azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{ClientOptions: clientOptions})
 	case AuthCLI:
 		cred, err = azidentity.NewAzureCLICredential(nil)
 	case AuthBrowser:
-		cred, err = azidentity.NewInteractiveBrowserCredential(nil)
+		cred, err = azidentity.NewInteractiveBrowserCredential(&azidentity.InteractiveBrowserCredentialOptions{ClientOptions: clientOptions})
 	case AuthManagedIdentity:
-		cred, err = azidentity.NewManagedIdentityCredential(nil)
+		cred, err = azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{ClientOptions: clientOptions})
+	case AuthEnvironment:
+		if missing := missingEnvironmentVars(os.Getenv); len(missing) > 0 {
+			return nil, fmt.Errorf("environment credential needs %s to be set", strings.Join(missing, ", "))
+		}
+		cred, err = azidentity.NewEnvironmentCredential(&azidentity.EnvironmentCredentialOptions{ClientOptions: clientOptions})
 	default:
 		return nil, fmt.Errorf("unknown auth method: %d", method)
 	}
 
 	if err != nil {
+		logger.Debug("creating credential failed", "method", method.String(), "error", err)
 		return nil, fmt.Errorf("failed to create credential: %w", err)
 	}
+	logger.Debug("using credential", "method", method.String(), "cloud", c.Name, "authority", c.Configuration.ActiveDirectoryAuthorityHost)
 
 	return &Authenticator{
 		credential: cred,
 		method:     method,
+		cloud:      c,
 	}, nil
 }
 
+// missingEnvironmentVars lists the AZURE_* variables an environment
+// credential still needs: the tenant and client IDs, and a client secret,
+// a certificate or a username and password
+func missingEnvironmentVars(getenv func(string) string) []string {
+	var missing []string
+	for _, name := range []string{"AZURE_TENANT_ID", "AZURE_CLIENT_ID"} {
+		if getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	switch {
+	case getenv("AZURE_CLIENT_SECRET") != "", getenv("AZURE_CLIENT_CERTIFICATE_PATH") != "":
+	case getenv("AZURE_USERNAME") != "":
+		if getenv("AZURE_PASSWORD") == "" {
+			missing = append(missing, "AZURE_PASSWORD")
+		}
+	default:
+		missing = append(missing, "AZURE_CLIENT_SECRET (or AZURE_CLIENT_CERTIFICATE_PATH, or AZURE_USERNAME and AZURE_PASSWORD)")
+	}
+	return missing
+}
+
 // GetCredential returns the Azure credential
 func (a *Authenticator) GetCredential() azcore.TokenCredential {
 	return a.credential
@@ -83,13 +129,20 @@ func (a *Authenticator) Method() AuthMethod {
 	return a.method
 }
 
+// Cloud returns the cloud the authenticator signs in to
+func (a *Authenticator) Cloud() Cloud {
+	return a.cloud
+}
+
 // Validate checks if the credential is valid by attempting to get a token
 func (a *Authenticator) Validate(ctx context.Context) error {
 	_, err := a.credential.GetToken(ctx, policy.TokenRequestOptions{
-		Scopes: []string{"https://api.loganalytics.io/.default"},
+		Scopes: []string{a.cloud.LogAnalyticsScope()},
 	})
 	if err != nil {
+		logger.Debug("credential validation failed", "method", a.method.String(), "error", err)
 		return fmt.Errorf("failed to validate credentials: %w", err)
 	}
+	logger.Debug("credential validated", "method", a.method.String())
 	return nil
 }
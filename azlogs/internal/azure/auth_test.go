@@ -2,6 +2,7 @@ package azure
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 )
@@ -61,6 +62,7 @@ func TestAuthMethodString(t *testing.T) {
 		{AuthCLI, "Azure CLI"},
 		{AuthBrowser, "Interactive Browser"},
 		{AuthManagedIdentity, "Managed Identity"},
+		{AuthEnvironment, "Environment"},
 		{AuthMethod(99), "Unknown"},
 	}
 
@@ -72,3 +74,26 @@ func TestAuthMethodString(t *testing.T) {
 		})
 	}
 }
+
+func TestMissingEnvironmentVars(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected []string
+	}{
+		{"client secret", map[string]string{"AZURE_TENANT_ID": "t", "AZURE_CLIENT_ID": "c", "AZURE_CLIENT_SECRET": "s"}, nil},
+		{"certificate", map[string]string{"AZURE_TENANT_ID": "t", "AZURE_CLIENT_ID": "c", "AZURE_CLIENT_CERTIFICATE_PATH": "cert.pem"}, nil},
+		{"username without password", map[string]string{"AZURE_TENANT_ID": "t", "AZURE_CLIENT_ID": "c", "AZURE_USERNAME": "u"}, []string{"AZURE_PASSWORD"}},
+		{"nothing set", nil, []string{"AZURE_TENANT_ID", "AZURE_CLIENT_ID",
+			"AZURE_CLIENT_SECRET (or AZURE_CLIENT_CERTIFICATE_PATH, or AZURE_USERNAME and AZURE_PASSWORD)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing := missingEnvironmentVars(func(name string) string { return tt.env[name] })
+			if strings.Join(missing, "; ") != strings.Join(tt.expected, "; ") {
+				t.Errorf("Expected %q missing, got %q", tt.expected, missing)
+			}
+		})
+	}
+}
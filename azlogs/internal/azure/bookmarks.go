@@ -0,0 +1,95 @@
+package azure
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookmarkEntry is a specific query saved with a note
+type BookmarkEntry struct {
+	ID          string    `json:"id"`
+	Query       string    `json:"query"`
+	Description string    `json:"description"`
+	Workspace   string    `json:"workspace,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Bookmarks manages bookmarked queries
+type Bookmarks struct {
+	Entries  []BookmarkEntry `json:"entries"`
+	filePath string
+}
+
+// NewBookmarks creates a new bookmarks manager
+func NewBookmarks() *Bookmarks {
+	b := &Bookmarks{
+		Entries: []BookmarkEntry{},
+	}
+	b.setDefaultPath()
+	return b
+}
+
+// setDefaultPath sets the default bookmarks file path
+func (b *Bookmarks) setDefaultPath() {
+	b.filePath = filepath.Join(ConfigDir(), "bookmarks.json")
+}
+
+// Load reads bookmarks from disk
+func (b *Bookmarks) Load() error {
+	return readJSONFile(b.filePath, b)
+}
+
+// Save writes bookmarks to disk
+func (b *Bookmarks) Save() error {
+	// Ensure directory exists
+	dir := filepath.Dir(b.filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(b.filePath, data, 0644)
+}
+
+// Add bookmarks a query, newest first
+func (b *Bookmarks) Add(query, description, workspace string) *BookmarkEntry {
+	entry := BookmarkEntry{
+		ID:          uuid.New().String(),
+		Query:       query,
+		Description: description,
+		Workspace:   workspace,
+		CreatedAt:   time.Now(),
+	}
+
+	b.Entries = append([]BookmarkEntry{entry}, b.Entries...)
+	return &b.Entries[0]
+}
+
+// Delete removes a bookmark by ID
+func (b *Bookmarks) Delete(id string) bool {
+	for i, entry := range b.Entries {
+		if entry.ID == id {
+			b.Entries = append(b.Entries[:i], b.Entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// GetAll returns all bookmarks
+func (b *Bookmarks) GetAll() []BookmarkEntry {
+	return b.Entries
+}
+
+// Count returns the number of bookmarks
+func (b *Bookmarks) Count() int {
+	return len(b.Entries)
+}
@@ -0,0 +1,169 @@
+package azure
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// cacheEntry is a cached query result with the time it was stored
+type cacheEntry struct {
+	Result   *QueryResult `json:"result"`
+	StoredAt time.Time    `json:"stored_at"`
+}
+
+// ResultCache caches query results for a limited time, keyed by workspace,
+// query and timespan. Entries are kept in memory and optionally on disk.
+type ResultCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	ttl     time.Duration
+	dir     string // On-disk cache directory, empty for memory only
+}
+
+// NewResultCache creates a cache whose entries expire after ttl.
+// A ttl of zero or less disables caching.
+func NewResultCache(ttl time.Duration) *ResultCache {
+	return &ResultCache{
+		entries: make(map[string]cacheEntry),
+		ttl:     ttl,
+	}
+}
+
+// EnableDisk additionally persists entries as files in dir
+func (c *ResultCache) EnableDisk(dir string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dir = dir
+}
+
+// Enabled reports whether the cache stores results
+func (c *ResultCache) Enabled() bool {
+	return c != nil && c.ttl > 0
+}
+
+// cacheKey builds the cache key for a query
+func cacheKey(workspaceID, query string, timespan *TimeSpan) string {
+	h := sha256.New()
+	h.Write([]byte(workspaceID))
+	h.Write([]byte{0})
+	h.Write([]byte(query))
+	if timespan != nil {
+		h.Write([]byte{0})
+		h.Write([]byte(timespan.Start.UTC().Format(time.RFC3339Nano)))
+		h.Write([]byte{0})
+		h.Write([]byte(timespan.End.UTC().Format(time.RFC3339Nano)))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns a cached result and its age if one exists and hasn't expired
+func (c *ResultCache) Get(workspaceID, query string, timespan *TimeSpan) (*QueryResult, time.Duration, bool) {
+	if !c.Enabled() {
+		return nil, 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := cacheKey(workspaceID, query, timespan)
+	entry, ok := c.entries[k]
+	if !ok && c.dir != "" {
+		entry, ok = c.readDisk(k)
+	}
+	if !ok {
+		return nil, 0, false
+	}
+
+	age := time.Since(entry.StoredAt)
+	if age > c.ttl {
+		c.remove(k)
+		return nil, 0, false
+	}
+
+	c.entries[k] = entry
+	return entry.Result, age, true
+}
+
+// Put stores a result
+func (c *ResultCache) Put(workspaceID, query string, timespan *TimeSpan, result *QueryResult) {
+	if !c.Enabled() || result == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := cacheKey(workspaceID, query, timespan)
+	entry := cacheEntry{Result: result, StoredAt: time.Now()}
+	c.entries[k] = entry
+	c.pruneExpired()
+
+	if c.dir != "" {
+		c.writeDisk(k, entry)
+	}
+}
+
+// Clear removes all cached results
+func (c *ResultCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k := range c.entries {
+		c.remove(k)
+	}
+	if c.dir != "" {
+		files, _ := filepath.Glob(filepath.Join(c.dir, "*.json"))
+		for _, f := range files {
+			os.Remove(f)
+		}
+	}
+}
+
+// pruneExpired drops expired entries from memory
+func (c *ResultCache) pruneExpired() {
+	for k, entry := range c.entries {
+		if time.Since(entry.StoredAt) > c.ttl {
+			c.remove(k)
+		}
+	}
+}
+
+// remove deletes an entry from memory and disk
+func (c *ResultCache) remove(k string) {
+	delete(c.entries, k)
+	if c.dir != "" {
+		os.Remove(filepath.Join(c.dir, k+".json"))
+	}
+}
+
+// readDisk loads an entry from the on-disk cache
+func (c *ResultCache) readDisk(k string) (cacheEntry, bool) {
+	data, err := os.ReadFile(filepath.Join(c.dir, k+".json"))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Result == nil {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// writeDisk saves an entry to the on-disk cache. Failures are ignored since
+// the cache is only an optimization.
+func (c *ResultCache) writeDisk(k string, entry cacheEntry) {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	os.WriteFile(filepath.Join(c.dir, k+".json"), data, 0600)
+}
@@ -0,0 +1,99 @@
+package azure
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResultCache_Get(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := &TimeSpan{Start: start, End: start.Add(24 * time.Hour)}
+	hour := &TimeSpan{Start: start, End: start.Add(time.Hour)}
+
+	tests := []struct {
+		name      string
+		workspace string
+		query     string
+		timespan  *TimeSpan
+		expected  bool
+	}{
+		{"same query", "ws-1", "T | take 10", day, true},
+		{"other workspace", "ws-2", "T | take 10", day, false},
+		{"other query", "ws-1", "T | take 20", day, false},
+		{"other timespan", "ws-1", "T | take 10", hour, false},
+		{"no timespan", "ws-1", "T | take 10", nil, false},
+	}
+
+	cache := NewResultCache(time.Minute)
+	result := &QueryResult{RowCount: 10}
+	cache.Put("ws-1", "T | take 10", day, result)
+
+	for _, tt := range tests {
+		got, _, ok := cache.Get(tt.workspace, tt.query, tt.timespan)
+		if ok != tt.expected {
+			t.Errorf("%s: expected hit %v, got %v", tt.name, tt.expected, ok)
+		}
+		if ok && got != result {
+			t.Errorf("%s: expected the stored result, got %+v", tt.name, got)
+		}
+	}
+}
+
+func TestResultCache_Expiry(t *testing.T) {
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		age      time.Duration
+		expected bool
+	}{
+		{"fresh", time.Minute, 10 * time.Second, true},
+		{"expired", time.Minute, 2 * time.Minute, false},
+		{"disabled", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		cache := NewResultCache(tt.ttl)
+		cache.Put("ws", "T", nil, &QueryResult{})
+		if entry, ok := cache.entries[cacheKey("ws", "T", nil)]; ok {
+			entry.StoredAt = entry.StoredAt.Add(-tt.age)
+			cache.entries[cacheKey("ws", "T", nil)] = entry
+		}
+
+		_, age, ok := cache.Get("ws", "T", nil)
+		if ok != tt.expected {
+			t.Errorf("%s: expected hit %v, got %v", tt.name, tt.expected, ok)
+		}
+		if ok && age < tt.age {
+			t.Errorf("%s: expected age of at least %v, got %v", tt.name, tt.age, age)
+		}
+		if !ok && len(cache.entries) != 0 {
+			t.Errorf("%s: expected no entries left, got %d", tt.name, len(cache.entries))
+		}
+	}
+}
+
+func TestResultCache_Disk(t *testing.T) {
+	dir := t.TempDir()
+
+	cache := NewResultCache(time.Minute)
+	cache.EnableDisk(dir)
+	cache.Put("ws", "T", nil, &QueryResult{RowCount: 3, Tables: []Table{{Name: "PrimaryResult"}}})
+
+	// A new cache, as in a later session, reads the entry back from disk
+	reloaded := NewResultCache(time.Minute)
+	reloaded.EnableDisk(dir)
+	got, _, ok := reloaded.Get("ws", "T", nil)
+	if !ok {
+		t.Fatal("Expected the result to be read from disk")
+	}
+	if got.RowCount != 3 || len(got.Tables) != 1 {
+		t.Errorf("Expected the stored result, got %+v", got)
+	}
+
+	reloaded.Clear()
+	fresh := NewResultCache(time.Minute)
+	fresh.EnableDisk(dir)
+	if _, _, ok := fresh.Get("ws", "T", nil); ok {
+		t.Error("Expected Clear to remove the on-disk entries")
+	}
+}
@@ -0,0 +1,28 @@
+package azure
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+//go:embed catalog.json
+var catalogJSON []byte
+
+// CatalogQuery is a read-only query from the bundled catalog of common
+// Log Analytics queries
+type CatalogQuery struct {
+	Category    string `json:"category"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Query       string `json:"query"`
+}
+
+// Catalog returns the bundled queries, grouped by category
+func Catalog() ([]CatalogQuery, error) {
+	var queries []CatalogQuery
+	if err := json.Unmarshal(catalogJSON, &queries); err != nil {
+		return nil, fmt.Errorf("invalid query catalog: %w", err)
+	}
+	return queries, nil
+}
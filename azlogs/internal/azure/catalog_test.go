@@ -0,0 +1,27 @@
+package azure
+
+import "testing"
+
+func TestCatalog(t *testing.T) {
+	queries, err := Catalog()
+	if err != nil {
+		t.Fatalf("Failed to load catalog: %v", err)
+	}
+	if len(queries) == 0 {
+		t.Fatal("Expected catalog queries")
+	}
+
+	// Categories must be contiguous for the catalog view to group them
+	seen := make(map[string]bool)
+	prev := ""
+	for _, q := range queries {
+		if q.Category == "" || q.Name == "" || q.Description == "" || q.Query == "" {
+			t.Errorf("Expected all fields set, got %+v", q)
+		}
+		if q.Category != prev && seen[q.Category] {
+			t.Errorf("Expected category %q to be listed together", q.Category)
+		}
+		seen[q.Category] = true
+		prev = q.Category
+	}
+}
@@ -0,0 +1,99 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/azquery"
+)
+
+// Cloud is an Azure cloud with its sign-in authority and service endpoints
+type Cloud struct {
+	Name          string
+	Configuration cloud.Configuration
+
+	// LogAnalyticsEndpoint is also the audience of Log Analytics tokens
+	LogAnalyticsEndpoint string
+
+	// CognitiveServicesEndpoint is the audience of Azure OpenAI tokens
+	CognitiveServicesEndpoint string
+
+	// PortalURL is the Azure portal that queries are opened in
+	PortalURL string
+
+	// DataExplorerURL is the Data Explorer web UI that cluster queries are
+	// opened in
+	DataExplorerURL string
+}
+
+// The clouds selectable with --cloud
+var (
+	CloudPublic = Cloud{
+		Name:                      "public",
+		Configuration:             cloud.AzurePublic,
+		LogAnalyticsEndpoint:      "https://api.loganalytics.io",
+		CognitiveServicesEndpoint: "https://cognitiveservices.azure.com",
+		PortalURL:                 "https://portal.azure.com",
+		DataExplorerURL:           "https://dataexplorer.azure.com",
+	}
+	CloudUSGov = Cloud{
+		Name:                      "usgov",
+		Configuration:             cloud.AzureGovernment,
+		LogAnalyticsEndpoint:      "https://api.loganalytics.us",
+		CognitiveServicesEndpoint: "https://cognitiveservices.azure.us",
+		PortalURL:                 "https://portal.azure.us",
+		DataExplorerURL:           "https://dataexplorer.azure.us",
+	}
+	CloudChina = Cloud{
+		Name:                      "china",
+		Configuration:             cloud.AzureChina,
+		LogAnalyticsEndpoint:      "https://api.loganalytics.azure.cn",
+		CognitiveServicesEndpoint: "https://cognitiveservices.azure.cn",
+		PortalURL:                 "https://portal.azure.cn",
+		DataExplorerURL:           "https://dataexplorer.azure.cn",
+	}
+)
+
+// ParseCloud returns the cloud with the given name: public, usgov or china.
+// An empty name selects the public cloud.
+func ParseCloud(name string) (Cloud, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "public", "azurecloud":
+		return CloudPublic, nil
+	case "usgov", "azureusgovernment":
+		return CloudUSGov, nil
+	case "china", "azurechinacloud":
+		return CloudChina, nil
+	default:
+		return Cloud{}, fmt.Errorf("unknown cloud %q (use public, usgov or china)", name)
+	}
+}
+
+// LogAnalyticsScope returns the token scope for querying Log Analytics
+func (c Cloud) LogAnalyticsScope() string {
+	return c.LogAnalyticsEndpoint + "/.default"
+}
+
+// OpenAIScope returns the token scope for Azure OpenAI
+func (c Cloud) OpenAIScope() string {
+	return c.CognitiveServicesEndpoint + "/.default"
+}
+
+// logsConfiguration returns the cloud's configuration with the Log Analytics
+// service pointed at LogAnalyticsEndpoint, so the logs client requests
+// tokens for the same audience the authenticator validates against
+func (c Cloud) logsConfiguration() cloud.Configuration {
+	config := cloud.Configuration{
+		ActiveDirectoryAuthorityHost: c.Configuration.ActiveDirectoryAuthorityHost,
+		Services:                     map[cloud.ServiceName]cloud.ServiceConfiguration{},
+	}
+	for name, service := range c.Configuration.Services {
+		config.Services[name] = service
+	}
+	config.Services[azquery.ServiceNameLogs] = cloud.ServiceConfiguration{
+		Audience: c.LogAnalyticsEndpoint,
+		Endpoint: c.LogAnalyticsEndpoint + "/v1",
+	}
+	return config
+}
@@ -0,0 +1,75 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/azquery"
+)
+
+func TestParseCloud(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"", "public", false},
+		{"public", "public", false},
+		{"USGov", "usgov", false},
+		{"AzureUSGovernment", "usgov", false},
+		{"china", "china", false},
+		{"germany", "", true},
+	}
+
+	for _, tt := range tests {
+		cloud, err := ParseCloud(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCloud(%q): expected error %v, got %v", tt.name, tt.wantErr, err)
+			continue
+		}
+		if cloud.Name != tt.want {
+			t.Errorf("ParseCloud(%q): expected %q, got %q", tt.name, tt.want, cloud.Name)
+		}
+	}
+}
+
+func TestCloud_Scopes(t *testing.T) {
+	tests := []struct {
+		cloud        Cloud
+		logAnalytics string
+		openAI       string
+	}{
+		{CloudPublic, "https://api.loganalytics.io/.default", "https://cognitiveservices.azure.com/.default"},
+		{CloudUSGov, "https://api.loganalytics.us/.default", "https://cognitiveservices.azure.us/.default"},
+		{CloudChina, "https://api.loganalytics.azure.cn/.default", "https://cognitiveservices.azure.cn/.default"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cloud.LogAnalyticsScope(); got != tt.logAnalytics {
+			t.Errorf("%s: expected Log Analytics scope %q, got %q", tt.cloud.Name, tt.logAnalytics, got)
+		}
+		if got := tt.cloud.OpenAIScope(); got != tt.openAI {
+			t.Errorf("%s: expected OpenAI scope %q, got %q", tt.cloud.Name, tt.openAI, got)
+		}
+	}
+}
+
+func TestCloud_LogsConfiguration(t *testing.T) {
+	for _, c := range []Cloud{CloudPublic, CloudUSGov, CloudChina} {
+		service, ok := c.logsConfiguration().Services[azquery.ServiceNameLogs]
+		if !ok {
+			t.Errorf("%s: expected a Log Analytics service configuration", c.Name)
+			continue
+		}
+		if service.Audience+"/.default" != c.LogAnalyticsScope() {
+			t.Errorf("%s: expected audience matching scope %q, got %q", c.Name, c.LogAnalyticsScope(), service.Audience)
+		}
+		if service.Endpoint != c.LogAnalyticsEndpoint+"/v1" {
+			t.Errorf("%s: expected endpoint %q, got %q", c.Name, c.LogAnalyticsEndpoint+"/v1", service.Endpoint)
+		}
+	}
+
+	// The shared SDK configuration must not be modified
+	if CloudUSGov.Configuration.Services[azquery.ServiceNameLogs].Endpoint != "https://api.loganalytics.us/v1" {
+		t.Error("Expected the SDK's cloud configuration to be left unchanged")
+	}
+}
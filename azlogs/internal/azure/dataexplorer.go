@@ -0,0 +1,317 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+)
+
+// DataExplorerClient handles queries to an Azure Data Explorer (Kusto)
+// database through the cluster's v1 REST query endpoint. Requests go through
+// an azcore pipeline with the Log Analytics client's transport, so both
+// backends share retries, token handling and download progress.
+//
+// This calls the REST API rather than kusto.Client from azure-kusto-go,
+// which isn't a dependency of the module yet. Every request goes through
+// execute, so switching to the SDK later only replaces that method.
+type DataExplorerClient struct {
+	clusterURL string
+	database   string
+	pipeline   runtime.Pipeline
+	maxRows    int // 0 for no limit
+}
+
+// NewDataExplorerClient creates a client for a database on a cluster. The
+// cluster is a URL or a name such as "mycluster.westeurope".
+func NewDataExplorerClient(cred azcore.TokenCredential, cluster, database string) (*DataExplorerClient, error) {
+	if database == "" {
+		return nil, errors.New("a database is required with a Data Explorer cluster")
+	}
+	clusterURL, err := ClusterURL(cluster)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("using Data Explorer", "cluster", clusterURL, "database", database)
+
+	tokenPolicy := runtime.NewBearerTokenPolicy(cred, []string{clusterURL + "/.default"}, nil)
+	pipeline := runtime.NewPipeline("azlogs", "", runtime.PipelineOptions{PerRetry: []policy.Policy{tokenPolicy}},
+		&azcore.ClientOptions{Transport: progressTransport{client: http.DefaultClient}})
+
+	return &DataExplorerClient{
+		clusterURL: clusterURL,
+		database:   database,
+		pipeline:   pipeline,
+	}, nil
+}
+
+// ClusterURL returns the URL of a Data Explorer cluster given as a URL or
+// as a cluster name with its region, e.g. "help" or "mycluster.westeurope"
+func ClusterURL(cluster string) (string, error) {
+	cluster = strings.TrimRight(strings.TrimSpace(cluster), "/")
+	if cluster == "" {
+		return "", errors.New("cluster is empty")
+	}
+	if strings.Contains(cluster, "://") {
+		if !strings.HasPrefix(cluster, "https://") {
+			return "", fmt.Errorf("cluster URL must use https: %s", cluster)
+		}
+		return cluster, nil
+	}
+	if strings.Contains(cluster, ".kusto.") {
+		return "https://" + cluster, nil
+	}
+	return "https://" + cluster + ".kusto.windows.net", nil
+}
+
+// SetMaxRows caps the number of rows kept per query result (0 for no limit)
+func (c *DataExplorerClient) SetMaxRows(maxRows int) {
+	c.maxRows = maxRows
+}
+
+// Query executes a KQL query against the database. Data Explorer has no
+// query-wide time range, so a timespan is an error rather than silently
+// ignored; the query's own time filters apply.
+func (c *DataExplorerClient) Query(ctx context.Context, query string, timespan *TimeSpan) (*QueryResult, error) {
+	if timespan != nil {
+		return nil, errors.New("Data Explorer queries have no time range: filter on a datetime column in the query")
+	}
+	return c.execute(ctx, "/v1/rest/query", query)
+}
+
+// QueryWithTimeout executes a query with a specific timeout
+func (c *DataExplorerClient) QueryWithTimeout(ctx context.Context, query string, timespan *TimeSpan, timeout time.Duration) (*QueryResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return c.Query(ctx, query, timespan)
+}
+
+// QueryWithParameters executes a query with declared query parameters
+func (c *DataExplorerClient) QueryWithParameters(ctx context.Context, query string, timespan *TimeSpan, params map[string]interface{}) (*QueryResult, error) {
+	return queryWithParameters(ctx, c, query, timespan, params)
+}
+
+// GetAvailableTables returns the tables in the database
+func (c *DataExplorerClient) GetAvailableTables(ctx context.Context) ([]string, error) {
+	result, err := c.execute(ctx, "/v1/rest/mgmt", ".show tables | project TableName | order by TableName asc")
+	if err != nil {
+		return nil, err
+	}
+	return firstColumnStrings(result), nil
+}
+
+// GetTableSchema returns the schema for a specific table
+func (c *DataExplorerClient) GetTableSchema(ctx context.Context, tableName string) ([]Column, error) {
+	result, err := c.Query(ctx, fmt.Sprintf("%s | getschema", tableName), nil)
+	if err != nil {
+		return nil, err
+	}
+	return schemaFromResult(result), nil
+}
+
+// execute posts a query or control command to a REST endpoint of the cluster
+func (c *DataExplorerClient) execute(ctx context.Context, path, csl string) (*QueryResult, error) {
+	start := time.Now()
+
+	jsonBody, err := json.Marshal(map[string]string{"db": c.database, "csl": csl})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := runtime.NewRequest(ctx, http.MethodPost, c.clusterURL+path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := req.SetBody(streaming.NopCloser(bytes.NewReader(jsonBody)), "application/json; charset=utf-8"); err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Raw().Header.Set("Accept", "application/json")
+	req.Raw().Header.Set("x-ms-app", "azlogs")
+
+	logger.Debug("sending query", "url", c.clusterURL+path, "database", c.database, "query", csl)
+	resp, err := c.pipeline.Do(req)
+	if err != nil {
+		logger.Debug("query failed", "duration", time.Since(start), "error", err)
+		return nil, classifyError(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		logger.Debug("query failed", "status", resp.StatusCode, "duration", time.Since(start))
+		return nil, dataExplorerError(resp.StatusCode, body)
+	}
+
+	result, err := parseDataExplorerResponse(body, c.maxRows)
+	if err != nil {
+		return nil, err
+	}
+	result.Duration = time.Since(start)
+	result.AsOf = time.Now()
+	logger.Debug("query finished", "status", result.QueryStatus, "rows", result.RowCount, "duration", result.Duration)
+	return result, nil
+}
+
+// dataExplorerError converts an error response into a QueryError
+func dataExplorerError(status int, body []byte) error {
+	var resp struct {
+		Error struct {
+			Code          string `json:"code"`
+			Message       string `json:"message"`
+			DetailMessage string `json:"@message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Error.Message == "" {
+		return classifyResponse("", status, fmt.Errorf("API error (status %d): %s", status, strings.TrimSpace(string(body))))
+	}
+
+	msg := resp.Error.Message
+	if resp.Error.DetailMessage != "" && resp.Error.DetailMessage != msg {
+		msg = resp.Error.DetailMessage
+	}
+	return classifyResponse(resp.Error.Code, status, fmt.Errorf("%s: %s", resp.Error.Code, msg))
+}
+
+// dataExplorerTable is a table in a v1 REST API response
+type dataExplorerTable struct {
+	TableName string `json:"TableName"`
+	Columns   []struct {
+		ColumnName string `json:"ColumnName"`
+		DataType   string `json:"DataType"`
+		ColumnType string `json:"ColumnType"`
+	} `json:"Columns"`
+	Rows [][]interface{} `json:"Rows"`
+}
+
+// parseDataExplorerResponse converts a v1 REST API response into a query
+// result. When the response ends with a table of contents, only the tables
+// it lists as query results are kept and the query status table is checked
+// for errors.
+func parseDataExplorerResponse(body []byte, maxRows int) (*QueryResult, error) {
+	var resp struct {
+		Tables []dataExplorerTable `json:"Tables"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	result := &QueryResult{QueryStatus: "Success"}
+	tables := resp.Tables
+	if n := len(tables); n > 1 {
+		toc := toQueryResult(tables[n-1])
+		kind := columnIndex(toc.Columns, "Kind")
+		ordinal := columnIndex(toc.Columns, "Ordinal")
+		if kind >= 0 && ordinal >= 0 {
+			var primary []dataExplorerTable
+			for _, row := range toc.Rows {
+				if len(row) <= kind || len(row) <= ordinal {
+					continue
+				}
+				i, ok := row[ordinal].(float64)
+				if !ok || int(i) < 0 || int(i) >= n-1 {
+					continue
+				}
+				switch row[kind] {
+				case "QueryResult":
+					primary = append(primary, tables[int(i)])
+				case "QueryStatus":
+					if msg := statusErrors(toQueryResult(tables[int(i)])); msg != "" {
+						result.QueryStatus = "Partial: QueryStatus"
+						result.PartialError = msg
+					}
+				case "QueryProperties":
+					result.Render = propertiesRender(toQueryResult(tables[int(i)]))
+				}
+			}
+			tables = primary
+		}
+	}
+
+	for _, t := range tables {
+		table := toQueryResult(t)
+		if maxRows > 0 && result.RowCount+len(table.Rows) > maxRows {
+			table.Rows = table.Rows[:maxRows-result.RowCount]
+			result.Truncated = true
+		}
+		result.RowCount += len(table.Rows)
+		result.Tables = append(result.Tables, table)
+	}
+	return result, nil
+}
+
+// toQueryResult converts a response table, typing columns with their KQL type
+func toQueryResult(t dataExplorerTable) Table {
+	table := Table{Name: t.TableName, Rows: t.Rows}
+	for _, col := range t.Columns {
+		colType := col.ColumnType
+		if colType == "" {
+			colType = strings.ToLower(col.DataType)
+		}
+		table.Columns = append(table.Columns, Column{Name: col.ColumnName, Type: colType})
+	}
+	return table
+}
+
+// statusErrors returns the error descriptions in a query status table
+func statusErrors(status Table) string {
+	severity := columnIndex(status.Columns, "SeverityName")
+	description := columnIndex(status.Columns, "StatusDescription")
+	if severity < 0 || description < 0 {
+		return ""
+	}
+
+	var errs []string
+	for _, row := range status.Rows {
+		if len(row) <= severity || len(row) <= description {
+			continue
+		}
+		if name, _ := row[severity].(string); name == "Error" || name == "Critical" {
+			if desc, ok := row[description].(string); ok {
+				errs = append(errs, desc)
+			}
+		}
+	}
+	return strings.Join(errs, "; ")
+}
+
+// propertiesRender returns the chart type in a query properties table,
+// which holds the render operator's properties as JSON under Visualization
+func propertiesRender(props Table) string {
+	key := columnIndex(props.Columns, "Key")
+	value := columnIndex(props.Columns, "Value")
+	if key < 0 || value < 0 {
+		return ""
+	}
+	for _, row := range props.Rows {
+		if len(row) <= key || len(row) <= value || row[key] != "Visualization" {
+			continue
+		}
+		if v, ok := row[value].(string); ok {
+			return renderHint([]byte(v))
+		}
+	}
+	return ""
+}
+
+// columnIndex returns the index of the named column, or -1
+func columnIndex(columns []Column, name string) int {
+	for i, col := range columns {
+		if col.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,103 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClusterURL(t *testing.T) {
+	tests := []struct {
+		cluster  string
+		expected string
+		wantErr  bool
+	}{
+		{"help", "https://help.kusto.windows.net", false},
+		{"mycluster.westeurope", "https://mycluster.westeurope.kusto.windows.net", false},
+		{"mycluster.westeurope.kusto.windows.net", "https://mycluster.westeurope.kusto.windows.net", false},
+		{"https://mycluster.westeurope.kusto.windows.net/", "https://mycluster.westeurope.kusto.windows.net", false},
+		{"http://mycluster.kusto.windows.net", "", true},
+		{"  ", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cluster, func(t *testing.T) {
+			got, err := ClusterURL(tt.cluster)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseDataExplorerResponse(t *testing.T) {
+	body := `{"Tables": [
+		{"TableName": "Table_0",
+		 "Columns": [{"ColumnName": "State", "DataType": "String", "ColumnType": "string"}, {"ColumnName": "Count", "DataType": "Int64", "ColumnType": "long"}],
+		 "Rows": [["TEXAS", 10], ["KANSAS", 7], ["IOWA", 3]]},
+		{"TableName": "Table_1",
+		 "Columns": [{"ColumnName": "SeverityName", "DataType": "String"}, {"ColumnName": "StatusDescription", "DataType": "String"}],
+		 "Rows": [["Info", "Query completed successfully"], ["Error", "Partial query failure: Low memory condition"]]},
+		{"TableName": "Table_2",
+		 "Columns": [{"ColumnName": "TableId", "DataType": "Int32"}, {"ColumnName": "Key", "DataType": "String"}, {"ColumnName": "Value", "DataType": "Object"}],
+		 "Rows": [[0, "Visualization", "{\"Visualization\":\"BarChart\",\"Title\":null}"]]},
+		{"TableName": "Table_3",
+		 "Columns": [{"ColumnName": "Ordinal", "DataType": "Int64"}, {"ColumnName": "Kind", "DataType": "String"}, {"ColumnName": "Name", "DataType": "String"}],
+		 "Rows": [[0, "QueryResult", "PrimaryResult"], [1, "QueryStatus", "QueryStatus"], [2, "QueryProperties", "@ExtendedProperties"]]}
+	]}`
+
+	result, err := parseDataExplorerResponse([]byte(body), 2)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if len(result.Tables) != 1 {
+		t.Fatalf("Expected only the query result table, got %d tables", len(result.Tables))
+	}
+	table := result.Tables[0]
+	if len(table.Columns) != 2 || table.Columns[1].Name != "Count" || table.Columns[1].Type != "long" {
+		t.Errorf("Expected columns typed with their KQL type, got %+v", table.Columns)
+	}
+	if result.RowCount != 2 || len(table.Rows) != 2 || !result.Truncated {
+		t.Errorf("Expected 2 rows kept of 3 and truncated, got %d rows (truncated %v)", result.RowCount, result.Truncated)
+	}
+	if !result.IsPartial() || result.PartialError != "Partial query failure: Low memory condition" {
+		t.Errorf("Expected a partial result from the status table, got %q: %q", result.QueryStatus, result.PartialError)
+	}
+	if result.Render != "barchart" {
+		t.Errorf("Expected the render hint from the query properties, got %q", result.Render)
+	}
+}
+
+func TestDataExplorerError(t *testing.T) {
+	body := `{"error": {"code": "General_BadRequest", "message": "Request is invalid and cannot be executed.", "@message": "Syntax error: Query could not be parsed"}}`
+	err := dataExplorerError(400, []byte(body))
+
+	if !errors.Is(err, ErrQuerySyntax) {
+		t.Errorf("Expected a syntax error, got %v", err)
+	}
+	var qe *QueryError
+	if !errors.As(err, &qe) || qe.Code != "General_BadRequest" {
+		t.Fatalf("Expected a QueryError with the error code, got %v", err)
+	}
+	if want := "query failed: General_BadRequest: Syntax error: Query could not be parsed"; err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err.Error())
+	}
+}
+
+func TestDataExplorerClient_QueryTimespan(t *testing.T) {
+	client, err := NewDataExplorerClient(nil, "help", "Samples")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	now := time.Now()
+	_, err = client.Query(context.Background(), "StormEvents | take 1", &TimeSpan{Start: now.Add(-time.Hour), End: now})
+	if err == nil {
+		t.Error("Expected an error for a timespan, which Data Explorer can't apply")
+	}
+}
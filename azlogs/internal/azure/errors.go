@@ -0,0 +1,104 @@
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// Query failure classes. Use errors.Is to check a query error against them.
+var (
+	ErrQuerySyntax       = errors.New("query syntax error")
+	ErrThrottled         = errors.New("request throttled")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+)
+
+// QueryError is a query failure with its classification
+type QueryError struct {
+	Kind       error  // One of the Err* classes, nil if unclassified
+	Code       string // Azure error code, if available
+	StatusCode int    // HTTP status code, if available
+	Err        error  // Underlying error
+}
+
+// Error implements the error interface
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("query failed: %v", e.Err)
+}
+
+// Unwrap returns both the classification and the underlying error
+func (e *QueryError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// errorCodeKinds maps Azure error codes to failure classes
+var errorCodeKinds = map[string]error{
+	"BadArgumentError": ErrQuerySyntax,
+	"SyntaxError":      ErrQuerySyntax,
+	"SemanticError":    ErrQuerySyntax,
+
+	"ThrottledError":  ErrThrottled,
+	"TooManyRequests": ErrThrottled,
+
+	"AuthorizationFailed":                ErrUnauthorized,
+	"AuthorizationRequiredError":         ErrUnauthorized,
+	"InsufficientAccessError":            ErrUnauthorized,
+	"InvalidAuthenticationToken":         ErrUnauthorized,
+	"InvalidAuthenticationTokenAudience": ErrUnauthorized,
+	"InvalidTokenError":                  ErrUnauthorized,
+
+	"WorkspaceNotFoundError": ErrWorkspaceNotFound,
+	"PathNotFoundError":      ErrWorkspaceNotFound,
+	"ResourceNotFound":       ErrWorkspaceNotFound,
+}
+
+// classifyStatus maps an HTTP status code to a failure class
+func classifyStatus(status int) error {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrQuerySyntax
+	case http.StatusTooManyRequests:
+		return ErrThrottled
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return ErrWorkspaceNotFound
+	}
+	return nil
+}
+
+// classifyError wraps a query error in a QueryError, classifying it from
+// the Azure error response or credential failure
+func classifyError(err error) error {
+	qe := &QueryError{Err: err}
+
+	var respErr *azcore.ResponseError
+	var authErr *azidentity.AuthenticationFailedError
+	switch {
+	case errors.As(err, &respErr):
+		return classifyResponse(respErr.ErrorCode, respErr.StatusCode, err)
+	case errors.As(err, &authErr):
+		qe.Kind = ErrUnauthorized
+	}
+
+	return qe
+}
+
+// classifyResponse wraps the error from an Azure error response in a
+// QueryError, classified by its error code or else its HTTP status
+func classifyResponse(code string, status int, err error) *QueryError {
+	qe := &QueryError{Code: code, StatusCode: status, Err: err}
+	if kind, ok := errorCodeKinds[code]; ok {
+		qe.Kind = kind
+	} else {
+		qe.Kind = classifyStatus(status)
+	}
+	return qe
+}
@@ -0,0 +1,68 @@
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		status int
+		want   error
+	}{
+		{"syntax error code", "BadArgumentError", 400, ErrQuerySyntax},
+		{"semantic error code", "SemanticError", 400, ErrQuerySyntax},
+		{"throttled code", "ThrottledError", 429, ErrThrottled},
+		{"throttled status only", "", 429, ErrThrottled},
+		{"invalid token audience", "InvalidAuthenticationTokenAudience", 401, ErrUnauthorized},
+		{"insufficient access", "InsufficientAccessError", 403, ErrUnauthorized},
+		{"forbidden status only", "", 403, ErrUnauthorized},
+		{"workspace not found", "WorkspaceNotFoundError", 404, ErrWorkspaceNotFound},
+		{"path not found", "PathNotFoundError", 404, ErrWorkspaceNotFound},
+		{"code takes precedence over status", "ThrottledError", 400, ErrThrottled},
+		{"unknown server error", "InternalServerError", 500, nil},
+	}
+
+	kinds := []error{ErrQuerySyntax, ErrThrottled, ErrUnauthorized, ErrWorkspaceNotFound}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			respErr := &azcore.ResponseError{ErrorCode: tt.code, StatusCode: tt.status}
+			err := classifyError(fmt.Errorf("request: %w", respErr))
+
+			for _, kind := range kinds {
+				if got := errors.Is(err, kind); got != (kind == tt.want) {
+					t.Errorf("errors.Is(err, %v) = %v, expected %v", kind, got, kind == tt.want)
+				}
+			}
+
+			var qe *QueryError
+			if !errors.As(err, &qe) {
+				t.Fatalf("Expected *QueryError, got %T", err)
+			}
+			if qe.Code != tt.code || qe.StatusCode != tt.status {
+				t.Errorf("Expected code %q status %d, got %q %d", tt.code, tt.status, qe.Code, qe.StatusCode)
+			}
+			if !errors.Is(err, respErr) {
+				t.Error("Expected the underlying response error to be preserved")
+			}
+		})
+	}
+}
+
+func TestClassifyError_Unknown(t *testing.T) {
+	err := classifyError(errors.New("connection reset"))
+	for _, kind := range []error{ErrQuerySyntax, ErrThrottled, ErrUnauthorized, ErrWorkspaceNotFound} {
+		if errors.Is(err, kind) {
+			t.Errorf("Expected unclassified error, got %v", kind)
+		}
+	}
+	if err.Error() != "query failed: connection reset" {
+		t.Errorf("Expected 'query failed: connection reset', got '%s'", err.Error())
+	}
+}
@@ -0,0 +1,90 @@
+package azure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// CorruptFileError reports a state file that couldn't be parsed. The file
+// has been moved aside to Backup so the app can start fresh without losing it
+type CorruptFileError struct {
+	Path   string
+	Backup string
+	Err    error
+}
+
+func (e *CorruptFileError) Error() string {
+	return fmt.Sprintf("%s is corrupt (%v); moved it to %s and started fresh", filepath.Base(e.Path), e.Err, e.Backup)
+}
+
+func (e *CorruptFileError) Unwrap() error {
+	return e.Err
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so an interrupted write leaves the previous file intact
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // Fails harmlessly once renamed
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readJSONFile decodes a JSON state file into v, a pointer to a struct. A
+// missing file leaves v unchanged, and so does a file that fails to decode.
+// A file that isn't valid JSON is renamed to a timestamped .corrupt backup
+// and reported with a *CorruptFileError, so it is neither lost nor
+// overwritten by the next save. Valid JSON that doesn't fit v, e.g. from a
+// newer version, is reported as is and left in place.
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	// Decode into a copy so a failure part way through doesn't leave v half
+	// loaded. The copy keeps v's defaults and unexported fields.
+	target := reflect.ValueOf(v).Elem()
+	decoded := reflect.New(target.Type())
+	decoded.Elem().Set(target)
+	if err := json.Unmarshal(data, decoded.Interface()); err != nil {
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+		}
+		backup := path + ".corrupt-" + time.Now().Format("20060102-150405")
+		if renameErr := os.Rename(path, backup); renameErr != nil {
+			return fmt.Errorf("%s is corrupt (%v) and couldn't be moved aside: %w", filepath.Base(path), err, renameErr)
+		}
+		return &CorruptFileError{Path: path, Backup: backup, Err: err}
+	}
+	target.Set(decoded.Elem())
+	return nil
+}
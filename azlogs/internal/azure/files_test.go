@@ -0,0 +1,90 @@
+package azure
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	if err := os.WriteFile(path, []byte(`{"old":true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(path, []byte(`{"new":true}`), 0644); err != nil {
+		t.Fatalf("Expected the write to succeed, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"new":true}` {
+		t.Errorf("Expected the file replaced, got %s", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected no temporary files left behind, got %d files", len(entries))
+	}
+
+	// A write that can't complete leaves the old file alone
+	if err := writeFileAtomic(filepath.Join(dir, "missing", "state.json"), nil, 0644); err == nil {
+		t.Error("Expected an error writing into a missing directory")
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"new":true}` {
+		t.Errorf("Expected the file untouched, got %s", data)
+	}
+}
+
+func TestHistory_LoadCorrupt(t *testing.T) {
+	SetConfigDir(t.TempDir())
+	defer SetConfigDir("")
+
+	h := NewHistory(10)
+	if err := os.WriteFile(h.filePath, []byte(`{"entries": [{"query": "Sign`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := h.Load()
+	var corrupt *CorruptFileError
+	if !errors.As(err, &corrupt) {
+		t.Fatalf("Expected a CorruptFileError, got %v", err)
+	}
+	if len(h.Entries) != 0 {
+		t.Errorf("Expected an empty history, got %d entries", len(h.Entries))
+	}
+	if data, _ := os.ReadFile(corrupt.Backup); string(data) != `{"entries": [{"query": "Sign` {
+		t.Errorf("Expected the corrupt file kept in %s, got %q", corrupt.Backup, data)
+	}
+
+	// Starting fresh doesn't overwrite the backup
+	h.Add(HistoryEntry{Query: "SigninLogs"})
+	if err := h.Save(); err != nil {
+		t.Fatalf("Expected the save to succeed, got %v", err)
+	}
+	fresh := NewHistory(10)
+	if err := fresh.Load(); err != nil || len(fresh.Entries) != 1 {
+		t.Errorf("Expected 1 saved entry, got %d (%v)", len(fresh.Entries), err)
+	}
+}
+
+func TestConfig_LoadMismatchedFile(t *testing.T) {
+	dir := t.TempDir()
+	SetConfigDir(dir)
+	defer SetConfigDir("")
+
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"time_range": "6h", "max_history_size": "many"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := NewConfig()
+	err := config.Load()
+	var corrupt *CorruptFileError
+	if err == nil || errors.As(err, &corrupt) {
+		t.Fatalf("Expected a plain decode error, got %v", err)
+	}
+	if config.TimeRange != NewConfig().TimeRange {
+		t.Errorf("Expected the config left unchanged, got time range %q", config.TimeRange)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected valid JSON left in place: %v", err)
+	}
+}
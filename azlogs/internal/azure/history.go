@@ -2,8 +2,12 @@ package azure
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -23,6 +27,7 @@ type History struct {
 	Entries  []HistoryEntry `json:"entries"`
 	MaxSize  int            `json:"max_size"`
 	filePath string
+	cleared  bool // Cleared since the last save, so nothing on disk is kept
 }
 
 // NewHistory creates a new history manager
@@ -37,28 +42,17 @@ func NewHistory(maxSize int) *History {
 
 // setDefaultPath sets the default history file path
 func (h *History) setDefaultPath() {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = "."
-	}
-	configDir := filepath.Join(homeDir, ".config", "azlogs")
-	h.filePath = filepath.Join(configDir, "history.json")
+	h.filePath = filepath.Join(ConfigDir(), "history.json")
 }
 
 // Load reads history from disk
 func (h *History) Load() error {
-	data, err := os.ReadFile(h.filePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // No history file yet
-		}
-		return err
-	}
-
-	return json.Unmarshal(data, h)
+	return readJSONFile(h.filePath, h)
 }
 
-// Save writes history to disk
+// Save writes history to disk. Entries another instance saved since this
+// history was loaded are merged in first, so concurrent sessions don't
+// overwrite each other's history.
 func (h *History) Save() error {
 	// Ensure directory exists
 	dir := filepath.Dir(h.filePath)
@@ -66,12 +60,48 @@ func (h *History) Save() error {
 		return err
 	}
 
+	if !h.cleared {
+		onDisk := &History{}
+		if err := readJSONFile(h.filePath, onDisk); err == nil {
+			h.merge(onDisk.Entries)
+		}
+	}
+
 	data, err := json.MarshalIndent(h, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(h.filePath, data, 0644)
+	if err := writeFileAtomic(h.filePath, data, 0644); err != nil {
+		return err
+	}
+	h.cleared = false
+	return nil
+}
+
+// merge adds the entries not already in the history, identified by query
+// and execution time, keeping the newest first within MaxSize
+func (h *History) merge(entries []HistoryEntry) {
+	key := func(e HistoryEntry) string {
+		return e.Query + "\x00" + e.ExecutedAt.UTC().Format(time.RFC3339Nano)
+	}
+	seen := make(map[string]bool, len(h.Entries))
+	for _, e := range h.Entries {
+		seen[key(e)] = true
+	}
+	for _, e := range entries {
+		if !seen[key(e)] {
+			seen[key(e)] = true
+			h.Entries = append(h.Entries, e)
+		}
+	}
+
+	sort.SliceStable(h.Entries, func(i, j int) bool {
+		return h.Entries[i].ExecutedAt.After(h.Entries[j].ExecutedAt)
+	})
+	if h.MaxSize > 0 && len(h.Entries) > h.MaxSize {
+		h.Entries = h.Entries[:h.MaxSize]
+	}
 }
 
 // Add adds a new entry to history
@@ -107,6 +137,17 @@ func (h *History) Search(query string) []HistoryEntry {
 // Clear clears all history
 func (h *History) Clear() {
 	h.Entries = []HistoryEntry{}
+	h.cleared = true
+}
+
+// Export writes the history as JSON to w
+func (h *History) Export(w io.Writer) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
 }
 
 // containsIgnoreCase checks if s contains substr (case insensitive)
@@ -141,56 +182,175 @@ func contains(s, substr string) bool {
 
 // Config holds application configuration
 type Config struct {
-	DefaultWorkspace string `json:"default_workspace"`
-	DefaultAuthMethod AuthMethod `json:"default_auth_method"`
-	QueryTimeout     int    `json:"query_timeout_seconds"`
-	MaxHistorySize   int    `json:"max_history_size"`
-	SavedWorkspaces  []SavedWorkspace `json:"saved_workspaces"`
+	DefaultWorkspace  string              `json:"default_workspace"`
+	DefaultAuthMethod AuthMethod          `json:"default_auth_method"`
+	QueryTimeout      int                 `json:"query_timeout_seconds"`
+	MaxHistorySize    int                 `json:"max_history_size"`
+	SavedWorkspaces   []SavedWorkspace    `json:"saved_workspaces"`
+	MaxColumnWidth    int                 `json:"max_column_width"`
+	KeyBindings       map[string][]string `json:"key_bindings,omitempty"`
+	Theme             string              `json:"theme,omitempty"`
+	CacheTTL          int                 `json:"cache_ttl_seconds"`
+	CacheToDisk       bool                `json:"cache_to_disk"`
+	MaxResultRows     int                 `json:"max_result_rows"`
+	TimeRange         string              `json:"time_range,omitempty"`
+	SuggestDebounceMs int                 `json:"suggest_debounce_ms"`
+	ManualAISuggest   bool                `json:"manual_ai_suggest"`
+	Timezone          string              `json:"timezone,omitempty"`
+	DatetimeFormat    string              `json:"datetime_format,omitempty"`
+	DecimalPrecision  int                 `json:"decimal_precision"`
+	ThousandsSep      bool                `json:"thousands_separator"`
+	DisableScanGuard  bool                `json:"disable_scan_guard"`
+	SlowQueryMs       int                 `json:"slow_query_ms"`
+	SuggestTimeoutMs  int                 `json:"suggest_timeout_ms"`
+	AITimeoutSeconds  int                 `json:"ai_timeout_seconds"`
+	AIPrompt          string              `json:"ai_prompt,omitempty"`
+	OpenAIEndpoint    string              `json:"openai_endpoint,omitempty"`
+	OpenAIDeployment  string              `json:"openai_deployment,omitempty"`
+	StartupQuery      string              `json:"startup_query,omitempty"`
+	AutosaveSeconds   int                 `json:"autosave_seconds"`
+	PopupMaxWidth     int                 `json:"popup_max_width"`
+	ColumnWidths      TableWidths         `json:"column_widths,omitempty"`
+
+	// Session-only overrides from command line flags, never saved
+	NoCache        bool   `json:"-"` // --no-cache
+	NoTableScan    bool   `json:"-"` // --no-table-scan
+	MaxRows        *int   `json:"-"` // --max-rows, overrides MaxResultRows
+	Cloud          string `json:"-"` // --cloud: public, usgov or china
+	NoStartupQuery bool   `json:"-"` // --no-startup-query
+
+	// Azure Data Explorer database queried instead of a workspace when
+	// Cluster is set (--cluster, --database)
+	Cluster  string `json:"-"`
+	Database string `json:"-"`
 }
 
+// TableWidths holds the result column widths set for each table, by table
+// then column name
+type TableWidths map[string]map[string]int
+
 // SavedWorkspace represents a saved workspace
 type SavedWorkspace struct {
 	Name        string `json:"name"`
 	WorkspaceID string `json:"workspace_id"`
 	Description string `json:"description,omitempty"`
+
+	// ResourceID is the workspace's Azure resource ID, needed to open
+	// queries in the Azure portal
+	ResourceID string `json:"resource_id,omitempty"`
 }
 
+// DefaultAutosaveInterval is how often state is saved when the config
+// doesn't say
+const DefaultAutosaveInterval = 30 * time.Second
+
 // NewConfig creates a new config with defaults
 func NewConfig() *Config {
 	return &Config{
 		DefaultAuthMethod: AuthDefault,
-		QueryTimeout:     300,
-		MaxHistorySize:   1000,
-		SavedWorkspaces:  []SavedWorkspace{},
+		QueryTimeout:      300,
+		MaxHistorySize:    1000,
+		SavedWorkspaces:   []SavedWorkspace{},
+		MaxColumnWidth:    40,
+		CacheTTL:          60,
+		MaxResultRows:     100000,
+		SuggestDebounceMs: 500,
+		SuggestTimeoutMs:  5000,
+		AITimeoutSeconds:  60,
+		DecimalPrecision:  -1,
+		AutosaveSeconds:   30,
+		PopupMaxWidth:     60,
 	}
 }
 
-// Load reads config from disk
-func (c *Config) Load() error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = "."
+// SuggestDebounce returns how long typing must pause before an AI suggestion
+// is requested
+func (c *Config) SuggestDebounce() time.Duration {
+	if c.SuggestDebounceMs <= 0 {
+		return 500 * time.Millisecond
+	}
+	return time.Duration(c.SuggestDebounceMs) * time.Millisecond
+}
+
+// SuggestTimeout returns how long an AI suggestion request may take before
+// it is abandoned
+func (c *Config) SuggestTimeout() time.Duration {
+	if c.SuggestTimeoutMs <= 0 {
+		return DefaultSuggestTimeout
 	}
-	configPath := filepath.Join(homeDir, ".config", "azlogs", "config.json")
+	return time.Duration(c.SuggestTimeoutMs) * time.Millisecond
+}
+
+// AITimeout returns how long AI query explanations and fixes may take
+func (c *Config) AITimeout() time.Duration {
+	if c.AITimeoutSeconds <= 0 {
+		return DefaultAssistTimeout
+	}
+	return time.Duration(c.AITimeoutSeconds) * time.Second
+}
 
-	data, err := os.ReadFile(configPath)
+// AIInstructions returns the ai_prompt text added to the AI's built-in
+// guidance, cut to MaxAIPromptLength characters. The error reports a cut.
+func (c *Config) AIInstructions() (string, error) {
+	prompt := []rune(strings.TrimSpace(c.AIPrompt))
+	if len(prompt) > MaxAIPromptLength {
+		return string(prompt[:MaxAIPromptLength]), fmt.Errorf("ai_prompt in config is %d characters, only the first %d are used", len(prompt), MaxAIPromptLength)
+	}
+	return string(prompt), nil
+}
+
+// AIEndpoint returns the Azure OpenAI endpoint for AI features: the
+// openai_endpoint setting, or the built-in resource in the public cloud.
+// Other clouds have no built-in resource, so AI features need the setting.
+func (c *Config) AIEndpoint() (string, error) {
+	if endpoint := strings.TrimSpace(c.OpenAIEndpoint); endpoint != "" {
+		return endpoint, nil
+	}
+	if cloud := c.AzureCloud(); cloud.Name != CloudPublic.Name {
+		return "", fmt.Errorf("AI features need an Azure OpenAI resource in the %s cloud: set openai_endpoint in config.json", cloud.Name)
+	}
+	return DefaultOpenAIEndpoint, nil
+}
+
+// AutosaveInterval returns how often history, config and templates are
+// saved while the app runs, 0 when auto-save is disabled
+func (c *Config) AutosaveInterval() time.Duration {
+	switch {
+	case c.AutosaveSeconds < 0:
+		return 0
+	case c.AutosaveSeconds == 0:
+		return DefaultAutosaveInterval
+	}
+	return time.Duration(c.AutosaveSeconds) * time.Second
+}
+
+// ResultRowLimit returns the maximum number of rows kept per query result,
+// 0 for no limit
+func (c *Config) ResultRowLimit() int {
+	if c.MaxRows != nil {
+		return *c.MaxRows
+	}
+	return c.MaxResultRows
+}
+
+// AzureCloud returns the cloud selected with --cloud, the public cloud by
+// default
+func (c *Config) AzureCloud() Cloud {
+	cloud, err := ParseCloud(c.Cloud)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
+		return CloudPublic
 	}
+	return cloud
+}
 
-	return json.Unmarshal(data, c)
+// Load reads config from disk
+func (c *Config) Load() error {
+	return readJSONFile(filepath.Join(ConfigDir(), "config.json"), c)
 }
 
 // Save writes config to disk
 func (c *Config) Save() error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = "."
-	}
-	configDir := filepath.Join(homeDir, ".config", "azlogs")
+	configDir := ConfigDir()
 	configPath := filepath.Join(configDir, "config.json")
 
 	if err := os.MkdirAll(configDir, 0755); err != nil {
@@ -202,7 +362,7 @@ func (c *Config) Save() error {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0644)
+	return writeFileAtomic(configPath, data, 0644)
 }
 
 // AddWorkspace adds a workspace to saved workspaces
@@ -217,6 +377,17 @@ func (c *Config) AddWorkspace(ws SavedWorkspace) {
 	c.SavedWorkspaces = append(c.SavedWorkspaces, ws)
 }
 
+// WorkspaceResourceID returns the resource ID saved for a workspace, or ""
+// if it isn't saved or has none
+func (c *Config) WorkspaceResourceID(workspaceID string) string {
+	for _, ws := range c.SavedWorkspaces {
+		if strings.EqualFold(ws.WorkspaceID, workspaceID) {
+			return ws.ResourceID
+		}
+	}
+	return ""
+}
+
 // RemoveWorkspace removes a workspace from saved workspaces
 func (c *Config) RemoveWorkspace(workspaceID string) {
 	for i, ws := range c.SavedWorkspaces {
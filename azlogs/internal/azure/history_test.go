@@ -0,0 +1,115 @@
+package azure
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistory_SaveMerges(t *testing.T) {
+	SetConfigDir(t.TempDir())
+	defer SetConfigDir("")
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	first, second := NewHistory(3), NewHistory(3)
+	first.Load()
+	second.Load()
+
+	first.Add(HistoryEntry{Query: "A", ExecutedAt: start})
+	second.Add(HistoryEntry{Query: "B", ExecutedAt: start.Add(time.Minute)})
+	first.Add(HistoryEntry{Query: "C", ExecutedAt: start.Add(2 * time.Minute)})
+	second.Add(HistoryEntry{Query: "D", ExecutedAt: start.Add(3 * time.Minute)})
+	if err := first.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Save(); err != nil {
+		t.Fatal(err)
+	}
+	// Saving again finds nothing new
+	if err := first.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHistory(3)
+	if err := h.Load(); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range h.Entries {
+		got = append(got, e.Query)
+	}
+	if len(got) != 3 || got[0] != "D" || got[1] != "C" || got[2] != "B" {
+		t.Errorf("Expected the newest 3 of both sessions [D C B], got %v", got)
+	}
+}
+
+func TestHistory_SaveAfterClear(t *testing.T) {
+	SetConfigDir(t.TempDir())
+	defer SetConfigDir("")
+
+	h := NewHistory(10)
+	h.Add(HistoryEntry{Query: "A", ExecutedAt: time.Now()})
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	h.Clear()
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+	fresh := NewHistory(10)
+	if err := fresh.Load(); err != nil || len(fresh.Entries) != 0 {
+		t.Errorf("Expected clearing to empty the saved history, got %d entries (%v)", len(fresh.Entries), err)
+	}
+}
+
+func TestConfig_AIInstructions(t *testing.T) {
+	tests := []struct {
+		name     string
+		prompt   string
+		expected int
+		cut      bool
+	}{
+		{"unset", "", 0, false},
+		{"trimmed", "  Prefer has over contains.\n", len("Prefer has over contains."), false},
+		{"too long", strings.Repeat("é", MaxAIPromptLength+1), MaxAIPromptLength, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NewConfig()
+			config.AIPrompt = tt.prompt
+			got, err := config.AIInstructions()
+			if n := len([]rune(got)); n != tt.expected || (err != nil) != tt.cut {
+				t.Errorf("Expected %d characters (cut %v), got %d (%v)", tt.expected, tt.cut, n, err)
+			}
+		})
+	}
+}
+
+func TestConfig_AIEndpoint(t *testing.T) {
+	tests := []struct {
+		cloud    string
+		endpoint string
+		expected string
+		wantErr  bool
+	}{
+		{"", "", DefaultOpenAIEndpoint, false},
+		{"public", "https://mine.openai.azure.com", "https://mine.openai.azure.com", false},
+		{"usgov", "", "", true},
+		{"usgov", " https://mine.openai.azure.us ", "https://mine.openai.azure.us", false},
+		{"china", "", "", true},
+	}
+
+	for _, tt := range tests {
+		config := &Config{Cloud: tt.cloud, OpenAIEndpoint: tt.endpoint}
+		got, err := config.AIEndpoint()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Cloud %q, endpoint %q: expected error %v, got %v", tt.cloud, tt.endpoint, tt.wantErr, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("Cloud %q, endpoint %q: expected %q, got %q", tt.cloud, tt.endpoint, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package azure
+
+import "context"
+
+// mbPerGB converts the Usage table's Quantity, in MB, to GB. Ingestion is
+// billed in decimal GB, the unit the catalog's billing queries use too
+const mbPerGB = 1000.0
+
+// ingestionQuery averages each table's billable ingestion over the last 30
+// days. The Usage table reports Quantity in MB
+const ingestionQuery = `Usage
+| where TimeGenerated > ago(30d) and IsBillable == true
+| summarize MBPerDay = sum(Quantity) / 30 by DataType`
+
+// IngestionRates returns the average GB per day each table ingests, from the
+// workspace's Usage table. Data Explorer databases have no Usage table
+func IngestionRates(ctx context.Context, c QueryClient) (map[string]float64, error) {
+	result, err := c.Query(ctx, ingestionQuery, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ingestionRates(result), nil
+}
+
+// ingestionRates reads the table name and MB per day columns of a Usage
+// summary, converted to GB per day
+func ingestionRates(result *QueryResult) map[string]float64 {
+	rates := make(map[string]float64)
+	if len(result.Tables) == 0 {
+		return rates
+	}
+	for _, row := range result.Tables[0].Rows {
+		if len(row) < 2 {
+			continue
+		}
+		table, ok := row[0].(string)
+		mb, isNumber := row[1].(float64)
+		if ok && isNumber && table != "" {
+			rates[table] = mb / mbPerGB
+		}
+	}
+	return rates
+}
@@ -0,0 +1,23 @@
+package azure
+
+import "testing"
+
+func TestIngestionRates(t *testing.T) {
+	result := &QueryResult{Tables: []Table{{
+		Columns: []Column{{Name: "DataType", Type: "string"}, {Name: "MBPerDay", Type: "real"}},
+		Rows: [][]interface{}{
+			{"SigninLogs", 500.0},
+			{"Perf", 12250.0},
+			{nil, 1000.0},
+			{"AuditLogs"},
+		},
+	}}}
+
+	rates := ingestionRates(result)
+	if len(rates) != 2 || rates["SigninLogs"] != 0.5 || rates["Perf"] != 12.25 {
+		t.Errorf("Expected rates for SigninLogs and Perf, got %v", rates)
+	}
+	if rates := ingestionRates(&QueryResult{}); len(rates) != 0 {
+		t.Errorf("Expected no rates without tables, got %v", rates)
+	}
+}
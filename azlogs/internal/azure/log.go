@@ -0,0 +1,40 @@
+package azure
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+
+	azlog "github.com/Azure/azure-sdk-for-go/sdk/azcore/log"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// logger receives diagnostic output, discarded unless set with SetLogger
+var logger = slog.New(slog.DiscardHandler)
+
+// SetLogger sends diagnostics such as the auth method, endpoints, queries
+// and timings to l, along with the Azure SDK's retry and authentication
+// events. A nil logger turns diagnostics off again.
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		logger = slog.New(slog.DiscardHandler)
+		azlog.SetListener(nil)
+		return
+	}
+
+	logger = l
+	azlog.SetEvents(azlog.EventRetryPolicy, azidentity.EventAuthentication)
+	azlog.SetListener(func(event azlog.Event, msg string) {
+		logger.Debug(strings.TrimSpace(msg), "source", "azure-sdk", "event", string(event))
+	})
+}
+
+// Logger returns the diagnostic logger
+func Logger() *slog.Logger {
+	return logger
+}
+
+// NewVerboseLogger returns a logger writing debug level text lines to w
+func NewVerboseLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
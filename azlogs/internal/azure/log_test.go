@@ -0,0 +1,39 @@
+package azure
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetLogger(t *testing.T) {
+	defer SetLogger(nil)
+
+	var buf bytes.Buffer
+	SetLogger(NewVerboseLogger(&buf))
+	Logger().Debug("sending query", "query", "Heartbeat | take 1")
+	if !strings.Contains(buf.String(), `msg="sending query"`) || !strings.Contains(buf.String(), "Heartbeat | take 1") {
+		t.Errorf("Expected debug line in log, got %q", buf.String())
+	}
+
+	SetLogger(nil)
+	buf.Reset()
+	Logger().Debug("sending query")
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output after disabling the logger, got %q", buf.String())
+	}
+}
+
+func TestTimespanAttr(t *testing.T) {
+	if got := timespanAttr(nil); got != "" {
+		t.Errorf("Expected empty timespan, got %q", got)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts := &TimeSpan{Start: start, End: start.Add(time.Hour)}
+	want := "2024-01-01T00:00:00Z/2024-01-01T01:00:00Z"
+	if got := timespanAttr(ts); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
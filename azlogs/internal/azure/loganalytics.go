@@ -2,7 +2,10 @@ package azure
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
@@ -13,15 +16,31 @@ import (
 type LogAnalyticsClient struct {
 	client      *azquery.LogsClient
 	workspaceID string
+	maxRows     int  // 0 for no limit
+	noTableScan bool // Never search table data to list tables
 }
 
 // QueryResult represents the result of a Log Analytics query
 type QueryResult struct {
 	Tables      []Table
 	Statistics  string
-	Duration    time.Duration
+	Duration    time.Duration // Wall-clock time including network and queueing
 	RowCount    int
 	QueryStatus string
+	AsOf        time.Time // When the response was received
+	Truncated   bool      // Rows past the client's row cap were dropped
+
+	// ExecutionTime is the server-reported time spent running the query,
+	// 0 when the service didn't report it
+	ExecutionTime time.Duration
+
+	// PartialError describes why the result is incomplete when QueryStatus
+	// is partial
+	PartialError string
+
+	// Render is the chart type the query asked for with `| render`, such
+	// as timechart or barchart, empty when it has none
+	Render string
 }
 
 // Table represents a result table from a query
@@ -43,12 +62,26 @@ type TimeSpan struct {
 	End   time.Time
 }
 
-// NewLogAnalyticsClient creates a new Log Analytics client
+// NewLogAnalyticsClient creates a new Log Analytics client for the public
+// cloud
 func NewLogAnalyticsClient(cred azcore.TokenCredential, workspaceID string) (*LogAnalyticsClient, error) {
-	client, err := azquery.NewLogsClient(cred, nil)
+	return NewCloudLogAnalyticsClient(cred, workspaceID, CloudPublic)
+}
+
+// NewCloudLogAnalyticsClient creates a Log Analytics client that queries
+// the endpoint of the given cloud
+func NewCloudLogAnalyticsClient(cred azcore.TokenCredential, workspaceID string, c Cloud) (*LogAnalyticsClient, error) {
+	options := &azquery.LogsClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Cloud:     c.logsConfiguration(),
+			Transport: progressTransport{client: http.DefaultClient},
+		},
+	}
+	client, err := azquery.NewLogsClient(cred, options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create logs client: %w", err)
 	}
+	logger.Debug("using Log Analytics", "workspace", workspaceID, "cloud", c.Name, "endpoint", c.LogAnalyticsEndpoint)
 
 	return &LogAnalyticsClient{
 		client:      client,
@@ -61,6 +94,11 @@ func (c *LogAnalyticsClient) SetWorkspace(workspaceID string) {
 	c.workspaceID = workspaceID
 }
 
+// SetMaxRows caps the number of rows kept per query result (0 for no limit)
+func (c *LogAnalyticsClient) SetMaxRows(maxRows int) {
+	c.maxRows = maxRows
+}
+
 // GetWorkspace returns the current workspace ID
 func (c *LogAnalyticsClient) GetWorkspace() string {
 	return c.workspaceID
@@ -80,20 +118,32 @@ func (c *LogAnalyticsClient) Query(ctx context.Context, query string, timespan *
 		body.Timespan = &ts
 	}
 
-	resp, err := c.client.QueryWorkspace(ctx, c.workspaceID, body, nil)
+	statistics, visualization := true, true
+	options := &azquery.LogsClientQueryWorkspaceOptions{
+		Options: &azquery.LogsQueryOptions{Statistics: &statistics, Visualization: &visualization},
+	}
+
+	logger.Debug("sending query", "workspace", c.workspaceID, "timespan", timespanAttr(timespan), "query", query)
+	resp, err := c.client.QueryWorkspace(ctx, c.workspaceID, body, options)
 	if err != nil {
-		return nil, fmt.Errorf("query failed: %w", err)
+		logger.Debug("query failed", "duration", time.Since(start), "error", err)
+		return nil, classifyError(err)
 	}
 
 	duration := time.Since(start)
 	result := &QueryResult{
-		Duration:    duration,
-		QueryStatus: "Success",
+		Statistics:    string(resp.Statistics),
+		Duration:      duration,
+		ExecutionTime: executionTime(resp.Statistics),
+		Render:        renderHint(resp.Visualization),
+		QueryStatus:   "Success",
+		AsOf:          time.Now(),
 	}
 
 	// Handle partial errors
 	if resp.Error != nil && resp.Error.Code != "" {
 		result.QueryStatus = fmt.Sprintf("Partial: %s", resp.Error.Code)
+		result.PartialError = partialErrorMessage(resp.Error.Error())
 	}
 
 	// Process tables
@@ -110,8 +160,12 @@ func (c *LogAnalyticsClient) Query(ctx context.Context, query string, timespan *
 			})
 		}
 
-		// Process rows
+		// Process rows, stopping at the row cap
 		for _, row := range t.Rows {
+			if c.maxRows > 0 && result.RowCount >= c.maxRows {
+				result.Truncated = true
+				break
+			}
 			table.Rows = append(table.Rows, row)
 			result.RowCount++
 		}
@@ -119,9 +173,73 @@ func (c *LogAnalyticsClient) Query(ctx context.Context, query string, timespan *
 		result.Tables = append(result.Tables, table)
 	}
 
+	logger.Debug("query finished", "status", result.QueryStatus, "rows", result.RowCount,
+		"duration", duration, "execution_time", result.ExecutionTime, "truncated", result.Truncated)
 	return result, nil
 }
 
+// timespanAttr formats a query timespan for the log, empty when the query
+// sets its own
+func timespanAttr(ts *TimeSpan) string {
+	if ts == nil {
+		return ""
+	}
+	return ts.Start.Format(time.RFC3339) + "/" + ts.End.Format(time.RFC3339)
+}
+
+// IsPartial reports whether the result is incomplete due to a server-side error
+func (r *QueryResult) IsPartial() bool {
+	return strings.HasPrefix(r.QueryStatus, "Partial")
+}
+
+// partialErrorMessage extracts the readable messages from the raw JSON error
+// returned alongside partial results
+func partialErrorMessage(raw string) string {
+	var info struct {
+		Message string `json:"message"`
+		Details []struct {
+			Message string `json:"message"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal([]byte(raw), &info); err != nil || info.Message == "" {
+		return raw
+	}
+
+	msg := info.Message
+	for _, d := range info.Details {
+		if d.Message != "" && d.Message != info.Message {
+			msg += ": " + d.Message
+		}
+	}
+	return msg
+}
+
+// executionTime extracts the server-side execution time from the query
+// statistics, which report it in seconds
+func executionTime(stats []byte) time.Duration {
+	var info struct {
+		Query struct {
+			ExecutionTime float64 `json:"executionTime"`
+		} `json:"query"`
+	}
+	if len(stats) == 0 || json.Unmarshal(stats, &info) != nil {
+		return 0
+	}
+	return time.Duration(info.Query.ExecutionTime * float64(time.Second))
+}
+
+// renderHint extracts the chart type from a query's visualization
+// properties, empty when the query has no render operator
+func renderHint(visualization []byte) string {
+	var info struct {
+		Visualization string `json:"visualization"`
+	}
+	if len(visualization) == 0 || json.Unmarshal(visualization, &info) != nil {
+		return ""
+	}
+	return strings.ToLower(info.Visualization)
+}
+
 // QueryWithTimeout executes a query with a specific timeout
 func (c *LogAnalyticsClient) QueryWithTimeout(ctx context.Context, query string, timespan *TimeSpan, timeout time.Duration) (*QueryResult, error) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
@@ -129,26 +247,47 @@ func (c *LogAnalyticsClient) QueryWithTimeout(ctx context.Context, query string,
 	return c.Query(ctx, query, timespan)
 }
 
+// Queries listing the tables in a workspace. The Usage table holds a small
+// row per table per hour of ingestion, so reading it is cheap. Searching
+// the data itself is the fallback when Usage is empty or not readable, and
+// is time bounded as search * scans every table.
+const (
+	usageTablesQuery  = `Usage | where TimeGenerated > ago(30d) | distinct DataType | order by DataType asc`
+	searchTablesQuery = `search * | where TimeGenerated > ago(1d) | summarize count() by $table | project $table | order by $table asc`
+)
+
+// DisableTableScan stops GetAvailableTables from falling back to searching
+// table data when the Usage table lists no tables
+func (c *LogAnalyticsClient) DisableTableScan() {
+	c.noTableScan = true
+}
+
 // GetAvailableTables returns a list of tables in the workspace
 func (c *LogAnalyticsClient) GetAvailableTables(ctx context.Context) ([]string, error) {
-	query := `search * | summarize count() by $table | project $table | order by $table asc`
-	result, err := c.Query(ctx, query, nil)
-	if err != nil {
-		return nil, err
+	query := func(ctx context.Context, q string) (*QueryResult, error) {
+		return c.Query(ctx, q, nil)
 	}
+	return availableTables(ctx, query, !c.noTableScan)
+}
 
-	var tables []string
-	if len(result.Tables) > 0 && len(result.Tables[0].Rows) > 0 {
-		for _, row := range result.Tables[0].Rows {
-			if len(row) > 0 {
-				if tableName, ok := row[0].(string); ok {
-					tables = append(tables, tableName)
-				}
-			}
+// availableTables lists tables from the Usage table, falling back to
+// searching table data if allowed to scan
+func availableTables(ctx context.Context, query func(context.Context, string) (*QueryResult, error), scan bool) ([]string, error) {
+	result, err := query(ctx, usageTablesQuery)
+	if err == nil {
+		if tables := firstColumnStrings(result); len(tables) > 0 {
+			return tables, nil
 		}
 	}
+	if !scan {
+		return nil, err
+	}
 
-	return tables, nil
+	result, err = query(ctx, searchTablesQuery)
+	if err != nil {
+		return nil, err
+	}
+	return firstColumnStrings(result), nil
 }
 
 // GetTableSchema returns the schema for a specific table
@@ -159,21 +298,5 @@ func (c *LogAnalyticsClient) GetTableSchema(ctx context.Context, tableName strin
 		return nil, err
 	}
 
-	var columns []Column
-	if len(result.Tables) > 0 {
-		for _, row := range result.Tables[0].Rows {
-			if len(row) >= 2 {
-				col := Column{}
-				if name, ok := row[0].(string); ok {
-					col.Name = name
-				}
-				if colType, ok := row[1].(string); ok {
-					col.Type = colType
-				}
-				columns = append(columns, col)
-			}
-		}
-	}
-
-	return columns, nil
+	return schemaFromResult(result), nil
 }
@@ -2,7 +2,9 @@ package azure
 
 import (
 	"context"
+	"errors"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -130,3 +132,48 @@ func TestLogAnalyticsClient_QueryWithTimeout(t *testing.T) {
 
 	t.Logf("Query completed: %d rows returned in %s", result.RowCount, result.Duration)
 }
+
+func TestAvailableTables(t *testing.T) {
+	tableResult := func(names ...string) *QueryResult {
+		table := Table{Columns: []Column{{Name: "DataType", Type: "string"}}}
+		for _, name := range names {
+			table.Rows = append(table.Rows, []interface{}{name})
+		}
+		return &QueryResult{Tables: []Table{table}}
+	}
+
+	tests := []struct {
+		name     string
+		usage    *QueryResult
+		usageErr error
+		scan     bool
+		expected []string
+		searched bool
+	}{
+		{"from usage", tableResult("Heartbeat", "Perf"), nil, true, []string{"Heartbeat", "Perf"}, false},
+		{"empty usage falls back to search", tableResult(), nil, true, []string{"Searched"}, true},
+		{"usage error falls back to search", nil, errors.New("denied"), true, []string{"Searched"}, true},
+		{"no scan", tableResult(), nil, false, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searched := false
+			query := func(ctx context.Context, q string) (*QueryResult, error) {
+				if q == searchTablesQuery {
+					searched = true
+					return tableResult("Searched"), nil
+				}
+				return tt.usage, tt.usageErr
+			}
+
+			tables, _ := availableTables(context.Background(), query, tt.scan)
+			if strings.Join(tables, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected tables %v, got %v", tt.expected, tables)
+			}
+			if searched != tt.searched {
+				t.Errorf("Expected searched %v, got %v", tt.searched, searched)
+			}
+		})
+	}
+}
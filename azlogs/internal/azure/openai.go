@@ -23,12 +23,27 @@ const (
 	OpenAIAPIVersion        = "2024-12-01-preview"
 )
 
+// Default request timeouts. Suggestions are requested while typing and are
+// only useful if they arrive quickly; explanations and fixes can take longer.
+const (
+	DefaultSuggestTimeout = 5 * time.Second
+	DefaultAssistTimeout  = 60 * time.Second
+)
+
+// MaxAIPromptLength caps the instructions appended to the built-in system
+// prompt, so they can't crowd the table schemas out of the token budget
+const MaxAIPromptLength = 2000
+
 // OpenAIClient handles Azure OpenAI API calls
 type OpenAIClient struct {
 	endpoint       string
 	deploymentName string
 	credential     azcore.TokenCredential
+	scope          string // Token scope for the cloud of the endpoint
 	httpClient     *http.Client
+	suggestTimeout time.Duration // Limit for SuggestKQLQuery
+	assistTimeout  time.Duration // Limit for ExplainKQLQuery and FixKQLQuery
+	instructions   string        // Appended to the system prompt of suggestions and fixes
 }
 
 // ChatMessage represents a message in a chat completion
@@ -69,12 +84,43 @@ func NewOpenAIClient(credential azcore.TokenCredential, endpoint, deploymentName
 		endpoint:       strings.TrimSuffix(endpoint, "/"),
 		deploymentName: deploymentName,
 		credential:     credential,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		scope:          CloudPublic.OpenAIScope(),
+		httpClient:     &http.Client{},
+		suggestTimeout: DefaultSuggestTimeout,
+		assistTimeout:  DefaultAssistTimeout,
 	}
 }
 
+// SetTimeouts sets how long suggestions and explanations or fixes may take.
+// Zero keeps the current value.
+func (c *OpenAIClient) SetTimeouts(suggest, assist time.Duration) {
+	if suggest > 0 {
+		c.suggestTimeout = suggest
+	}
+	if assist > 0 {
+		c.assistTimeout = assist
+	}
+}
+
+// SetCloud requests tokens for Azure OpenAI in the given cloud
+func (c *OpenAIClient) SetCloud(cloud Cloud) {
+	c.scope = cloud.OpenAIScope()
+}
+
+// SetInstructions adds guidance such as a team's KQL conventions to the
+// built-in system prompt of suggestions and fixes
+func (c *OpenAIClient) SetInstructions(instructions string) {
+	c.instructions = strings.TrimSpace(instructions)
+}
+
+// withInstructions appends the configured instructions to a system prompt
+func (c *OpenAIClient) withInstructions(systemPrompt string) string {
+	if c.instructions == "" {
+		return systemPrompt
+	}
+	return systemPrompt + "\n\nAlso follow these instructions from the user:\n" + c.instructions
+}
+
 // NewOpenAIClientWithDefaults creates a client with default Azure OpenAI settings
 func NewOpenAIClientWithDefaults(credential azcore.TokenCredential) *OpenAIClient {
 	return NewOpenAIClient(credential, DefaultOpenAIEndpoint, DefaultDeploymentName)
@@ -83,7 +129,7 @@ func NewOpenAIClientWithDefaults(credential azcore.TokenCredential) *OpenAIClien
 // getToken retrieves an access token for Azure OpenAI
 func (c *OpenAIClient) getToken(ctx context.Context) (string, error) {
 	token, err := c.credential.GetToken(ctx, policy.TokenRequestOptions{
-		Scopes: []string{"https://cognitiveservices.azure.com/.default"},
+		Scopes: []string{c.scope},
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to get token: %w", err)
@@ -120,8 +166,11 @@ func (c *OpenAIClient) Complete(ctx context.Context, messages []ChatMessage, max
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
 
+	start := time.Now()
+	logger.Debug("sending completion request", "url", url, "messages", len(messages))
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		logger.Debug("completion request failed", "duration", time.Since(start), "error", err)
 		return "", fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
@@ -131,6 +180,7 @@ func (c *OpenAIClient) Complete(ctx context.Context, messages []ChatMessage, max
 		return "", fmt.Errorf("failed to read response: %w", err)
 	}
 
+	logger.Debug("completion request finished", "status", resp.StatusCode, "duration", time.Since(start))
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
 	}
@@ -197,10 +247,12 @@ Guidelines:
 	userPrompt := fmt.Sprintf("Complete or suggest a KQL query based on this input:\n%s", partialQuery)
 
 	messages := []ChatMessage{
-		{Role: "system", Content: systemPrompt},
+		{Role: "system", Content: c.withInstructions(systemPrompt)},
 		{Role: "user", Content: userPrompt},
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, c.suggestTimeout)
+	defer cancel()
 	resp, err := c.Complete(ctx, messages, 500)
 	if err != nil {
 		return "", err
@@ -236,6 +288,8 @@ Be concise but thorough. Format your response clearly.`
 		{Role: "user", Content: userPrompt},
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, c.assistTimeout)
+	defer cancel()
 	return c.Complete(ctx, messages, 500)
 }
 
@@ -248,9 +302,11 @@ Only output the corrected query, no explanations.`
 	userPrompt := fmt.Sprintf("Fix this KQL query:\n%s\n\nError: %s", query, errorMsg)
 
 	messages := []ChatMessage{
-		{Role: "system", Content: systemPrompt},
+		{Role: "system", Content: c.withInstructions(systemPrompt)},
 		{Role: "user", Content: userPrompt},
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, c.assistTimeout)
+	defer cancel()
 	return c.Complete(ctx, messages, 500)
 }
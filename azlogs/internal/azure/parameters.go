@@ -0,0 +1,154 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// paramNamePattern matches valid KQL parameter names
+var paramNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// QueryWithParameters executes a query with declared query parameters. The
+// Logs API has no separate parameter payload, so the parameters are sent as a
+// `declare query_parameters(...)` statement with each value encoded as a typed
+// KQL literal, which keeps values from being interpreted as query text.
+func (c *LogAnalyticsClient) QueryWithParameters(ctx context.Context, query string, timespan *TimeSpan, params map[string]interface{}) (*QueryResult, error) {
+	return queryWithParameters(ctx, c, query, timespan, params)
+}
+
+// DeclareParameters builds a `declare query_parameters(...);` statement for
+// the given parameters. It returns an empty string when there are none.
+func DeclareParameters(params map[string]interface{}) (string, error) {
+	if len(params) == 0 {
+		return "", nil
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	decls := make([]string, 0, len(names))
+	for _, name := range names {
+		if !paramNamePattern.MatchString(name) {
+			return "", fmt.Errorf("invalid parameter name %q", name)
+		}
+		kqlType, literal, err := kqlLiteral(params[name])
+		if err != nil {
+			return "", fmt.Errorf("parameter %s: %w", name, err)
+		}
+		decls = append(decls, fmt.Sprintf("%s:%s = %s", name, kqlType, literal))
+	}
+
+	return "declare query_parameters(" + strings.Join(decls, ", ") + ");\n", nil
+}
+
+// kqlLiteral returns the KQL type and literal for a Go value
+func kqlLiteral(v interface{}) (string, string, error) {
+	switch val := v.(type) {
+	case string:
+		return "string", quoteKQLString(val), nil
+	case bool:
+		return "bool", strconv.FormatBool(val), nil
+	case int:
+		return "long", fmt.Sprintf("long(%d)", val), nil
+	case int32:
+		return "long", fmt.Sprintf("long(%d)", val), nil
+	case int64:
+		return "long", fmt.Sprintf("long(%d)", val), nil
+	case float32:
+		return "real", fmt.Sprintf("real(%s)", strconv.FormatFloat(float64(val), 'g', -1, 32)), nil
+	case float64:
+		return "real", fmt.Sprintf("real(%s)", strconv.FormatFloat(val, 'g', -1, 64)), nil
+	case time.Time:
+		return "datetime", fmt.Sprintf("datetime(%s)", val.UTC().Format(time.RFC3339Nano)), nil
+	case time.Duration:
+		// 1 tick = 100ns
+		return "timespan", fmt.Sprintf("timespan(%dtick)", val.Nanoseconds()/100), nil
+	default:
+		return "", "", fmt.Errorf("unsupported parameter type %T", v)
+	}
+}
+
+// quoteKQLString returns s as a double-quoted KQL string literal
+func quoteKQLString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// ParseParameter parses a "name=value" or "name:type=value" parameter as
+// given on the command line. Supported types are string (the default), long,
+// real, bool, datetime (RFC 3339) and timespan (Go duration, e.g. 90s).
+func ParseParameter(s string) (string, interface{}, error) {
+	spec, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return "", nil, fmt.Errorf("invalid parameter %q, expected name=value", s)
+	}
+
+	name, kqlType, _ := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+	if !paramNamePattern.MatchString(name) {
+		return "", nil, fmt.Errorf("invalid parameter name %q", name)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(kqlType)) {
+	case "", "string":
+		return name, value, nil
+	case "long", "int":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("parameter %s: invalid long %q", name, value)
+		}
+		return name, n, nil
+	case "real", "double":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("parameter %s: invalid real %q", name, value)
+		}
+		return name, f, nil
+	case "bool":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("parameter %s: invalid bool %q", name, value)
+		}
+		return name, b, nil
+	case "datetime":
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return "", nil, fmt.Errorf("parameter %s: invalid datetime %q", name, value)
+		}
+		return name, t, nil
+	case "timespan":
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("parameter %s: invalid timespan %q", name, value)
+		}
+		return name, d, nil
+	default:
+		return "", nil, fmt.Errorf("parameter %s: unsupported type %q", name, kqlType)
+	}
+}
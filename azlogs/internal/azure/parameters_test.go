@@ -0,0 +1,77 @@
+package azure
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeclareParameters(t *testing.T) {
+	got, err := DeclareParameters(map[string]interface{}{
+		"name":    `x" | take 1 //`,
+		"limit":   int64(10),
+		"enabled": true,
+		"since":   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"window":  90 * time.Second,
+		"ratio":   0.5,
+	})
+	if err != nil {
+		t.Fatalf("DeclareParameters failed: %v", err)
+	}
+
+	want := "declare query_parameters(enabled:bool = true, limit:long = long(10), " +
+		`name:string = "x\" | take 1 //", ratio:real = real(0.5), ` +
+		"since:datetime = datetime(2024-01-02T03:04:05Z), window:timespan = timespan(900000000tick));\n"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestDeclareParameters_Invalid(t *testing.T) {
+	if _, err := DeclareParameters(map[string]interface{}{"bad name": "x"}); err == nil {
+		t.Error("Expected error for invalid parameter name")
+	}
+	if _, err := DeclareParameters(map[string]interface{}{"p": []string{"x"}}); err == nil {
+		t.Error("Expected error for unsupported parameter type")
+	}
+	if got, err := DeclareParameters(nil); err != nil || got != "" {
+		t.Errorf("Expected empty declaration, got %q (err %v)", got, err)
+	}
+}
+
+func TestParseParameter(t *testing.T) {
+	tests := []struct {
+		input   string
+		name    string
+		value   interface{}
+		wantErr bool
+	}{
+		{"user=alice", "user", "alice", false},
+		{"q=a=b", "q", "a=b", false},
+		{"limit:long=25", "limit", int64(25), false},
+		{"ratio:real=1.5", "ratio", 1.5, false},
+		{"on:bool=true", "on", true, false},
+		{"window:timespan=5m", "window", 5 * time.Minute, false},
+		{"limit:long=abc", "", nil, true},
+		{"novalue", "", nil, true},
+		{"1bad=x", "", nil, true},
+		{"x:guid=1", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			name, value, err := ParseParameter(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if name != tt.name || value != tt.value {
+				t.Errorf("Expected %s=%v, got %s=%v", tt.name, tt.value, name, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package azure
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// configDirOverride is set from the --config-dir flag
+var configDirOverride string
+
+// profile is set from the --profile flag
+var profile string
+
+// SetConfigDir overrides the directory used for config, history and templates
+func SetConfigDir(dir string) {
+	configDirOverride = dir
+}
+
+// SetProfile keeps all state for the named profile in its own directory
+// under profiles/. An empty name selects the default, unnamespaced state.
+func SetProfile(name string) error {
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	profile = name
+	return nil
+}
+
+// ConfigDir returns the directory where all state files are stored. It is
+// resolved in order from SetConfigDir, AZLOGS_CONFIG_DIR,
+// $XDG_CONFIG_HOME/azlogs and ~/.config/azlogs, then namespaced to
+// profiles/<name> when a profile is set.
+func ConfigDir() string {
+	if profile != "" {
+		return filepath.Join(baseConfigDir(), "profiles", profile)
+	}
+	return baseConfigDir()
+}
+
+// baseConfigDir returns the config directory shared by all profiles
+func baseConfigDir() string {
+	if configDirOverride != "" {
+		return configDirOverride
+	}
+	if dir := os.Getenv("AZLOGS_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "azlogs")
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	return filepath.Join(homeDir, ".config", "azlogs")
+}
@@ -0,0 +1,85 @@
+package azure
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigDir(t *testing.T) {
+	t.Setenv("HOME", "/home/test")
+
+	t.Setenv("AZLOGS_CONFIG_DIR", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	if got, want := ConfigDir(), filepath.Join("/home/test", ".config", "azlogs"); got != want {
+		t.Errorf("Expected default dir %q, got %q", want, got)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	if got, want := ConfigDir(), filepath.Join("/xdg", "azlogs"); got != want {
+		t.Errorf("Expected XDG dir %q, got %q", want, got)
+	}
+
+	t.Setenv("AZLOGS_CONFIG_DIR", "/env/azlogs")
+	if got := ConfigDir(); got != "/env/azlogs" {
+		t.Errorf("Expected env dir '/env/azlogs', got %q", got)
+	}
+
+	SetConfigDir("/flag/azlogs")
+	defer SetConfigDir("")
+	if got := ConfigDir(); got != "/flag/azlogs" {
+		t.Errorf("Expected flag dir '/flag/azlogs', got %q", got)
+	}
+}
+
+func TestConfigDir_Profile(t *testing.T) {
+	SetConfigDir("/base")
+	defer SetConfigDir("")
+
+	if err := SetProfile("client-a"); err != nil {
+		t.Fatalf("Failed to set profile: %v", err)
+	}
+	defer SetProfile("")
+	if got, want := ConfigDir(), filepath.Join("/base", "profiles", "client-a"); got != want {
+		t.Errorf("Expected profile dir %q, got %q", want, got)
+	}
+	if got, want := NewTemplates().filePath, filepath.Join("/base", "profiles", "client-a", "templates.json"); got != want {
+		t.Errorf("Expected templates in the profile dir %q, got %q", want, got)
+	}
+
+	for _, name := range []string{"../escape", "a/b", ".."} {
+		if err := SetProfile(name); err == nil {
+			t.Errorf("Expected profile name %q to be rejected", name)
+		}
+	}
+
+	SetProfile("")
+	if got := ConfigDir(); got != "/base" {
+		t.Errorf("Expected no profile to use the base dir, got %q", got)
+	}
+}
+
+func TestConfigDir_SharedByStateFiles(t *testing.T) {
+	dir := t.TempDir()
+	SetConfigDir(dir)
+	defer SetConfigDir("")
+
+	if got := NewHistory(10).filePath; got != filepath.Join(dir, "history.json") {
+		t.Errorf("Expected history in %s, got %s", dir, got)
+	}
+	if got := NewTemplates().filePath; got != filepath.Join(dir, "templates.json") {
+		t.Errorf("Expected templates in %s, got %s", dir, got)
+	}
+
+	config := NewConfig()
+	config.DefaultWorkspace = "test-workspace"
+	if err := config.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	loaded := NewConfig()
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if loaded.DefaultWorkspace != "test-workspace" {
+		t.Errorf("Expected workspace 'test-workspace', got '%s'", loaded.DefaultWorkspace)
+	}
+}
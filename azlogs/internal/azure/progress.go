@@ -0,0 +1,65 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// Progress counts the bytes of a query's response as it downloads. Neither
+// backend streams rows, so this is the only sign of progress before the
+// result arrives. It is safe to read while the query runs.
+type Progress struct {
+	bytes atomic.Int64
+}
+
+// Bytes returns how many bytes of the response have been received
+func (p *Progress) Bytes() int64 {
+	return p.bytes.Load()
+}
+
+// progressKey is the context key of a query's Progress
+type progressKey struct{}
+
+// WithProgress returns a context whose queries count their response bytes
+// into p
+func WithProgress(ctx context.Context, p *Progress) context.Context {
+	return context.WithValue(ctx, progressKey{}, p)
+}
+
+// countingBody counts the bytes read from a response body
+type countingBody struct {
+	io.ReadCloser
+	progress *Progress
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.progress.bytes.Add(int64(n))
+	return n, err
+}
+
+// progressBody wraps a response body to count it into the context's
+// Progress, if it has one
+func progressBody(ctx context.Context, body io.ReadCloser) io.ReadCloser {
+	p, ok := ctx.Value(progressKey{}).(*Progress)
+	if !ok || p == nil {
+		return body
+	}
+	return &countingBody{ReadCloser: body, progress: p}
+}
+
+// progressTransport sends the SDK's requests, counting response bodies as
+// the pipeline downloads them
+type progressTransport struct {
+	client *http.Client
+}
+
+func (t progressTransport) Do(req *http.Request) (*http.Response, error) {
+	resp, err := t.client.Do(req)
+	if err == nil {
+		resp.Body = progressBody(req.Context(), resp.Body)
+	}
+	return resp, err
+}
@@ -0,0 +1,25 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestProgressBody(t *testing.T) {
+	p := &Progress{}
+	body := progressBody(WithProgress(context.Background(), p), io.NopCloser(strings.NewReader(`{"tables":[]}`)))
+	if _, err := io.ReadAll(body); err != nil {
+		t.Fatal(err)
+	}
+	if p.Bytes() != 13 {
+		t.Errorf("Expected 13 bytes counted, got %d", p.Bytes())
+	}
+
+	// Without a Progress the body is left alone
+	plain := io.NopCloser(strings.NewReader("x"))
+	if progressBody(context.Background(), plain) != plain {
+		t.Error("Expected the body unwrapped without a Progress")
+	}
+}
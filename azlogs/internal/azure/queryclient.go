@@ -0,0 +1,131 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+// QueryClient runs KQL queries against a Log Analytics workspace or an
+// Azure Data Explorer database
+type QueryClient interface {
+	// Query executes a KQL query, limited to timespan when the backend
+	// supports a query-wide time range
+	Query(ctx context.Context, query string, timespan *TimeSpan) (*QueryResult, error)
+
+	// QueryWithParameters executes a query with declared query parameters
+	QueryWithParameters(ctx context.Context, query string, timespan *TimeSpan, params map[string]interface{}) (*QueryResult, error)
+
+	// GetAvailableTables returns the names of the tables that can be queried
+	GetAvailableTables(ctx context.Context) ([]string, error)
+
+	// GetTableSchema returns the columns of a table
+	GetTableSchema(ctx context.Context, tableName string) ([]Column, error)
+
+	// SetMaxRows caps the number of rows kept per query result (0 for no limit)
+	SetMaxRows(maxRows int)
+}
+
+// NewQueryClient creates a client for the Data Explorer database set in the
+// config, or for the workspace in the config's cloud if no cluster is set
+func NewQueryClient(cred azcore.TokenCredential, workspaceID string, config *Config) (QueryClient, error) {
+	var client QueryClient
+	var err error
+	if config.Cluster != "" {
+		client, err = NewDataExplorerClient(cred, config.Cluster, config.Database)
+	} else {
+		var la *LogAnalyticsClient
+		la, err = NewCloudLogAnalyticsClient(cred, workspaceID, config.AzureCloud())
+		if err == nil && config.NoTableScan {
+			la.DisableTableScan()
+		}
+		client = la
+	}
+	if err != nil {
+		return nil, err
+	}
+	client.SetMaxRows(config.ResultRowLimit())
+	return client, nil
+}
+
+// queryWithParameters runs a query with its parameters declared in a
+// `declare query_parameters(...)` statement, as both backends accept
+func queryWithParameters(ctx context.Context, c QueryClient, query string, timespan *TimeSpan, params map[string]interface{}) (*QueryResult, error) {
+	declare, err := DeclareParameters(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.Query(ctx, declare+query, timespan)
+}
+
+// firstColumnStrings returns the string values of the first column of the
+// first result table
+func firstColumnStrings(result *QueryResult) []string {
+	var values []string
+	if len(result.Tables) > 0 {
+		for _, row := range result.Tables[0].Rows {
+			if len(row) > 0 {
+				if s, ok := row[0].(string); ok {
+					values = append(values, s)
+				}
+			}
+		}
+	}
+	return values
+}
+
+// dataTypeNames maps the .NET types in getschema's DataType column to KQL
+// type names, for results without a ColumnType column
+var dataTypeNames = map[string]string{
+	"System.String":                   "string",
+	"System.Int32":                    "int",
+	"System.Int64":                    "long",
+	"System.Double":                   "real",
+	"System.Data.SqlTypes.SqlDecimal": "decimal",
+	"System.DateTime":                 "datetime",
+	"System.TimeSpan":                 "timespan",
+	"System.SByte":                    "bool",
+	"System.Boolean":                  "bool",
+	"System.Guid":                     "guid",
+	"System.Object":                   "dynamic",
+}
+
+// schemaFromResult reads the columns from a getschema result. Its columns
+// (ColumnName, ColumnOrdinal, DataType, ColumnType) are looked up by name
+// rather than position.
+func schemaFromResult(result *QueryResult) []Column {
+	if len(result.Tables) == 0 {
+		return nil
+	}
+	table := result.Tables[0]
+	nameCol := columnIndex(table.Columns, "ColumnName")
+	typeCol := columnIndex(table.Columns, "ColumnType")
+	dataTypeCol := columnIndex(table.Columns, "DataType")
+	if nameCol < 0 {
+		return nil
+	}
+
+	var columns []Column
+	for _, row := range table.Rows {
+		col := Column{Name: stringAt(row, nameCol)}
+		if col.Name == "" {
+			continue
+		}
+		col.Type = stringAt(row, typeCol)
+		if col.Type == "" {
+			col.Type = dataTypeNames[stringAt(row, dataTypeCol)]
+		}
+		columns = append(columns, col)
+	}
+	return columns
+}
+
+// stringAt returns the string value at index i of a row, or "" if there is
+// none
+func stringAt(row []interface{}, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	s, _ := row[i].(string)
+	return s
+}
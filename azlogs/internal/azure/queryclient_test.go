@@ -0,0 +1,47 @@
+package azure
+
+import "testing"
+
+func TestSchemaFromResult(t *testing.T) {
+	// getschema as returned by the service: ordinal second, .NET type third
+	result := &QueryResult{
+		Tables: []Table{{
+			Columns: []Column{
+				{Name: "ColumnName", Type: "string"},
+				{Name: "ColumnOrdinal", Type: "int"},
+				{Name: "DataType", Type: "string"},
+				{Name: "ColumnType", Type: "string"},
+			},
+			Rows: [][]interface{}{
+				{"TimeGenerated", 0.0, "System.DateTime", "datetime"},
+				{"Computer", 1.0, "System.String", "string"},
+				{"CounterValue", 2.0, "System.Double", "real"},
+			},
+		}},
+	}
+
+	columns := schemaFromResult(result)
+	expected := []Column{{"TimeGenerated", "datetime"}, {"Computer", "string"}, {"CounterValue", "real"}}
+	if len(columns) != len(expected) {
+		t.Fatalf("Expected %d columns, got %v", len(expected), columns)
+	}
+	for i := range expected {
+		if columns[i] != expected[i] {
+			t.Errorf("Column %d: expected %v, got %v", i, expected[i], columns[i])
+		}
+	}
+}
+
+func TestSchemaFromResult_DataTypeOnly(t *testing.T) {
+	result := &QueryResult{
+		Tables: []Table{{
+			Columns: []Column{{Name: "DataType", Type: "string"}, {Name: "ColumnName", Type: "string"}},
+			Rows:    [][]interface{}{{"System.Int64", "Count"}, {"System.Object", "Props"}},
+		}},
+	}
+
+	columns := schemaFromResult(result)
+	if len(columns) != 2 || columns[0] != (Column{"Count", "long"}) || columns[1] != (Column{"Props", "dynamic"}) {
+		t.Errorf("Expected types mapped from DataType, got %v", columns)
+	}
+}
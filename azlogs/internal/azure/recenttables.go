@@ -0,0 +1,80 @@
+package azure
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// maxRecentTables bounds how many tables are remembered per workspace
+const maxRecentTables = 10
+
+// RecentTables remembers the tables most recently queried in each workspace
+type RecentTables struct {
+	Workspaces map[string][]string `json:"workspaces"`
+	filePath   string
+}
+
+// NewRecentTables creates a new recent tables tracker
+func NewRecentTables() *RecentTables {
+	r := &RecentTables{
+		Workspaces: make(map[string][]string),
+	}
+	r.setDefaultPath()
+	return r
+}
+
+// setDefaultPath sets the default recent tables file path
+func (r *RecentTables) setDefaultPath() {
+	r.filePath = filepath.Join(ConfigDir(), "recent_tables.json")
+}
+
+// Load reads recent tables from disk
+func (r *RecentTables) Load() error {
+	err := readJSONFile(r.filePath, r)
+	if r.Workspaces == nil {
+		r.Workspaces = make(map[string][]string)
+	}
+	return err
+}
+
+// Save writes recent tables to disk
+func (r *RecentTables) Save() error {
+	// Ensure directory exists
+	dir := filepath.Dir(r.filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(r.filePath, data, 0644)
+}
+
+// Add moves the tables to the front of the workspace's list, most recent
+// first, forgetting the oldest beyond maxRecentTables
+func (r *RecentTables) Add(workspace string, tables []string) {
+	if len(tables) == 0 {
+		return
+	}
+
+	recent := append([]string{}, tables...)
+	for _, t := range r.Workspaces[workspace] {
+		if !slices.Contains(tables, t) {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) > maxRecentTables {
+		recent = recent[:maxRecentTables]
+	}
+	r.Workspaces[workspace] = recent
+}
+
+// Get returns the workspace's recently queried tables, most recent first
+func (r *RecentTables) Get(workspace string) []string {
+	return r.Workspaces[workspace]
+}
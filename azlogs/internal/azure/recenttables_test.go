@@ -0,0 +1,60 @@
+package azure
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRecentTables_Add(t *testing.T) {
+	r := NewRecentTables()
+	r.Add("ws1", []string{"SigninLogs"})
+	r.Add("ws1", []string{"AuditLogs", "Heartbeat"})
+	r.Add("ws1", []string{"SigninLogs"})
+	r.Add("ws2", []string{"Perf"})
+	r.Add("ws2", nil)
+
+	if got, want := r.Get("ws1"), []string{"SigninLogs", "AuditLogs", "Heartbeat"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got, want := r.Get("ws2"), []string{"Perf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v for a different workspace, got %v", want, got)
+	}
+	if got := r.Get("unknown"); len(got) != 0 {
+		t.Errorf("Expected no tables for an unknown workspace, got %v", got)
+	}
+}
+
+func TestRecentTables_Bounded(t *testing.T) {
+	r := NewRecentTables()
+	for i := 0; i < maxRecentTables+5; i++ {
+		r.Add("ws", []string{fmt.Sprintf("T%d", i)})
+	}
+
+	got := r.Get("ws")
+	if len(got) != maxRecentTables {
+		t.Fatalf("Expected %d tables, got %d", maxRecentTables, len(got))
+	}
+	if want := fmt.Sprintf("T%d", maxRecentTables+4); got[0] != want {
+		t.Errorf("Expected most recent table %s first, got %s", want, got[0])
+	}
+}
+
+func TestRecentTables_SaveLoad(t *testing.T) {
+	r := NewRecentTables()
+	r.filePath = filepath.Join(t.TempDir(), "recent_tables.json")
+	r.Add("ws", []string{"SigninLogs"})
+	if err := r.Save(); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	loaded := NewRecentTables()
+	loaded.filePath = r.filePath
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if got := loaded.Get("ws"); !reflect.DeepEqual(got, []string{"SigninLogs"}) {
+		t.Errorf("Expected [SigninLogs] after reload, got %v", got)
+	}
+}
@@ -0,0 +1,59 @@
+package azure
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SlowQueryLog appends queries that exceeded the slow query threshold to
+// slow-queries.log
+type SlowQueryLog struct {
+	filePath string
+}
+
+// NewSlowQueryLog creates a slow query log in the config directory
+func NewSlowQueryLog() *SlowQueryLog {
+	l := &SlowQueryLog{}
+	l.setDefaultPath()
+	return l
+}
+
+// setDefaultPath sets the default slow query log path
+func (l *SlowQueryLog) setDefaultPath() {
+	l.filePath = filepath.Join(ConfigDir(), "slow-queries.log")
+}
+
+// Append records a query with its total and server execution times
+func (l *SlowQueryLog) Append(workspace, query string, total, execution time.Duration, at time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(l.filePath), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(formatSlowQuery(workspace, query, total, execution, at))
+	return err
+}
+
+// formatSlowQuery formats a slow query log entry: a header line with the
+// timings followed by the indented query and a blank line
+func formatSlowQuery(workspace, query string, total, execution time.Duration, at time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s workspace=%s total=%s", at.Format(time.RFC3339), workspace, total.Round(time.Millisecond))
+	if execution > 0 {
+		fmt.Fprintf(&b, " execution=%s", execution.Round(time.Millisecond))
+	}
+	b.WriteString("\n")
+	for _, line := range strings.Split(strings.TrimSpace(query), "\n") {
+		b.WriteString("    " + line + "\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
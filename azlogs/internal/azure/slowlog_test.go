@@ -0,0 +1,51 @@
+package azure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSlowQueryLog_Append(t *testing.T) {
+	l := &SlowQueryLog{filePath: filepath.Join(t.TempDir(), "slow-queries.log")}
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := l.Append("ws1", "T\n| take 5", 2500*time.Millisecond, 1200*time.Millisecond, at); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := l.Append("ws1", "U", time.Second, 0, at); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	data, err := os.ReadFile(l.filePath)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	expected := "2024-03-01T12:00:00Z workspace=ws1 total=2.5s execution=1.2s\n    T\n    | take 5\n\n" +
+		"2024-03-01T12:00:00Z workspace=ws1 total=1s\n    U\n\n"
+	if string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(data))
+	}
+}
+
+func TestExecutionTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		stats    string
+		expected time.Duration
+	}{
+		{"reported", `{"query":{"executionTime":0.25,"resourceUsage":{}}}`, 250 * time.Millisecond},
+		{"missing", `{"query":{}}`, 0},
+		{"empty", ``, 0},
+		{"invalid", `not json`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := executionTime([]byte(tt.stats)); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
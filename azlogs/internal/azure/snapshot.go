@@ -0,0 +1,67 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Snapshot is a query result saved to disk so it can be reloaded later
+// without re-running the query
+type Snapshot struct {
+	Query     string       `json:"query"`
+	Workspace string       `json:"workspace,omitempty"`
+	SavedAt   time.Time    `json:"saved_at"`
+	Result    *QueryResult `json:"result"`
+}
+
+// NewSnapshot captures a result of a query
+func NewSnapshot(query, workspace string, result *QueryResult) *Snapshot {
+	return &Snapshot{
+		Query:     query,
+		Workspace: workspace,
+		SavedAt:   time.Now(),
+		Result:    result,
+	}
+}
+
+// DefaultSnapshotPath returns a file for a snapshot saved at the given time,
+// in the snapshots directory under ConfigDir
+func DefaultSnapshotPath(at time.Time) string {
+	return filepath.Join(ConfigDir(), "snapshots", "result-"+at.Format("20060102-150405")+".json")
+}
+
+// Save writes the snapshot to path as JSON
+func (s *Snapshot) Save(path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadSnapshot reads a snapshot saved with Save
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", path, err)
+	}
+	if s.Result == nil {
+		return nil, fmt.Errorf("invalid snapshot %s: no result", path)
+	}
+	return &s, nil
+}
@@ -0,0 +1,51 @@
+package azure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshot_SaveLoad(t *testing.T) {
+	result := &QueryResult{
+		RowCount:    2,
+		QueryStatus: "Success",
+		AsOf:        time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		Tables: []Table{{
+			Name:    "PrimaryResult",
+			Columns: []Column{{Name: "Computer", Type: "string"}, {Name: "Count", Type: "long"}},
+			Rows:    [][]interface{}{{"web-1", 42.0}, {nil, 7.0}},
+		}},
+	}
+	path := filepath.Join(t.TempDir(), "nested", "incident.json")
+
+	if err := NewSnapshot("Heartbeat | count", "ws-1", result).Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+
+	if loaded.Query != "Heartbeat | count" || loaded.Workspace != "ws-1" {
+		t.Errorf("Expected query and workspace kept, got %q in %q", loaded.Query, loaded.Workspace)
+	}
+	if !loaded.Result.AsOf.Equal(result.AsOf) || loaded.Result.RowCount != 2 {
+		t.Errorf("Expected result metadata kept, got %+v", loaded.Result)
+	}
+	rows := loaded.Result.Tables[0].Rows
+	if len(rows) != 2 || rows[0][0] != "web-1" || rows[0][1] != 42.0 || rows[1][0] != nil {
+		t.Errorf("Expected rows kept, got %v", rows)
+	}
+}
+
+func TestLoadSnapshot_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.json")
+	if err := os.WriteFile(path, []byte(`{"query": "T"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSnapshot(path); err == nil {
+		t.Error("Expected an error for a snapshot without a result")
+	}
+}
@@ -39,25 +39,12 @@ func NewTemplates() *Templates {
 
 // setDefaultPath sets the default templates file path
 func (t *Templates) setDefaultPath() {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = "."
-	}
-	configDir := filepath.Join(homeDir, ".config", "azlogs")
-	t.filePath = filepath.Join(configDir, "templates.json")
+	t.filePath = filepath.Join(ConfigDir(), "templates.json")
 }
 
 // Load reads templates from disk
 func (t *Templates) Load() error {
-	data, err := os.ReadFile(t.filePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // No templates file yet
-		}
-		return err
-	}
-
-	return json.Unmarshal(data, t)
+	return readJSONFile(t.filePath, t)
 }
 
 // Save writes templates to disk
@@ -73,7 +60,7 @@ func (t *Templates) Save() error {
 		return err
 	}
 
-	return os.WriteFile(t.filePath, data, 0644)
+	return writeFileAtomic(t.filePath, data, 0644)
 }
 
 // Add adds a new template
@@ -0,0 +1,105 @@
+package azure
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// maxColumnUsageEntries bounds how many table/column pairs are tracked
+const maxColumnUsageEntries = 500
+
+// ColumnUsage counts how often each table's columns are used in queries
+type ColumnUsage struct {
+	Tables   map[string]map[string]int `json:"tables"`
+	filePath string
+}
+
+// NewColumnUsage creates a new column usage tracker
+func NewColumnUsage() *ColumnUsage {
+	u := &ColumnUsage{
+		Tables: make(map[string]map[string]int),
+	}
+	u.setDefaultPath()
+	return u
+}
+
+// setDefaultPath sets the default column usage file path
+func (u *ColumnUsage) setDefaultPath() {
+	u.filePath = filepath.Join(ConfigDir(), "column_usage.json")
+}
+
+// Load reads column usage from disk
+func (u *ColumnUsage) Load() error {
+	err := readJSONFile(u.filePath, u)
+	if u.Tables == nil {
+		u.Tables = make(map[string]map[string]int)
+	}
+	return err
+}
+
+// Save writes column usage to disk
+func (u *ColumnUsage) Save() error {
+	// Ensure directory exists
+	dir := filepath.Dir(u.filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(u.filePath, data, 0644)
+}
+
+// Record counts one use of a table's column. When the tracker is full the
+// least used column is forgotten to make room.
+func (u *ColumnUsage) Record(table, column string) {
+	columns, ok := u.Tables[table]
+	if !ok {
+		columns = make(map[string]int)
+		u.Tables[table] = columns
+	}
+
+	if _, tracked := columns[column]; !tracked && u.entries() >= maxColumnUsageEntries {
+		u.evictLeastUsed()
+	}
+	columns[column]++
+}
+
+// Count returns how often a table's column has been used
+func (u *ColumnUsage) Count(table, column string) int {
+	return u.Tables[table][column]
+}
+
+// entries returns the number of table/column pairs tracked
+func (u *ColumnUsage) entries() int {
+	n := 0
+	for _, columns := range u.Tables {
+		n += len(columns)
+	}
+	return n
+}
+
+// evictLeastUsed removes the table/column pair with the lowest count
+func (u *ColumnUsage) evictLeastUsed() {
+	var minTable, minColumn string
+	minCount := -1
+	for table, columns := range u.Tables {
+		for column, count := range columns {
+			if minCount == -1 || count < minCount {
+				minTable, minColumn, minCount = table, column, count
+			}
+		}
+	}
+	if minCount == -1 {
+		return
+	}
+
+	delete(u.Tables[minTable], minColumn)
+	if len(u.Tables[minTable]) == 0 {
+		delete(u.Tables, minTable)
+	}
+}
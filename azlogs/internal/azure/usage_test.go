@@ -0,0 +1,58 @@
+package azure
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestColumnUsage_Record(t *testing.T) {
+	u := NewColumnUsage()
+	u.Record("SigninLogs", "UserPrincipalName")
+	u.Record("SigninLogs", "UserPrincipalName")
+	u.Record("AuditLogs", "UserPrincipalName")
+
+	if got := u.Count("SigninLogs", "UserPrincipalName"); got != 2 {
+		t.Errorf("Expected count 2, got %d", got)
+	}
+	if got := u.Count("AuditLogs", "UserPrincipalName"); got != 1 {
+		t.Errorf("Expected count 1 for a different table, got %d", got)
+	}
+	if got := u.Count("Unknown", "Column"); got != 0 {
+		t.Errorf("Expected count 0 for an unknown column, got %d", got)
+	}
+}
+
+func TestColumnUsage_Bounded(t *testing.T) {
+	u := NewColumnUsage()
+	u.Record("T", "Frequent")
+	u.Record("T", "Frequent")
+	for i := 0; i < maxColumnUsageEntries+10; i++ {
+		u.Record("T", fmt.Sprintf("Col%d", i))
+	}
+
+	if got := u.entries(); got != maxColumnUsageEntries {
+		t.Errorf("Expected %d entries, got %d", maxColumnUsageEntries, got)
+	}
+	if got := u.Count("T", "Frequent"); got != 2 {
+		t.Errorf("Expected the most used column to be kept, got count %d", got)
+	}
+}
+
+func TestColumnUsage_SaveLoad(t *testing.T) {
+	u := NewColumnUsage()
+	u.filePath = filepath.Join(t.TempDir(), "column_usage.json")
+	u.Record("SigninLogs", "ResultType")
+	if err := u.Save(); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	loaded := NewColumnUsage()
+	loaded.filePath = u.filePath
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if got := loaded.Count("SigninLogs", "ResultType"); got != 1 {
+		t.Errorf("Expected count 1 after reload, got %d", got)
+	}
+}
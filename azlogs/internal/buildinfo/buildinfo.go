@@ -0,0 +1,92 @@
+// Package buildinfo reports the version of the binary and how it was built
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Build metadata set at build time, e.g.
+//
+//	go build -ldflags "-X github.com/codyseavey/tools/azlogs/internal/buildinfo.Commit=$(git rev-parse HEAD)"
+//
+// Whatever is left empty is read from the build info Go embeds in the binary
+var (
+	Version = ""
+	Commit  = ""
+	Date    = ""
+)
+
+// defaultVersion is reported when neither -ldflags nor the module version
+// set one, as for a build from a source checkout
+const defaultVersion = "1.0.0"
+
+// Info describes the running binary
+type Info struct {
+	Version   string
+	Commit    string
+	Date      string
+	Modified  bool // Built from a checkout with uncommitted changes
+	GoVersion string
+}
+
+// Get returns the build metadata, preferring values set with -ldflags
+func Get() Info {
+	bi, _ := debug.ReadBuildInfo()
+	return resolve(bi)
+}
+
+// resolve fills in whatever -ldflags left unset from the embedded build info,
+// which may be nil
+func resolve(bi *debug.BuildInfo) Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date, GoVersion: runtime.Version()}
+	if bi != nil {
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = strings.TrimPrefix(bi.Main.Version, "v")
+		}
+		if bi.GoVersion != "" {
+			info.GoVersion = bi.GoVersion
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = s.Value
+				}
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = defaultVersion
+	}
+	return info
+}
+
+// String returns the version followed by a line for each known detail
+func (i Info) String() string {
+	var b strings.Builder
+	b.WriteString(i.Version)
+	if i.Commit != "" {
+		commit := i.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if i.Modified {
+			commit += " (modified)"
+		}
+		fmt.Fprintf(&b, "\n  commit: %s", commit)
+	}
+	if i.Date != "" {
+		fmt.Fprintf(&b, "\n  built:  %s", i.Date)
+	}
+	fmt.Fprintf(&b, "\n  go:     %s", i.GoVersion)
+	return b.String()
+}
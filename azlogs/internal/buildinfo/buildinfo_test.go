@@ -0,0 +1,44 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	bi := &debug.BuildInfo{
+		GoVersion: "go1.24.1",
+		Main:      debug.Module{Version: "v1.2.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123456789abcdef"},
+			{Key: "vcs.time", Value: "2024-05-01T10:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	info := resolve(bi)
+	expected := Info{Version: "1.2.0", Commit: "0123456789abcdef", Date: "2024-05-01T10:00:00Z", Modified: true, GoVersion: "go1.24.1"}
+	if info != expected {
+		t.Errorf("Expected %+v, got %+v", expected, info)
+	}
+	if s := info.String(); s != "1.2.0\n  commit: 0123456789ab (modified)\n  built:  2024-05-01T10:00:00Z\n  go:     go1.24.1" {
+		t.Errorf("Unexpected version output %q", s)
+	}
+
+	// Values set with -ldflags win
+	Version, Commit = "2.0.0", "fedcba"
+	defer func() { Version, Commit = "", "" }()
+	if info := resolve(bi); info.Version != "2.0.0" || info.Commit != "fedcba" {
+		t.Errorf("Expected the ldflags values, got %+v", info)
+	}
+}
+
+func TestResolve_Devel(t *testing.T) {
+	info := resolve(&debug.BuildInfo{Main: debug.Module{Version: "(devel)"}})
+	if info.Version != defaultVersion || info.Commit != "" {
+		t.Errorf("Expected the default version without a commit, got %+v", info)
+	}
+	if info := resolve(nil); info.Version != defaultVersion || info.GoVersion == "" {
+		t.Errorf("Expected the default version and the runtime Go version, got %+v", info)
+	}
+}
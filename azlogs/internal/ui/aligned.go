@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// AlignedText lays out a header and rows of cells as columns padded to a
+// common width, with a rule of dashes under the header, for monospace
+// places like code blocks in chat. Cells are collapsed onto one line and
+// values wider than maxWidth are truncated
+func AlignedText(header []string, rows [][]string, maxWidth int) string {
+	lines := make([][]string, 0, len(rows)+1)
+	lines = append(lines, header)
+	for _, row := range rows {
+		line := make([]string, len(header))
+		for i := range line {
+			if i < len(row) {
+				line[i] = strings.Join(strings.Fields(row[i]), " ")
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	widths := make([]int, len(header))
+	for _, line := range lines {
+		for i, cell := range line {
+			widths[i] = max(widths[i], min(runewidth.StringWidth(cell), maxWidth))
+		}
+	}
+
+	var b strings.Builder
+	writeLine := func(line []string) {
+		for i, cell := range line {
+			cell = runewidth.Truncate(cell, widths[i], "…")
+			if i == len(line)-1 {
+				b.WriteString(cell)
+			} else {
+				b.WriteString(runewidth.FillRight(cell, widths[i]) + "  ")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	writeLine(lines[0])
+	rule := make([]string, len(widths))
+	for i, width := range widths {
+		rule[i] = strings.Repeat("-", width)
+	}
+	writeLine(rule)
+	for _, line := range lines[1:] {
+		writeLine(line)
+	}
+	return b.String()
+}
+
+// copyAligned copies the displayed result, without hidden columns, as
+// aligned text
+func (m Model) copyAligned() tea.Cmd {
+	columns, rows := m.shownResult()
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	cells := make([][]string, len(rows))
+	for r, row := range rows {
+		cells[r] = make([]string, len(columns))
+		for i, col := range columns {
+			cells[r][i] = StripANSI(formatCell(row[i], col.Type))
+		}
+	}
+	return copyToClipboard(AlignedText(header, cells, m.table.MaxColumnWidth()), "Aligned table")
+}
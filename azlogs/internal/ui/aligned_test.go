@@ -0,0 +1,19 @@
+package ui
+
+import "testing"
+
+func TestAlignedText(t *testing.T) {
+	header := []string{"Name", "City", "Count"}
+	rows := [][]string{
+		{"web-1", "東京", "3"},
+		{"a-much-longer-name", "Oslo\nNorway"},
+	}
+
+	expected := "Name        City        Count\n" +
+		"----------  ----------  -----\n" +
+		"web-1       東京        3\n" +
+		"a-much-lo…  Oslo Norw…  \n"
+	if got := AlignedText(header, rows, 10); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
@@ -2,10 +2,14 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -24,6 +28,11 @@ const (
 	ViewWorkspace
 	ViewRowDetail
 	ViewTemplates
+	ViewTimeRange
+	ViewBookmarks
+	ViewSchema
+	ViewError
+	ViewCatalog
 )
 
 // Model is the main application model
@@ -35,12 +44,15 @@ type Model struct {
 	workspaceInput textinput.Model
 
 	// Azure clients
-	client       *azure.LogAnalyticsClient
+	client       azure.QueryClient
 	openaiClient *azure.OpenAIClient
+	openaiErr    error // Why AI features are unavailable, if they are
 	auth         *azure.Authenticator
 	authMethod   azure.AuthMethod
 	config       *azure.Config
 	history      *azure.History
+	cache        *azure.ResultCache
+	slowLog      *azure.SlowQueryLog
 
 	// State
 	currentView      View
@@ -50,46 +62,125 @@ type Model struct {
 	lastQuery        string
 	lastError        string
 	lastDuration     time.Duration
+	lastExecTime     time.Duration // Server-reported execution time, 0 if unknown
+	resultAsOf       time.Time     // When the displayed data was fetched
+	partialWarning   string        // Why the last result is incomplete, if it is
+	truncatedAt      int           // Row cap the last result was truncated at, 0 if not
+	freshnessTicking bool          // A freshnessTickMsg is pending
+	resultCached     bool          // Last result was served from the cache
+	cacheAge         time.Duration // Age of the cached result
 	rowCount         int
 	styles           *Styles
 	connected        bool
 	connecting       bool
+	reconnecting     bool   // Connection was lost and is being re-established
+	healthTicking    bool   // A healthTickMsg is pending
+	autosaveFailed   bool   // The last auto-save failed, already reported
+	savedConfig      []byte // Config and templates as last saved, so unchanged
+	savedTemplates   []byte // ones aren't rewritten on every auto-save
 	workspaceID      string
 	historyIndex     int
 	historyList      []azure.HistoryEntry
+	confirmClear     bool // Waiting for confirmation to clear history
+	confirmScan      bool // Waiting for confirmation to run an unbounded query
+	confirmScanForce bool // The unbounded query should bypass the cache
+	confirmReplace   bool // Waiting for confirmation to replace the query with the AI suggestion
 	detailScrollPos  int
-	hideEmptyFields  bool // Hide empty/null fields in row detail view
+	helpView         ScrollView
+	errorView        ScrollView         // Full text of the last error
+	hideEmptyFields  bool               // Hide empty/null fields in row detail view
+	detailSelected   map[int]bool       // Columns picked for a where clause in the row detail view
+	fetchingFullRow  bool               // A row's full record is being fetched
+	fullRecord       *fullRecord        // Fetched record shown in row detail
+	queryStarted     time.Time          // When the running query was sent
+	queryProgress    *azure.Progress    // Bytes received of the running query's response
+	resultColumns    []azure.Column     // Columns of the displayed result
+	resultRows       [][]interface{}    // Raw values of the displayed result
+	notice           string             // Confirmation shown in the status bar until the next key
+	reference        string             // KQL reference shown below the editor until the next key
+	portalURL        string             // Portal link shown when no browser opens, until the next key
+	baseline         *resultBaseline    // Result the next run of the same query is compared with
+	diff             *diffSummary       // Differences of the displayed result from the baseline
+	showChart        bool               // Show the chart above the results
+	chartX, chartY   int                // Columns charted as x axis and value, -1 if none
+	chartKind        chartKind          // How the chart is drawn
+	chartHint        string             // Chart type from the query's render operator, empty if none
+	result           *azure.QueryResult // Displayed result, kept for saving snapshots
+	snapshot         *azure.Snapshot    // Snapshot the displayed result was loaded from, nil if live
 
 	// Autocomplete state
 	suggestion            string
 	suggestLoading        bool
 	suggestionDebounceTag int
+	pastedAt              time.Time          // When text was last pasted into the editor
+	pasted                bool               // Suggestions are refreshed once the paste settles
+	cancelSuggestion      context.CancelFunc // Cancels the in-flight AI suggestion request
 	availableTables       []string
 	schemaCache           map[string][]azure.Column // Cache of table schemas
+	schemaFetching        map[string]bool           // Tables whose schema is being fetched
+	schemaFailures        map[string]fetchFailure   // Tables whose schema fetch recently failed
+	tablesFailure         fetchFailure              // The last failed table list fetch
+	ingestionRates        map[string]float64        // GB per day each table ingests, for the cost hint
 
 	// Local autocomplete
 	autocompleteEngine *AutocompleteEngine
+	columnUsage        *azure.ColumnUsage
+	recentTables       *azure.RecentTables
 	suggestionPopup    *SuggestionPopup
 
+	// Keybindings
+	keys *KeyMap
+
 	// Templates state
 	templates      *azure.Templates
 	templateList   []azure.TemplateEntry
 	templateIndex  int
 	templateInput  textinput.Model
 	savingTemplate bool
+
+	// Query catalog state
+	catalog      []azure.CatalogQuery
+	catalogIndex int
+
+	// Bookmarks state
+	bookmarks      *azure.Bookmarks
+	bookmarkList   []azure.BookmarkEntry
+	bookmarkIndex  int
+	bookmarkInput  textinput.Model
+	addingBookmark bool
+
+	// Time range state
+	timeRangeIndex   int
+	timeRangeInput   textinput.Model
+	editingTimeRange bool
+
+	// Schema explorer state
+	schemaFilter textinput.Model
+	schemaTables []string // Available tables matching the filter
+	schemaIndex  int
+
+	startupQuery string // Run once connected, then cleared
+
+	// Query variables, referenced as {{name}} in queries
+	variables      map[string]string // Comma-separated KQL literals by name
+	variableInput  textinput.Model
+	namingVariable bool
 }
 
 // Messages
 type queryResultMsg struct {
-	result *azure.QueryResult
-	err    error
+	result   *azure.QueryResult
+	err      error
+	cached   bool
+	cacheAge time.Duration
 }
 
 type connectMsg struct {
 	err          error
 	auth         *azure.Authenticator
-	client       *azure.LogAnalyticsClient
+	client       azure.QueryClient
 	openaiClient *azure.OpenAIClient
+	openaiErr    error // Why AI features are unavailable, if they are
 }
 
 type suggestionMsg struct {
@@ -102,26 +193,60 @@ type debounceMsg struct {
 	tag int
 }
 
+// freshnessTickMsg refreshes the data age shown in the status bar
+type freshnessTickMsg struct{}
+
+// tablesMsg and schemaMsg carry the workspace they were fetched for, so
+// results landing after a workspace switch can be dropped
 type tablesMsg struct {
-	tables []string
-	err    error
+	workspace string
+	tables    []string
+	err       error
 }
 
 type schemaMsg struct {
+	workspace string
 	tableName string
 	columns   []azure.Column
 	err       error
 }
 
-// waitForDebounce waits for a short period before triggering autocomplete
-func waitForDebounce(tag int) tea.Cmd {
-	return tea.Tick(500*time.Millisecond, func(_ time.Time) tea.Msg {
+// freshnessTick schedules the next data age refresh
+func freshnessTick() tea.Cmd {
+	return tea.Tick(time.Second, func(_ time.Time) tea.Msg {
+		return freshnessTickMsg{}
+	})
+}
+
+// scheduleFreshness starts the data age refresh while a result's age is
+// shown and no refresh is already pending
+func (m *Model) scheduleFreshness() tea.Cmd {
+	if m.freshnessTicking || m.loading || m.resultAsOf.IsZero() {
+		return nil
+	}
+	m.freshnessTicking = true
+	return freshnessTick()
+}
+
+// waitForDebounce waits for a typing pause before triggering AI autocomplete
+func waitForDebounce(tag int, d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(_ time.Time) tea.Msg {
 		return debounceMsg{tag: tag}
 	})
 }
 
+// pasteSettle is how long after a paste further input is taken to be part
+// of it, as a multi-line paste arrives as a burst of messages
+const pasteSettle = 50 * time.Millisecond
+
+// isPaste reports whether a key message carries several characters at once,
+// which only happens when text is pasted rather than typed
+func isPaste(msg tea.KeyMsg) bool {
+	return msg.Type == tea.KeyRunes && len(msg.Runes) > 1
+}
+
 // NewModel creates a new application model
-func NewModel(workspaceID string, authMethod azure.AuthMethod) Model {
+func NewModel(workspaceID string, authMethod azure.AuthMethod, config *azure.Config) Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(ColorPrimary)
@@ -135,38 +260,131 @@ func NewModel(workspaceID string, authMethod azure.AuthMethod) Model {
 		wi.SetValue(workspaceID)
 	}
 
-	config := azure.NewConfig()
-	config.Load()
-
+	// A corrupt state file is moved aside and started fresh; say so once the
+	// UI is up
 	history := azure.NewHistory(1000)
-	history.Load()
+	loadErrs := []error{history.Load()}
 
 	templates := azure.NewTemplates()
-	templates.Load()
+	loadErrs = append(loadErrs, templates.Load())
+
+	bookmarks := azure.NewBookmarks()
+	loadErrs = append(loadErrs, bookmarks.Load())
+
+	columnUsage := azure.NewColumnUsage()
+	loadErrs = append(loadErrs, columnUsage.Load())
+	autocompleteEngine := NewAutocompleteEngine()
+	autocompleteEngine.SetUsage(columnUsage)
+
+	recentTables := azure.NewRecentTables()
+	loadErrs = append(loadErrs, recentTables.Load())
+
+	bi := textinput.New()
+	bi.Placeholder = "What is this query for?"
+	bi.CharLimit = 200
+	bi.Width = 60
 
 	ti := textinput.New()
 	ti.Placeholder = "Enter template name"
 	ti.CharLimit = 100
 	ti.Width = 40
 
+	sfi := textinput.New()
+	sfi.Placeholder = "Type to filter tables"
+	sfi.CharLimit = 100
+	sfi.Width = 40
+
+	tri := textinput.New()
+	tri.Placeholder = "e.g. 90m"
+	tri.CharLimit = 20
+	tri.Width = 20
+
+	vi := textinput.New()
+	vi.Placeholder = "name"
+	vi.CharLimit = 50
+	vi.Width = 20
+
+	// The startup query runs on the first connection, making the first
+	// screen a dashboard of whatever the config asks for
+	var startupQuery string
+	if !config.NoStartupQuery {
+		startupQuery = strings.TrimSpace(config.StartupQuery)
+	}
+
+	keys := DefaultKeyMap()
+	var startupErrors []string
+	if err := errors.Join(loadErrs...); err != nil {
+		azure.Logger().Warn("loading state failed", "error", err)
+		startupErrors = append(startupErrors, err.Error())
+	}
+	if err := keys.Apply(config.KeyBindings); err != nil {
+		startupErrors = append(startupErrors, fmt.Sprintf("Invalid key bindings in config: %v", err))
+	}
+	if _, err := config.AIInstructions(); err != nil {
+		startupErrors = append(startupErrors, err.Error())
+	}
+
+	cacheTTL := time.Duration(config.CacheTTL) * time.Second
+	if config.NoCache {
+		cacheTTL = 0
+	}
+	cache := azure.NewResultCache(cacheTTL)
+	if config.CacheToDisk {
+		cache.EnableDisk(filepath.Join(azure.ConfigDir(), "cache"))
+	}
+
+	helpView := NewScrollView()
+	helpView.SetKeyMap(keys)
+	helpView.SetContent(buildHelp(keys, ViewQuery))
+
+	errorView := NewScrollView()
+	errorView.SetKeyMap(keys)
+
+	editor := NewQueryEditor()
+	editor.SetKeyMap(keys)
+
+	table := NewResultsTable()
+	table.SetKeyMap(keys)
+	if config.MaxColumnWidth > 0 {
+		table.SetMaxColumnWidth(config.MaxColumnWidth)
+	}
+
 	return Model{
-		editor:             NewQueryEditor(),
-		table:              NewResultsTable(),
+		editor:             editor,
+		table:              table,
 		spinner:            s,
 		workspaceInput:     wi,
 		config:             config,
 		history:            history,
+		cache:              cache,
+		slowLog:            azure.NewSlowQueryLog(),
 		authMethod:         authMethod,
 		currentView:        ViewQuery,
 		styles:             DefaultStyles(),
 		workspaceID:        workspaceID,
 		connecting:         workspaceID != "", // Start connecting if workspace provided
 		schemaCache:        make(map[string][]azure.Column),
+		schemaFetching:     make(map[string]bool),
+		schemaFailures:     make(map[string]fetchFailure),
+		schemaFilter:       sfi,
 		hideEmptyFields:    true, // Hide empty fields by default
-		autocompleteEngine: NewAutocompleteEngine(),
+		autocompleteEngine: autocompleteEngine,
+		columnUsage:        columnUsage,
+		recentTables:       recentTables,
 		suggestionPopup:    NewSuggestionPopup(),
+		keys:               keys,
+		helpView:           helpView,
+		errorView:          errorView,
+		lastError:          strings.Join(startupErrors, "\n"),
 		templates:          templates,
+		savedConfig:        stateJSON(config),
+		savedTemplates:     stateJSON(templates),
 		templateInput:      ti,
+		timeRangeInput:     tri,
+		bookmarks:          bookmarks,
+		bookmarkInput:      bi,
+		variableInput:      vi,
+		startupQuery:       startupQuery,
 	}
 }
 
@@ -177,6 +395,10 @@ func (m Model) Init() tea.Cmd {
 		m.spinner.Tick,
 	}
 
+	if tick := autosaveTick(m.config.AutosaveInterval()); tick != nil {
+		cmds = append(cmds, tick)
+	}
+
 	// Auto-connect if workspace is provided
 	if m.workspaceID != "" {
 		cmds = append(cmds, m.Connect(m.authMethod))
@@ -188,19 +410,28 @@ func (m Model) Init() tea.Cmd {
 // Connect connects to Azure
 func (m *Model) Connect(authMethod azure.AuthMethod) tea.Cmd {
 	workspaceID := m.workspaceID
+	config := m.config
 	return func() tea.Msg {
-		auth, err := azure.NewAuthenticator(authMethod)
+		auth, err := azure.NewCloudAuthenticator(authMethod, config.AzureCloud())
 		if err != nil {
 			return connectMsg{err: err, auth: nil, client: nil, openaiClient: nil}
 		}
 
-		client, err := azure.NewLogAnalyticsClient(auth.GetCredential(), workspaceID)
+		client, err := azure.NewQueryClient(auth.GetCredential(), workspaceID, config)
 		if err != nil {
 			return connectMsg{err: err, auth: nil, client: nil, openaiClient: nil}
 		}
 
 		// Create OpenAI client for autocomplete
-		openaiClient := azure.NewOpenAIClientWithDefaults(auth.GetCredential())
+		endpoint, err := config.AIEndpoint()
+		if err != nil {
+			return connectMsg{auth: auth, client: client, openaiErr: err}
+		}
+		openaiClient := azure.NewOpenAIClient(auth.GetCredential(), endpoint, config.OpenAIDeployment)
+		openaiClient.SetCloud(auth.Cloud())
+		openaiClient.SetTimeouts(config.SuggestTimeout(), config.AITimeout())
+		instructions, _ := config.AIInstructions() // Reported at startup if cut
+		openaiClient.SetInstructions(instructions)
 
 		return connectMsg{err: nil, auth: auth, client: client, openaiClient: openaiClient}
 	}
@@ -212,41 +443,73 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		m.editor.SetSize(msg.Width-4, 8)
-		m.table.SetSize(msg.Width-4, msg.Height-20)
+		m.resize(msg.Width, msg.Height)
 		return m, nil
 
 	case tea.KeyMsg:
+		m.notice = ""
+		m.reference = ""
+		m.portalURL = ""
+
 		// Global keys
-		switch msg.String() {
-		case "ctrl+c", "ctrl+q":
+		switch {
+		case key.Matches(msg, m.keys.Quit):
 			m.saveState()
 			return m, tea.Quit
 
-		case "f1":
+		case key.Matches(msg, m.keys.Help):
+			m.helpView.SetContent(buildHelp(m.keys, m.currentView))
 			m.currentView = ViewHelp
 			return m, nil
 
-		case "f2":
-			m.historyList = m.history.GetRecent(50)
+		case key.Matches(msg, m.keys.History):
+			m.loadHistoryList()
 			m.historyIndex = 0
+			m.confirmClear = false
 			m.currentView = ViewHistory
 			return m, nil
 
-		case "f3":
+		case key.Matches(msg, m.keys.Workspace):
 			m.currentView = ViewWorkspace
 			m.workspaceInput.Focus()
 			return m, nil
 
-		case "f4":
+		case key.Matches(msg, m.keys.Templates):
 			m.templateList = m.templates.GetAll()
 			m.templateIndex = 0
 			m.currentView = ViewTemplates
 			return m, nil
 
-		case "esc":
+		case key.Matches(msg, m.keys.TimeRange):
+			m.openTimeRangeView()
+			return m, nil
+
+		case key.Matches(msg, m.keys.Bookmarks):
+			m.openBookmarksView()
+			return m, nil
+
+		case key.Matches(msg, m.keys.Catalog):
+			m.openCatalogView()
+			return m, nil
+
+		case key.Matches(msg, m.keys.SchemaExplorer):
+			return m, m.openSchemaView()
+
+		case key.Matches(msg, m.keys.Bookmark):
+			m.startBookmark()
+			return m, nil
+
+		case key.Matches(msg, m.keys.ErrorDetail):
+			m.openErrorView()
+			return m, nil
+
+		case key.Matches(msg, m.keys.OpenPortal):
+			return m, m.openInPortal()
+
+		case key.Matches(msg, m.keys.Back):
+			m.editingTimeRange = false
+			m.addingBookmark = false
+			m.namingVariable = false
 			if m.currentView != ViewQuery {
 				m.currentView = ViewQuery
 				m.editor.Focus()
@@ -271,8 +534,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateRowDetailView(msg)
 		case ViewTemplates:
 			return m.updateTemplatesView(msg)
+		case ViewTimeRange:
+			return m.updateTimeRangeView(msg)
+		case ViewBookmarks:
+			return m.updateBookmarksView(msg)
+		case ViewCatalog:
+			return m.updateCatalogView(msg)
+		case ViewSchema:
+			return m.updateSchemaView(msg)
+		case ViewError:
+			return m.updateErrorView(msg)
 		}
 
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -282,33 +558,100 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.loading = false
 		if msg.err != nil {
 			m.lastError = msg.err.Error()
+			if hint := queryErrorHint(msg.err); hint != "" {
+				m.lastError += "\n" + hint
+			}
 			m.addToHistory(false, msg.err.Error())
+			// An expired session shows up as an auth error on the next query
+			if errors.Is(msg.err, azure.ErrUnauthorized) && m.connected {
+				return m, m.startReconnect()
+			}
 		} else {
 			m.lastError = ""
 			m.processResults(msg.result)
+			m.snapshot = nil
+			m.resultCached = msg.cached
+			m.cacheAge = msg.cacheAge
 			m.addToHistory(true, "")
+			if !msg.cached {
+				m.logSlowQuery()
+			}
+		}
+		return m, m.scheduleFreshness()
+
+	case freshnessTickMsg:
+		// Stop ticking while no data age is shown; the next result restarts it
+		m.freshnessTicking = false
+		return m, m.scheduleFreshness()
+
+	case pasteMsg:
+		return m.updatePaste(msg)
+
+	case portalMsg:
+		return m.updatePortal(msg)
+
+	case fullRowMsg:
+		return m.updateFullRow(msg)
+
+	case copiedMsg:
+		if msg.err != nil {
+			m.lastError = clipboardError(msg.err)
+		} else {
+			m.notice = msg.what + " copied to clipboard"
 		}
 		return m, nil
 
+	case autosaveTickMsg:
+		m.autosave()
+		return m, autosaveTick(m.config.AutosaveInterval())
+
+	case healthTickMsg, healthMsg:
+		return m.updateHealth(msg)
+
 	case connectMsg:
 		m.connecting = false
-		if msg.err != nil {
+		if msg.err != nil && m.reconnecting {
+			m.lastError = fmt.Sprintf("Reconnect failed, retrying: %v", msg.err)
+			azure.Logger().Warn("reconnect failed", "error", msg.err)
+			return m, nil
+		} else if msg.err != nil {
 			m.lastError = fmt.Sprintf("Connection failed: %v", msg.err)
+			azure.Logger().Error("connection failed", "error", msg.err)
 			m.connected = false
 		} else {
 			m.auth = msg.auth
 			m.client = msg.client
 			m.openaiClient = msg.openaiClient
+			m.openaiErr = msg.openaiErr
+			if msg.openaiErr != nil {
+				azure.Logger().Info("AI features unavailable", "reason", msg.openaiErr)
+			}
+			if m.reconnecting {
+				// Only trust the new connection once its credential works
+				return m, m.checkHealth()
+			}
 			m.connected = true
 			m.lastError = ""
 			// Load available tables for autocomplete context
-			return m, m.loadAvailableTables()
+			cmds := []tea.Cmd{m.loadAvailableTables(), m.loadIngestionRates()}
+			if !m.healthTicking {
+				m.healthTicking = true
+				cmds = append(cmds, healthTick())
+			}
+			if m.startupQuery != "" {
+				m.editor.SetValue(m.startupQuery)
+				m.startupQuery = ""
+				model, cmd := m.runQuery(false)
+				return model, tea.Batch(append(cmds, cmd)...)
+			}
+			return m, tea.Batch(cmds...)
 		}
 		return m, nil
 
 	case suggestionMsg:
 		if msg.tag == m.suggestionDebounceTag {
 			m.suggestLoading = false
+			m.cancelSuggestion = nil
 			if msg.err != nil {
 				// Silently ignore suggestion errors
 				m.suggestion = ""
@@ -320,24 +663,56 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case debounceMsg:
 		if msg.tag == m.suggestionDebounceTag {
-			if !m.connected || m.openaiClient == nil {
-				return m, nil
+			if m.pasted {
+				m.pasted = false
+				m.updateLocalSuggestions()
+			}
+			// Load columns for tables the query uses that aren't cached yet
+			schemaCmd := m.fetchReferencedSchemas()
+			if m.config.ManualAISuggest || !m.connected || m.openaiClient == nil {
+				return m, schemaCmd
 			}
 			m.suggestLoading = true
-			return m, m.getSuggestion(m.suggestionDebounceTag)
+			return m, tea.Batch(schemaCmd, m.getSuggestion(m.suggestionDebounceTag))
 		}
 		return m, nil
 
 	case tablesMsg:
-		if msg.err == nil {
+		if msg.err != nil && msg.workspace == m.workspaceID {
+			m.tablesFailure = fetchFailure{err: msg.err, at: time.Now()}
+		}
+		if msg.err == nil && msg.workspace == m.workspaceID {
+			m.tablesFailure = fetchFailure{}
 			m.availableTables = msg.tables
 			m.autocompleteEngine.SetTables(msg.tables)
+			if m.currentView == ViewSchema {
+				m.filterSchemaTables()
+				return m, tea.Batch(m.fetchInitialSchemas(msg.tables), m.fetchSelectedSchema())
+			}
 			return m, m.fetchInitialSchemas(msg.tables)
 		}
 		return m, nil
 
+	case ingestionMsg:
+		if msg.workspace != m.workspaceID {
+			return m, nil // Fetched for the previous workspace
+		}
+		if msg.err != nil {
+			azure.Logger().Debug("no ingestion stats for the cost hint", "error", msg.err)
+		}
+		m.ingestionRates = msg.rates
+		return m, nil
+
 	case schemaMsg:
+		if msg.workspace != m.workspaceID {
+			return m, nil // Fetched for the previous workspace
+		}
+		delete(m.schemaFetching, msg.tableName)
+		if msg.err != nil && msg.tableName != "" {
+			m.schemaFailures[msg.tableName] = fetchFailure{err: msg.err, at: time.Now()}
+		}
 		if msg.err == nil && msg.tableName != "" {
+			delete(m.schemaFailures, msg.tableName)
 			if m.schemaCache == nil {
 				m.schemaCache = make(map[string][]azure.Column)
 			}
@@ -351,41 +726,62 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) updateQueryView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Any key other than confirm cancels a pending unbounded query
+	if m.confirmScan {
+		m.confirmScan = false
+		if key.Matches(msg, m.keys.Confirm) {
+			return m.executeQuery(m.confirmScanForce)
+		}
+		return m, nil
+	}
+
+	// Any key other than confirm keeps the query and the AI suggestion
+	if m.confirmReplace {
+		m.confirmReplace = false
+		if key.Matches(msg, m.keys.Confirm) {
+			m.editor.SetValue(m.suggestion)
+			m.suggestion = ""
+			m.notice = "Query replaced (" + m.keys.Undo.Help().Key + " to undo)"
+		}
+		return m, nil
+	}
+
 	// Handle popup navigation first if popup is visible
 	if m.suggestionPopup.IsVisible() {
-		switch msg.String() {
-		case "up", "ctrl+p":
+		switch {
+		case key.Matches(msg, m.keys.SuggestionPrev):
 			m.suggestionPopup.Previous()
 			return m, nil
-		case "down", "ctrl+n":
+		case key.Matches(msg, m.keys.SuggestionNext):
 			m.suggestionPopup.Next()
 			return m, nil
-		case "tab", "enter":
+		case key.Matches(msg, m.keys.AcceptSuggestion):
 			// Accept selected suggestion
-			if selected := m.suggestionPopup.GetSelectedText(); selected != "" {
-				m.acceptLocalSuggestion(selected)
+			if selected := m.suggestionPopup.Selected(); selected != nil {
+				m.acceptLocalSuggestion(selected.Text)
+				if selected.Type == "column" {
+					m.recordColumnUsage(selected.Text)
+				}
 			}
 			m.suggestionPopup.Hide()
 			return m, nil
-		case "esc":
+		case key.Matches(msg, m.keys.Back):
 			m.suggestionPopup.Hide()
 			return m, nil
 		}
 	}
 
-	switch msg.String() {
-	case "ctrl+enter", "f5":
-		if !m.connected {
-			m.lastError = "Not connected. Press F3 to set workspace."
-			return m, nil
-		}
-		m.suggestion = "" // Clear any pending suggestion
-		m.suggestionPopup.Hide()
-		return m.executeQuery()
+	switch {
+	case key.Matches(msg, m.keys.Execute, m.keys.ForceExecute):
+		return m.runQuery(key.Matches(msg, m.keys.ForceExecute))
 
-	case "tab":
+	case key.Matches(msg, m.keys.SwitchPane):
 		// Accept AI suggestion if available, otherwise switch to results
 		if m.suggestion != "" {
+			if replacesQuery(m.editor.Value(), m.suggestion) {
+				m.confirmReplace = true
+				return m, nil
+			}
 			m.editor.SetValue(m.suggestion)
 			m.suggestion = ""
 			return m, nil
@@ -395,7 +791,20 @@ func (m Model) updateQueryView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.table.Focus()
 		return m, nil
 
-	case "ctrl+@", "ctrl+ ", "alt+s": // Ctrl+Space or Alt+S to manually trigger AI autocomplete
+	case key.Matches(msg, m.keys.ShowReference):
+		m.suggestionPopup.Hide()
+		m.showReference()
+		return m, nil
+
+	case key.Matches(msg, m.keys.RecentTables):
+		m.showRecentTables()
+		return m, nil
+
+	case key.Matches(msg, m.keys.AISuggest): // Manually trigger AI autocomplete
+		if m.connected && m.openaiErr != nil {
+			m.lastError = "AI suggestions are unavailable: " + m.openaiErr.Error()
+			return m, nil
+		}
 		if !m.connected || m.openaiClient == nil {
 			m.lastError = "Connect to workspace first for AI suggestions"
 			return m, nil
@@ -407,13 +816,28 @@ func (m Model) updateQueryView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.suggestionPopup.Hide()
 		return m, m.getSuggestion(tag)
 
-	case "ctrl+l":
+	case key.Matches(msg, m.keys.ClearEditor):
 		m.editor.Reset()
 		m.suggestion = ""
 		m.suggestionPopup.Hide()
 		return m, nil
 
-	case "ctrl+s", "f6":
+	case key.Matches(msg, m.keys.ToggleComment):
+		m.editor.ToggleComment()
+		m.suggestion = ""
+		m.suggestionPopup.Hide()
+		return m, nil
+
+	case key.Matches(msg, m.keys.FormatQuery):
+		m.editor.SetValue(FormatKQL(m.editor.Value()))
+		m.suggestion = ""
+		m.suggestionPopup.Hide()
+		return m, nil
+
+	case key.Matches(msg, m.keys.PasteQuery, m.keys.PasteAndRun):
+		return m, pasteQuery(key.Matches(msg, m.keys.PasteAndRun))
+
+	case key.Matches(msg, m.keys.SaveTemplate):
 		// Save current query as template
 		if m.editor.Value() != "" {
 			m.savingTemplate = true
@@ -422,20 +846,26 @@ func (m Model) updateQueryView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-	case "esc":
-		// Clear AI suggestion if present
+	case key.Matches(msg, m.keys.Back):
+		// Clear AI suggestion if present, or stop waiting for one
 		if m.suggestion != "" {
 			m.suggestion = ""
 			return m, nil
 		}
+		if m.suggestLoading {
+			m.cancelPendingSuggestion()
+			m.suggestionDebounceTag++
+			m.suggestLoading = false
+			return m, nil
+		}
 
-	case "ctrl+up":
+	case key.Matches(msg, m.keys.HistoryPrev):
 		// Navigate history
 		m.suggestion = "" // Clear suggestion when navigating history
 		m.suggestionPopup.Hide()
 		return m.navigateHistory(-1)
 
-	case "ctrl+down":
+	case key.Matches(msg, m.keys.HistoryNext):
 		// Navigate history
 		m.suggestion = "" // Clear suggestion when navigating history
 		m.suggestionPopup.Hide()
@@ -445,49 +875,319 @@ func (m Model) updateQueryView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	m.editor, cmd = m.editor.Update(msg)
 
+	// Pasted text skips per-keystroke autocomplete; suggestions are updated
+	// once the paste settles
+	if isPaste(msg) || time.Since(m.pastedAt) < pasteSettle {
+		if isPaste(msg) {
+			m.pastedAt = time.Now()
+		}
+		m.pasted = true
+		m.suggestion = ""
+		m.suggestionPopup.Hide()
+		m.suggestionDebounceTag++
+		m.cancelPendingSuggestion()
+		return m, tea.Batch(cmd, waitForDebounce(m.suggestionDebounceTag, m.config.SuggestDebounce()))
+	}
+
 	// Trigger local autocomplete on typing
 	if len(msg.String()) == 1 || msg.String() == "backspace" || msg.String() == "delete" {
 		m.suggestion = ""
 		m.suggestionDebounceTag++
+		m.cancelPendingSuggestion()
 
 		// Update local autocomplete immediately
 		m.updateLocalSuggestions()
 
-		return m, tea.Batch(cmd, waitForDebounce(m.suggestionDebounceTag))
+		return m, tea.Batch(cmd, waitForDebounce(m.suggestionDebounceTag, m.config.SuggestDebounce()))
 	}
 
 	return m, cmd
 }
 
 func (m Model) updateResultsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "tab":
+	if m.namingVariable {
+		return m.updateVariablePrompt(msg)
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.SwitchPane):
 		m.currentView = ViewQuery
 		m.table.Blur()
 		m.editor.Focus()
 		return m, nil
 
-	case "enter":
+	case key.Matches(msg, m.keys.Baseline):
+		m.toggleBaseline()
+		return m, nil
+
+	case key.Matches(msg, m.keys.ToggleChart):
+		m.toggleChart()
+		return m, nil
+
+	case key.Matches(msg, m.keys.CopyColumn):
+		return m, m.copyCurrentColumn()
+
+	case key.Matches(msg, m.keys.CopyCell):
+		return m, m.copyCurrentCell()
+
+	case key.Matches(msg, m.keys.SaveVariable):
+		m.startVariable()
+		return m, nil
+
+	case key.Matches(msg, m.keys.CopyMarkdown):
+		if len(m.resultColumns) == 0 {
+			return m, nil
+		}
+		return m, m.copyMarkdown()
+
+	case key.Matches(msg, m.keys.CopyText):
+		if len(m.resultColumns) == 0 {
+			return m, nil
+		}
+		return m, m.copyAligned()
+
+	case key.Matches(msg, m.keys.InsertIn):
+		clause, capped := m.currentColumnIn()
+		if clause == "" {
+			return m, nil
+		}
+		m.appendToQuery("| " + clause)
+		if capped != "" {
+			m.notice = "Added " + capped
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.CopyIn):
+		clause, capped := m.currentColumnIn()
+		if clause == "" {
+			return m, nil
+		}
+		what := "In clause"
+		if capped != "" {
+			what += " (" + capped + ")"
+		}
+		return m, copyToClipboard("| "+clause, what)
+
+	case key.Matches(msg, m.keys.SaveReport):
+		m.saveReport()
+		return m, nil
+
+	case key.Matches(msg, m.keys.SaveSnapshot):
+		m.saveSnapshot()
+		return m, nil
+
+	case key.Matches(msg, m.keys.ProjectColumns):
+		clause := m.shownProjectClause()
+		if clause == "" {
+			return m, nil
+		}
+		m.appendToQuery(clause)
+		return m, nil
+
+	case key.Matches(msg, m.keys.ProjectAway):
+		m.applyHiddenColumns()
+		return m, nil
+
+	case key.Matches(msg, m.keys.CopyProject):
+		clause := m.shownProjectClause()
+		if clause == "" {
+			return m, nil
+		}
+		return m, copyToClipboard(clause, "Project clause")
+
+	case m.chartShown() && key.Matches(msg, m.keys.ChartX):
+		xs, _ := chartAxes(m.chartKind, m.table.GetColumnTypes())
+		m.cycleChartColumn(&m.chartX, xs)
+		return m, nil
+
+	case m.chartShown() && key.Matches(msg, m.keys.ChartY):
+		_, ys := chartColumns(m.table.GetColumnTypes())
+		m.cycleChartColumn(&m.chartY, ys)
+		return m, nil
+
+	case key.Matches(msg, m.keys.Select):
 		// Open row detail view
 		if m.table.RowCount() > 0 {
 			m.detailScrollPos = 0
+			m.fullRecord = nil
 			m.currentView = ViewRowDetail
 		}
 		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.table, cmd = m.table.Update(msg)
+	if key.Matches(msg, m.keys.WidenColumn, m.keys.NarrowColumn) {
+		m.saveColumnWidths()
+	}
+	return m, cmd
+}
+
+// saveColumnWidths remembers the column widths set for the table the
+// query reads from, to lay out its next results the same way
+func (m *Model) saveColumnWidths() {
+	table := sourceTable(m.lastQuery)
+	if table == "" {
+		return
+	}
+	if m.config.ColumnWidths == nil {
+		m.config.ColumnWidths = make(azure.TableWidths)
+	}
+	m.config.ColumnWidths[table] = m.table.ColumnWidths()
+}
+
+// appendToQuery adds a clause on a new line at the end of the query and
+// switches to the editor
+func (m *Model) appendToQuery(clause string) {
+	query := strings.TrimRight(m.editor.Value(), " \n")
+	if query != "" {
+		query += "\n"
+	}
+	m.editor.SetValue(query + clause)
+	m.currentView = ViewQuery
+	m.table.Blur()
+	m.editor.Focus()
+}
+
+// updateMouse routes mouse events over the results table to the table,
+// translating screen coordinates into table-relative ones
+func (m Model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.currentView == ViewHelp {
+		var cmd tea.Cmd
+		m.helpView, cmd = m.helpView.Update(msg)
+		return m, cmd
+	}
+	if m.currentView != ViewQuery && m.currentView != ViewResults {
+		return m, nil
+	}
+	if m.table.RowCount() == 0 {
+		return m, nil
+	}
 
-	case "y":
-		// Copy selected row (would need clipboard integration)
+	msg.Y -= m.tableTop()
+	if msg.Y < 0 {
 		return m, nil
 	}
 
+	if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
+		row := m.table.RowAt(msg.Y)
+		if row < 0 {
+			return m, nil
+		}
+
+		// Clicking the already-selected row opens its details
+		if m.currentView == ViewResults && row == m.table.GetSelectedRowIndex() {
+			m.detailScrollPos = 0
+			m.fullRecord = nil
+			m.currentView = ViewRowDetail
+			return m, nil
+		}
+	}
+
+	// Clicking or scrolling over the table moves focus to it
+	if m.currentView == ViewQuery && msg.Action == tea.MouseActionPress {
+		m.currentView = ViewResults
+		m.suggestionPopup.Hide()
+		m.editor.Blur()
+		m.table.Focus()
+	}
+
 	var cmd tea.Cmd
 	m.table, cmd = m.table.Update(msg)
 	return m, cmd
 }
 
+// tableTop returns the screen line on which the results table starts
+func (m Model) tableTop() int {
+	above := m.renderHeader() + "\n" +
+		m.renderStatusBar() + "\n\n" +
+		m.renderEditorSection() +
+		m.renderResultsHeader()
+	return strings.Count(above, "\n")
+}
+
+// renderResultsHeader renders the title above the results table, followed by
+// a warning when the results are incomplete
+func (m Model) renderResultsHeader() string {
+	header := m.styles.Prompt.Render("Results") + "\n"
+	if m.namingVariable {
+		header += m.renderVariablePrompt() + "\n"
+	}
+	if m.snapshot != nil {
+		header += m.renderSnapshotBanner() + "\n"
+	}
+	if m.partialWarning != "" {
+		header += m.renderPartialWarning() + "\n"
+	}
+	if m.truncatedAt > 0 {
+		header += m.styles.Warning.Bold(true).Render(fmt.Sprintf(
+			"⚠ Results truncated at %d rows (max_result_rows). Narrow the query or raise the limit.", m.truncatedAt)) + "\n"
+	}
+	if m.chartShown() {
+		header += m.renderChart()
+	}
+	if m.diff != nil {
+		keyedOn := "whole rows"
+		if name := m.keyColumnName(); name != "" {
+			keyedOn = name
+		}
+		header += m.styles.Muted.Render(fmt.Sprintf("Compared with baseline from %s, keyed on %s: %s",
+			m.baseline.at.Format("15:04:05"), keyedOn, m.diff)) + "\n"
+	} else if m.baseline != nil {
+		header += m.styles.Muted.Render(fmt.Sprintf("Diff baseline from %s set, rerun its query to compare",
+			m.baseline.at.Format("15:04:05"))) + "\n"
+	}
+	return header
+}
+
+// renderPartialWarning renders the banner shown for partial results
+func (m Model) renderPartialWarning() string {
+	style := m.styles.Warning.Bold(true)
+	if m.width > 10 {
+		style = style.Width(m.width - 4)
+	}
+	return style.Render("⚠ Partial results - the data below is incomplete: " + m.partialWarning)
+}
+
+// renderEmptyResult explains that the last query ran but matched no rows,
+// as opposed to no query having run yet
+func (m Model) renderEmptyResult() string {
+	msg := "✓ Query succeeded in " + describeTiming(m.lastDuration, m.lastExecTime) + " but returned 0 rows"
+	if n := len(m.resultColumns); n > 0 {
+		msg += fmt.Sprintf(" (%d columns)", n)
+	}
+
+	hint := "Check the query's filters"
+	if timeRange := m.activeTimeRange(); timeRange != "" {
+		hint += fmt.Sprintf(", or widen the time range (%s, %s to change)",
+			strings.ToLower(describeTimeRange(timeRange)), m.keys.TimeRange.Help().Key)
+	}
+	return m.styles.Success.Render(msg) + "\n" + m.styles.Muted.Render(hint)
+}
+
 func (m Model) updateHistoryView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "enter":
+	// Any key other than confirm cancels a pending clear
+	if m.confirmClear {
+		m.confirmClear = false
+		if key.Matches(msg, m.keys.Confirm) {
+			m.history.Clear()
+			if err := m.history.Save(); err != nil {
+				m.lastError = fmt.Sprintf("Failed to save history: %v", err)
+			}
+			m.historyList = nil
+			m.historyIndex = 0
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.ClearHistory):
+		if len(m.historyList) > 0 {
+			m.confirmClear = true
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Select):
 		if m.historyIndex >= 0 && m.historyIndex < len(m.historyList) {
 			m.editor.SetValue(m.historyList[m.historyIndex].Query)
 			m.currentView = ViewQuery
@@ -495,42 +1195,73 @@ func (m Model) updateHistoryView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "up", "k":
+	case key.Matches(msg, m.keys.AppendQuery):
+		// Keep the query being written, commented out so that running the
+		// editor runs only the entry added after it
+		if m.historyIndex >= 0 && m.historyIndex < len(m.historyList) {
+			query := m.historyList[m.historyIndex].Query
+			if current := strings.TrimRight(m.editor.Value(), "\n"); current != "" {
+				query = commentOut(current) + "\n\n" + query
+			}
+			m.editor.SetValue(query)
+			m.currentView = ViewQuery
+			m.editor.Focus()
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.CopyHistory):
+		if m.historyIndex >= 0 && m.historyIndex < len(m.historyList) {
+			return m, copyToClipboard(m.historyList[m.historyIndex].Query, "Query")
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Up):
 		if m.historyIndex > 0 {
 			m.historyIndex--
 		}
 		return m, nil
 
-	case "down", "j":
+	case key.Matches(msg, m.keys.Down):
 		if m.historyIndex < len(m.historyList)-1 {
 			m.historyIndex++
 		}
 		return m, nil
+
+	case key.Matches(msg, m.keys.PageUp):
+		m.historyIndex = max(m.historyIndex-m.historyPageSize(), 0)
+		return m, nil
+
+	case key.Matches(msg, m.keys.PageDown):
+		m.historyIndex = max(min(m.historyIndex+m.historyPageSize(), len(m.historyList)-1), 0)
+		return m, nil
 	}
 
 	return m, nil
 }
 
 func (m Model) updateHelpView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "enter", "q":
+	if key.Matches(msg, m.keys.Close) {
 		m.currentView = ViewQuery
 		return m, nil
 	}
-	return m, nil
+
+	var cmd tea.Cmd
+	m.helpView, cmd = m.helpView.Update(msg)
+	return m, cmd
 }
 
 func (m Model) updateWorkspaceView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "enter":
-		m.workspaceID = m.workspaceInput.Value()
-		if m.client != nil {
-			m.client.SetWorkspace(m.workspaceID)
+	switch {
+	case key.Matches(msg, m.keys.Select):
+		if ws := m.workspaceInput.Value(); ws != m.workspaceID {
+			m.workspaceID = ws
+			m.resetSchemas()
 		}
 		m.currentView = ViewQuery
 		m.editor.Focus()
 		m.connecting = true
 		m.connected = false
+		m.reconnecting = false
 		return m, m.Connect(m.authMethod)
 	}
 
@@ -540,57 +1271,90 @@ func (m Model) updateWorkspaceView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) updateRowDetailView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	row := m.table.GetSelectedRow()
-	columns := m.table.GetColumns()
+	columns, columnTypes, row := m.detailRow()
 	maxScroll := len(columns) - 1
 	if maxScroll < 0 {
 		maxScroll = 0
 	}
 
-	switch msg.String() {
-	case "esc", "q", "enter":
+	switch {
+	case key.Matches(msg, m.keys.Close, m.keys.Back):
 		m.currentView = ViewResults
+		if m.fullRecord != nil {
+			m.fullRecord = nil
+			m.detailSelected = nil
+		}
 		return m, nil
 
-	case "up", "k":
+	case key.Matches(msg, m.keys.Up):
 		if m.detailScrollPos > 0 {
 			m.detailScrollPos--
 		}
 		return m, nil
 
-	case "down", "j":
+	case key.Matches(msg, m.keys.Down):
 		if m.detailScrollPos < maxScroll {
 			m.detailScrollPos++
 		}
 		return m, nil
 
-	case "home", "g":
+	case key.Matches(msg, m.keys.Top):
 		m.detailScrollPos = 0
 		return m, nil
 
-	case "end", "G":
+	case key.Matches(msg, m.keys.Bottom):
 		m.detailScrollPos = maxScroll
 		return m, nil
 
-	case "pgup":
+	case key.Matches(msg, m.keys.PageUp):
 		m.detailScrollPos -= 10
 		if m.detailScrollPos < 0 {
 			m.detailScrollPos = 0
 		}
 		return m, nil
 
-	case "pgdown":
+	case key.Matches(msg, m.keys.PageDown):
 		m.detailScrollPos += 10
 		if m.detailScrollPos > maxScroll {
 			m.detailScrollPos = maxScroll
 		}
 		return m, nil
 
-	case "h":
+	case key.Matches(msg, m.keys.ToggleEmpty):
 		// Toggle hiding empty fields
 		m.hideEmptyFields = !m.hideEmptyFields
 		m.detailScrollPos = 0 // Reset scroll when toggling
 		return m, nil
+
+	case key.Matches(msg, m.keys.ToggleField):
+		m.toggleDetailField()
+		return m, nil
+
+	case key.Matches(msg, m.keys.CopyPanel):
+		if row == nil {
+			return m, nil
+		}
+		return m, copyToClipboard(detailText(m.detailFields(), columnTypes), "Row")
+
+	case key.Matches(msg, m.keys.InsertWhere):
+		clause := m.selectedRowWhere()
+		if clause == "" {
+			m.lastError = "No comparable fields selected"
+			return m, nil
+		}
+		m.appendToQuery("| " + clause)
+		return m, nil
+
+	case key.Matches(msg, m.keys.CopyWhere):
+		clause := m.selectedRowWhere()
+		if clause == "" {
+			m.lastError = "No comparable fields selected"
+			return m, nil
+		}
+		return m, copyToClipboard("| "+clause, "Where clause")
+
+	case key.Matches(msg, m.keys.FetchFullRow):
+		return m.fetchFullRow()
 	}
 
 	_ = row // Suppress unused warning
@@ -600,8 +1364,8 @@ func (m Model) updateRowDetailView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) updateTemplatesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Handle save template dialog
 	if m.savingTemplate {
-		switch msg.String() {
-		case "enter":
+		switch {
+		case key.Matches(msg, m.keys.Select):
 			name := m.templateInput.Value()
 			if name != "" {
 				m.templates.Add(name, m.editor.Value(), "", nil)
@@ -609,7 +1373,7 @@ func (m Model) updateTemplatesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			m.savingTemplate = false
 			return m, nil
-		case "esc":
+		case key.Matches(msg, m.keys.Back):
 			m.savingTemplate = false
 			return m, nil
 		}
@@ -618,8 +1382,8 @@ func (m Model) updateTemplatesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	}
 
-	switch msg.String() {
-	case "enter":
+	switch {
+	case key.Matches(msg, m.keys.Select):
 		if m.templateIndex >= 0 && m.templateIndex < len(m.templateList) {
 			m.editor.SetValue(m.templateList[m.templateIndex].Query)
 			m.templates.IncrementUseCount(m.templateList[m.templateIndex].ID)
@@ -629,7 +1393,7 @@ func (m Model) updateTemplatesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "d":
+	case key.Matches(msg, m.keys.Delete):
 		if len(m.templateList) > 0 && m.templateIndex < len(m.templateList) {
 			m.templates.Delete(m.templateList[m.templateIndex].ID)
 			m.templates.Save()
@@ -640,19 +1404,19 @@ func (m Model) updateTemplatesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "up", "k":
+	case key.Matches(msg, m.keys.Up):
 		if m.templateIndex > 0 {
 			m.templateIndex--
 		}
 		return m, nil
 
-	case "down", "j":
+	case key.Matches(msg, m.keys.Down):
 		if m.templateIndex < len(m.templateList)-1 {
 			m.templateIndex++
 		}
 		return m, nil
 
-	case "n":
+	case key.Matches(msg, m.keys.NewTemplate):
 		// Create new template from current query (if any)
 		if m.editor.Value() != "" {
 			m.savingTemplate = true
@@ -665,35 +1429,118 @@ func (m Model) updateTemplatesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m Model) executeQuery() (tea.Model, tea.Cmd) {
+// runQuery executes the query in the editor once connected, asking for
+// confirmation first if it may scan a whole table
+func (m Model) runQuery(bypassCache bool) (tea.Model, tea.Cmd) {
+	if !m.connected {
+		m.lastError = "Not connected. Press F3 to set workspace."
+		return m, nil
+	}
+	m.suggestion = "" // Clear any pending suggestion
+	m.suggestionPopup.Hide()
+	if !m.config.DisableScanGuard && m.activeTimeRange() == "" && isUnboundedQuery(m.editor.Value()) {
+		m.confirmScan = true
+		m.confirmScanForce = bypassCache
+		return m, nil
+	}
+	return m.executeQuery(bypassCache)
+}
+
+// executeQuery runs the editor's query, serving it from the result cache
+// unless bypassCache is set
+func (m Model) executeQuery(bypassCache bool) (tea.Model, tea.Cmd) {
 	query := strings.TrimSpace(m.editor.Value())
 	if query == "" {
 		m.lastError = "Query cannot be empty"
 		return m, nil
 	}
 
+	// Substitute {{name}} variables saved from earlier results
+	query, err := expandVariables(query, m.variables)
+	if err != nil {
+		hint := fmt.Sprintf("press %s on a results column to save one", m.keys.SaveVariable.Help().Key)
+		if len(m.variables) > 0 {
+			hint = "defined: " + strings.Join(m.variableNames(), ", ")
+		}
+		m.lastError = fmt.Sprintf("%v (%s)", err, hint)
+		return m, nil
+	}
+
 	// Add default limit if query doesn't specify one
 	query = ensureQueryLimit(query, 100)
 
+	// Learn which columns and tables the user actually queries
+	m.recordColumnUsage(query)
+	m.recordRecentTables(query)
+
 	m.loading = true
 	m.lastQuery = query
 	m.lastError = ""
+	progress := &azure.Progress{}
+	m.queryProgress = progress
+	m.queryStarted = time.Now()
+
+	// Relative ranges are resolved at execution time; cache them by range
+	// rather than by the exact timespan
+	timeRange := m.activeTimeRange()
+	timespan := timeSpanFor(timeRange, time.Now())
+	cacheQuery := query
+	if timespan != nil {
+		cacheQuery = "// time range: " + timeRange + "\n" + query
+	}
 
 	return m, tea.Batch(
 		m.spinner.Tick,
 		func() tea.Msg {
 			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(m.config.QueryTimeout)*time.Second)
 			defer cancel()
+			ctx = azure.WithProgress(ctx, progress)
 
-			result, err := m.client.Query(ctx, query, nil)
+			if !bypassCache {
+				if result, age, ok := m.cache.Get(m.workspaceID, cacheQuery, nil); ok {
+					return queryResultMsg{result: result, cached: true, cacheAge: age}
+				}
+			}
+
+			result, err := m.client.Query(ctx, query, timespan)
+			if err == nil && result.QueryStatus == "Success" {
+				m.cache.Put(m.workspaceID, cacheQuery, nil, result)
+			}
 			return queryResultMsg{result: result, err: err}
 		},
 	)
 }
 
+// replacesQuery reports whether accepting an AI suggestion would discard
+// what was typed, rather than extend it
+func replacesQuery(current, suggestion string) bool {
+	return strings.TrimSpace(current) != "" && !strings.HasPrefix(suggestion, current)
+}
+
+// noLimitDirective is a comment that stops the default limit being added
+// to a query, for queries meant to return every row
+const noLimitDirective = "nolimit"
+
+// hasNoLimitDirective reports whether a line of the query is commented with
+// //nolimit
+func hasNoLimitDirective(query string) bool {
+	for _, line := range strings.Split(query, "\n") {
+		if idx := commentStart(line); idx >= 0 && strings.EqualFold(strings.TrimSpace(line[idx+2:]), noLimitDirective) {
+			return true
+		}
+	}
+	return false
+}
+
 // ensureQueryLimit adds a limit to the query if one isn't already specified
+// and it isn't marked with //nolimit
 func ensureQueryLimit(query string, defaultLimit int) string {
-	queryLower := strings.ToLower(query)
+	if hasNoLimitDirective(query) {
+		return query
+	}
+
+	// Commented-out operators don't count
+	queryLower := strings.TrimSpace(strings.ToLower(StripKQLComments(query)))
 
 	// Check if query already has a limit (take, limit, or top)
 	limitKeywords := []string{"| take ", "|take ", "| limit ", "|limit ", "| top ", "|top "}
@@ -710,16 +1557,83 @@ func ensureQueryLimit(query string, defaultLimit int) string {
 		return query // User is typing a limit
 	}
 
-	// Add default limit
+	// render must stay the last operator, so the limit goes before it
+	if i := lastPipe(query); i >= 0 && trailingRenderPattern.MatchString(query[i:]) {
+		sep := " "
+		if strings.HasSuffix(strings.TrimRight(query[:i], " \t"), "\n") {
+			sep = "\n"
+		}
+		return fmt.Sprintf("%s| take %d%s%s", query[:i], defaultLimit, sep, query[i:])
+	}
+
+	// Add default limit, on its own line if the query ends in a comment
+	lines := strings.Split(query, "\n")
+	if commentStart(lines[len(lines)-1]) >= 0 {
+		return fmt.Sprintf("%s\n| take %d", query, defaultLimit)
+	}
 	return fmt.Sprintf("%s | take %d", query, defaultLimit)
 }
 
-func (m *Model) processResults(result *azure.QueryResult) {
-	if len(result.Tables) == 0 {
-		return
+// trailingRenderPattern matches a last pipe stage that renders a chart
+var trailingRenderPattern = regexp.MustCompile(`(?i)^\|\s*render\b`)
+
+// timeFilterPattern matches the calls that bound a query in time
+var timeFilterPattern = regexp.MustCompile(`\b(ago|between|datetime)\s*\(`)
+
+// limitPattern matches an operator that caps the number of rows a query returns
+var limitPattern = regexp.MustCompile(`\|\s*(take|limit|top|sample)\b`)
+
+// isUnboundedQuery reports whether a query has neither a time filter nor a
+// row limit of its own, and so may scan the whole table
+func isUnboundedQuery(query string) bool {
+	q := strings.ToLower(StripKQLComments(query))
+	if strings.TrimSpace(q) == "" {
+		return false
 	}
+	return !timeFilterPattern.MatchString(q) && !limitPattern.MatchString(q)
+}
+
+// queryErrorHint returns guidance for a classified query failure
+func queryErrorHint(err error) string {
+	switch {
+	case errors.Is(err, azure.ErrQuerySyntax):
+		return "Hint: the query is invalid. Check table and column names and operator syntax."
+	case errors.Is(err, azure.ErrThrottled):
+		return "Hint: too many requests. Wait a moment before running the query again."
+	case errors.Is(err, azure.ErrUnauthorized):
+		return "Hint: access denied. Check that you have read access to the workspace, or try --auth cli after 'az login'."
+	case errors.Is(err, azure.ErrWorkspaceNotFound):
+		return "Hint: workspace not found. Check the workspace ID (F3 to change)."
+	}
+	return ""
+}
 
-	table := result.Tables[0]
+func (m *Model) processResults(result *azure.QueryResult) {
+	m.resultAsOf = result.AsOf
+	m.truncatedAt = 0
+	if result.Truncated {
+		m.truncatedAt = result.RowCount
+	}
+	m.partialWarning = ""
+	if result.IsPartial() {
+		m.partialWarning = result.PartialError
+		if m.partialWarning == "" {
+			m.partialWarning = result.QueryStatus
+		}
+	}
+	// A result without tables is shown like an empty table, replacing the
+	// previous result
+	var table azure.Table
+	if len(result.Tables) > 0 {
+		table = result.Tables[0]
+	}
+	// Joins can return several columns with one name; rename them so row
+	// details, exports and per-column settings can tell them apart. Only the
+	// local copy is renamed: the result may be shared with the cache
+	if unique, renamed := UniqueColumns(table.Columns); len(renamed) > 0 {
+		table.Columns = unique
+		m.notice = "Duplicate column names renamed: " + strings.Join(renamed, ", ")
+	}
 	columns := make([]string, len(table.Columns))
 	columnTypes := make([]string, len(table.Columns))
 
@@ -732,22 +1646,70 @@ func (m *Model) processResults(result *azure.QueryResult) {
 	for i, row := range table.Rows {
 		rows[i] = make([]string, len(row))
 		for j, cell := range row {
-			rows[i][j] = formatCell(cell)
+			colType := ""
+			if j < len(columnTypes) {
+				colType = columnTypes[j]
+			}
+			rows[i][j] = formatCell(cell, colType)
+		}
+	}
+
+	// Compare with the baseline, keyed on the frozen column if there is one
+	rawRows := table.Rows
+	var marks []rowMark
+	m.diff = nil
+	if m.baseline != nil && sameQuery(m.baseline.query, m.lastQuery) && sameColumns(m.baseline.columns, table.Columns) {
+		var removed [][]interface{}
+		var summary diffSummary
+		marks, removed, summary = diffRows(*m.baseline, table.Columns, table.Rows, m.keyColumnName())
+		m.diff = &summary
+
+		// Removed rows are listed after the result, without growing the result's own slice
+		rawRows = append(rawRows[:len(rawRows):len(rawRows)], removed...)
+		for _, row := range removed {
+			cells := make([]string, len(row))
+			for j, cell := range row {
+				cells[j] = formatCell(cell, columnTypes[j])
+			}
+			rows = append(rows, cells)
+			marks = append(marks, rowRemoved)
 		}
 	}
 
+	m.table.SetColumnWidths(m.config.ColumnWidths[sourceTable(m.lastQuery)])
 	m.table.SetData(columns, columnTypes, rows)
+	m.table.SetRowMarks(marks)
+	m.result = result
+	m.resultColumns = table.Columns
+	m.resultRows = rawRows
+	m.resetChart(columnTypes, result.Render)
+	m.detailSelected = nil
+	m.fullRecord = nil
 	m.rowCount = result.RowCount
 	m.lastDuration = result.Duration
+	m.lastExecTime = result.ExecutionTime
+	if len(rows) == 0 {
+		return // Stay in the editor to refine a query that matched nothing
+	}
 	m.currentView = ViewResults
 	m.editor.Blur()
 	m.table.Focus()
 }
 
-func (m Model) navigateHistory(delta int) (tea.Model, tea.Cmd) {
-	if len(m.historyList) == 0 {
-		m.historyList = m.history.GetRecent(50)
+// exportResult returns the displayed result for reports and snapshots, with
+// the columns named as shown rather than as the cached result has them
+func (m Model) exportResult() *azure.QueryResult {
+	result := *m.result
+	if len(result.Tables) > 0 {
+		table := result.Tables[0]
+		table.Columns = m.resultColumns
+		result.Tables = append([]azure.Table{table}, result.Tables[1:]...)
 	}
+	return &result
+}
+
+func (m Model) navigateHistory(delta int) (tea.Model, tea.Cmd) {
+	m.loadHistoryList()
 
 	m.historyIndex += delta
 	if m.historyIndex < 0 {
@@ -775,25 +1737,67 @@ func (m *Model) addToHistory(success bool, errMsg string) {
 		ErrorMsg:   errMsg,
 	}
 	m.history.Add(entry)
-	m.historyList = nil // Reset to force reload
+
+	// Keep the cached list in step with the history rather than reloading it
+	if m.historyList != nil {
+		m.historyList = append([]azure.HistoryEntry{entry}, m.historyList...)
+		if m.history.MaxSize > 0 && len(m.historyList) > m.history.MaxSize {
+			m.historyList = m.historyList[:m.history.MaxSize]
+		}
+	}
+
+	// Save right away so a crash can't lose the query that just ran
+	if err := m.history.Save(); err != nil {
+		azure.Logger().Warn("saving history failed", "error", err)
+	}
+}
+
+// loadHistoryList fills the cached history list the first time it is needed
+func (m *Model) loadHistoryList() {
+	if m.historyList == nil {
+		m.historyList = m.history.GetRecent(len(m.history.Entries))
+	}
+}
+
+// historyPageSize returns how many history entries fit on one page
+func (m Model) historyPageSize() int {
+	return max(m.height-10, 5)
+}
+
+// logSlowQuery records the last query in the slow query log when it took
+// longer than the configured threshold
+func (m *Model) logSlowQuery() {
+	threshold := time.Duration(m.config.SlowQueryMs) * time.Millisecond
+	if threshold <= 0 || m.lastDuration < threshold {
+		return
+	}
+	if err := m.slowLog.Append(m.workspaceID, m.lastQuery, m.lastDuration, m.lastExecTime, time.Now()); err != nil {
+		m.lastError = fmt.Sprintf("Failed to write slow query log: %v", err)
+	}
 }
 
-func (m *Model) saveState() {
-	m.history.Save()
-	m.config.Save()
-	m.templates.Save()
+// describeTiming splits the total query time into server execution and
+// network/queue time when the server reported its execution time
+func describeTiming(total, execution time.Duration) string {
+	s := total.Round(time.Millisecond).String()
+	if execution <= 0 || execution > total {
+		return s
+	}
+	return fmt.Sprintf("%s (exec %s, network/queue %s)", s,
+		execution.Round(time.Millisecond), (total - execution).Round(time.Millisecond))
 }
 
 // loadAvailableTables fetches available tables for autocomplete context
 func (m *Model) loadAvailableTables() tea.Cmd {
+	client, workspace := m.client, m.workspaceID
 	return func() tea.Msg {
-		if m.client == nil {
-			return tablesMsg{err: fmt.Errorf("not connected")}
+		if client == nil {
+			return tablesMsg{workspace: workspace, err: fmt.Errorf("not connected")}
 		}
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
-		tables, err := m.client.GetAvailableTables(ctx)
-		return tablesMsg{tables: tables, err: err}
+		tables, err := client.GetAvailableTables(ctx)
+		return tablesMsg{workspace: workspace, tables: tables, err: err}
 	}
 }
 
@@ -807,23 +1811,32 @@ func (m *Model) fetchInitialSchemas(tables []string) tea.Cmd {
 	}
 
 	for i := 0; i < limit; i++ {
-		table := tables[i]
-		cmds = append(cmds, func() tea.Msg {
-			if m.client == nil {
-				return nil
-			}
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			defer cancel()
-			columns, err := m.client.GetTableSchema(ctx, table)
-			return schemaMsg{tableName: table, columns: columns, err: err}
-		})
+		cmds = append(cmds, m.fetchSchema(tables[i]))
+	}
+	return tea.Batch(cmds...)
+}
+
+// fetchReferencedSchemas fetches the schemas of tables referenced in the
+// query that aren't cached yet
+func (m *Model) fetchReferencedSchemas() tea.Cmd {
+	ctx := m.autocompleteEngine.ParseContext(m.editor.Value(), m.editor.CursorPosition())
+
+	var cmds []tea.Cmd
+	for _, table := range m.autocompleteEngine.MissingSchemas(ctx.ReferencedTables) {
+		cmds = append(cmds, m.fetchSchema(table))
 	}
 	return tea.Batch(cmds...)
 }
 
-// getSuggestion fetches a query suggestion from OpenAI
+// getSuggestion fetches a query suggestion from OpenAI. The request is
+// canceled if another suggestion request or further typing supersedes it.
 func (m *Model) getSuggestion(tag int) tea.Cmd {
+	m.cancelPendingSuggestion()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelSuggestion = cancel
+
 	return func() tea.Msg {
+		defer cancel()
 		if m.openaiClient == nil {
 			return suggestionMsg{err: fmt.Errorf("OpenAI not available"), tag: tag}
 		}
@@ -832,18 +1845,26 @@ func (m *Model) getSuggestion(tag int) tea.Cmd {
 			return suggestionMsg{err: fmt.Errorf("empty query"), tag: tag}
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-
-		// Parse tables from the query and fetch their schemas
+		// Parse tables from the query and fetch their schemas. The
+		// suggestion request itself is limited by suggest_timeout_ms.
 		referencedTables := m.parseTablesFromQuery(query)
-		schemas := m.fetchSchemasForTables(ctx, referencedTables)
+		schemaCtx, cancelSchemas := context.WithTimeout(ctx, 10*time.Second)
+		schemas := m.fetchSchemasForTables(schemaCtx, referencedTables)
+		cancelSchemas()
 
 		suggestion, err := m.openaiClient.SuggestKQLQuery(ctx, query, m.availableTables, schemas)
 		return suggestionMsg{suggestion: suggestion, err: err, tag: tag}
 	}
 }
 
+// cancelPendingSuggestion stops the in-flight AI suggestion request, if any
+func (m *Model) cancelPendingSuggestion() {
+	if m.cancelSuggestion != nil {
+		m.cancelSuggestion()
+		m.cancelSuggestion = nil
+	}
+}
+
 // updateLocalSuggestions updates the popup with local autocomplete suggestions
 func (m *Model) updateLocalSuggestions() {
 	query := m.editor.Value()
@@ -862,6 +1883,8 @@ func (m *Model) updateLocalSuggestions() {
 	}
 
 	m.suggestionPopup.SetSuggestions(filtered)
+	m.suggestionPopup.SetHint(m.autocompleteEngine.Signature(ctx))
+	m.suggestionPopup.SetMatch(ctx.CurrentWord)
 }
 
 // acceptLocalSuggestion accepts a suggestion from the popup
@@ -883,6 +1906,39 @@ func (m *Model) acceptLocalSuggestion(text string) {
 	m.editor.SetValue(newQuery)
 }
 
+// recordColumnUsage counts the cached columns of the query's tables that
+// appear in text and saves the updated counts
+func (m *Model) recordColumnUsage(text string) {
+	recorded := false
+	for _, table := range m.parseTablesFromQuery(m.editor.Value()) {
+		for _, col := range m.schemaCache[table] {
+			if containsIdentifier(text, col.Name) {
+				m.columnUsage.Record(table, col.Name)
+				recorded = true
+			}
+		}
+	}
+	if recorded {
+		m.columnUsage.Save()
+	}
+}
+
+// containsIdentifier reports whether name appears in text as a whole word
+func containsIdentifier(text, name string) bool {
+	for offset := 0; ; {
+		idx := strings.Index(text[offset:], name)
+		if idx == -1 {
+			return false
+		}
+		start := offset + idx
+		end := start + len(name)
+		if (start == 0 || !isAlphaNum(text[start-1])) && (end == len(text) || !isAlphaNum(text[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
 // parseTablesFromQuery extracts table names from a KQL query
 func (m *Model) parseTablesFromQuery(query string) []string {
 	var tables []string
@@ -919,6 +1975,43 @@ func (m *Model) parseTablesFromQuery(query string) []string {
 	return tables
 }
 
+// recordRecentTables remembers the tables the query uses as the most
+// recently queried in the workspace
+func (m *Model) recordRecentTables(query string) {
+	tables := m.parseTablesFromQuery(query)
+	if len(tables) == 0 {
+		return
+	}
+	m.recentTables.Add(m.workspaceID, tables)
+	m.recentTables.Save()
+}
+
+// showRecentTables lists the workspace's recently queried tables in the
+// suggestion popup. Like other completions, only tables starting with the
+// word at the cursor are listed and accepting one replaces that word.
+func (m *Model) showRecentTables() {
+	ctx := m.autocompleteEngine.ParseContext(m.editor.Value(), m.editor.CursorPosition())
+	recent := m.recentTables.Get(m.workspaceID)
+	if len(recent) == 0 {
+		m.notice = "No recently queried tables in this workspace"
+		return
+	}
+
+	var suggestions []Suggestion
+	for _, table := range recent {
+		if strings.HasPrefix(strings.ToLower(table), strings.ToLower(ctx.CurrentWord)) {
+			suggestions = append(suggestions, Suggestion{Text: table, Type: "table", Description: "recent"})
+		}
+	}
+	if len(suggestions) == 0 {
+		m.notice = fmt.Sprintf("No recently queried tables start with %q", ctx.CurrentWord)
+		return
+	}
+	m.suggestionPopup.SetSuggestions(suggestions)
+	m.suggestionPopup.SetHint("")
+	m.suggestionPopup.SetMatch(ctx.CurrentWord)
+}
+
 // fetchSchemasForTables fetches schemas for the given tables, using cache when available
 func (m *Model) fetchSchemasForTables(ctx context.Context, tables []string) map[string][]azure.Column {
 	schemas := make(map[string][]azure.Column)
@@ -946,6 +2039,10 @@ func (m *Model) fetchSchemasForTables(ctx context.Context, tables []string) map[
 
 // View renders the UI
 func (m Model) View() string {
+	if m.tooSmall() {
+		return m.renderTooSmall()
+	}
+
 	var b strings.Builder
 
 	// Header
@@ -970,12 +2067,28 @@ func (m Model) View() string {
 		b.WriteString(m.renderRowDetailView())
 	case ViewTemplates:
 		b.WriteString(m.renderTemplatesView())
+	case ViewTimeRange:
+		b.WriteString(m.renderTimeRangeView())
+	case ViewBookmarks:
+		b.WriteString(m.renderBookmarksView())
+	case ViewSchema:
+		b.WriteString(m.renderSchemaView())
+	case ViewError:
+		b.WriteString(m.renderErrorView())
+	case ViewCatalog:
+		b.WriteString(m.renderCatalogView())
 	}
 
 	// Error message
-	if m.lastError != "" {
+	if m.lastError != "" && m.currentView != ViewError {
 		b.WriteString("\n")
-		b.WriteString(m.styles.Error.Render("Error: " + m.lastError))
+		b.WriteString(m.renderErrorPreview())
+	}
+
+	// Portal link that couldn't be opened in a browser
+	if m.portalURL != "" {
+		b.WriteString("\n")
+		b.WriteString(m.renderPortalLink())
 	}
 
 	// Footer/Help
@@ -996,6 +2109,8 @@ func (m Model) renderStatusBar() string {
 	// Connection status
 	if m.connected {
 		parts = append(parts, m.styles.Success.Render("● Connected"))
+	} else if m.reconnecting {
+		parts = append(parts, m.spinner.View()+" "+m.styles.Warning.Render("Reconnecting..."))
 	} else if m.connecting {
 		parts = append(parts, m.spinner.View()+" "+m.styles.Warning.Render("Connecting..."))
 	} else {
@@ -1011,28 +2126,95 @@ func (m Model) renderStatusBar() string {
 		parts = append(parts, m.styles.StatusBarKey.Render("Workspace: ")+m.styles.Muted.Render(ws))
 	}
 
+	// Time range
+	if timeRange := m.activeTimeRange(); timeRange != "" {
+		parts = append(parts, m.styles.StatusBarKey.Render("Range: ")+m.styles.Muted.Render("last "+timeRange))
+	}
+
+	// The default row limit is off for the query in the editor
+	if hasNoLimitDirective(m.editor.Value()) {
+		parts = append(parts, m.styles.Warning.Render("No row limit (//nolimit)"))
+	}
+
 	// Loading indicator
 	if m.loading {
-		parts = append(parts, m.spinner.View()+" Querying...")
+		parts = append(parts, m.renderQueryProgress())
+	}
+
+	if m.notice != "" {
+		parts = append(parts, m.styles.Success.Render(m.notice))
 	}
 
 	// Last query stats
-	if m.rowCount > 0 && !m.loading {
-		stats := fmt.Sprintf("%d rows in %s", m.rowCount, m.lastDuration.Round(time.Millisecond))
+	if m.result != nil && !m.loading {
+		stats := fmt.Sprintf("%d rows in %s", m.rowCount, describeTiming(m.lastDuration, m.lastExecTime))
+		if m.resultCached {
+			stats += fmt.Sprintf(", cached (age %s)", m.cacheAge.Round(time.Second))
+		}
 		parts = append(parts, m.styles.Muted.Render(stats))
 	}
 
+	// Data freshness
+	if !m.resultAsOf.IsZero() && !m.loading {
+		age := time.Since(m.resultAsOf).Round(time.Second)
+		freshness := fmt.Sprintf("data as of %s (%s ago)", m.resultAsOf.Format("15:04:05"), age)
+		parts = append(parts, m.styles.Muted.Render(freshness))
+	}
+
 	return strings.Join(parts, "  │  ")
 }
 
 func (m Model) renderMainView() string {
 	var b strings.Builder
 
+	b.WriteString(m.renderEditorSection())
+
+	// Results table
+	if m.table.RowCount() > 0 {
+		b.WriteString(m.renderResultsHeader())
+		b.WriteString(m.table.View())
+	} else if m.partialWarning != "" && !m.loading {
+		b.WriteString(m.renderPartialWarning())
+	} else if m.result != nil && !m.loading {
+		b.WriteString(m.renderEmptyResult())
+	} else if !m.loading {
+		b.WriteString(m.styles.Muted.Render("No results yet. Enter a query and press F5 or Ctrl+Enter to execute."))
+	}
+
+	return b.String()
+}
+
+// renderEditorSection renders the query editor along with any suggestion UI below it
+func (m Model) renderEditorSection() string {
+	var b strings.Builder
+
 	// Query editor
 	b.WriteString(m.editor.View())
 
+	if m.confirmScan {
+		b.WriteString("\n")
+		b.WriteString(m.styles.Warning.Render("This query has no time bound and may scan a lot of data. Run anyway? (y/n)"))
+		return b.String()
+	}
+
+	if m.confirmReplace {
+		b.WriteString("\n")
+		b.WriteString(m.styles.Muted.Render(m.suggestion))
+		b.WriteString("\n")
+		b.WriteString(m.styles.Warning.Render("The suggestion replaces your entire query. Replace it? (y/n)"))
+		b.WriteString("\n\n")
+		return b.String()
+	}
+
 	// Local autocomplete popup (takes priority)
-	if m.suggestionPopup.IsVisible() {
+	if m.reference != "" {
+		style := m.styles.Box
+		if m.width > 10 {
+			style = style.Width(m.width - 4)
+		}
+		b.WriteString("\n")
+		b.WriteString(style.Render(m.reference))
+	} else if m.suggestionPopup.IsVisible() || m.suggestionPopup.HasHint() {
 		b.WriteString("\n")
 		b.WriteString(m.suggestionPopup.View())
 	} else if m.suggestLoading {
@@ -1060,20 +2242,18 @@ func (m Model) renderMainView() string {
 		// Simple instructions
 		b.WriteString(preview)
 		b.WriteString("\n")
-		b.WriteString(m.styles.Muted.Render(" [Tab] to accept · [Esc] to dismiss"))
+		if replacesQuery(current, suggestion) {
+			b.WriteString(m.styles.Muted.Render(" [Tab] to replace query · [Esc] to dismiss"))
+		} else {
+			b.WriteString(m.styles.Muted.Render(" [Tab] to accept · [Esc] to dismiss"))
+		}
+	} else if hint := m.renderCostHint(); hint != "" {
+		b.WriteString("\n")
+		b.WriteString(hint)
 	}
 
 	b.WriteString("\n\n")
 
-	// Results table
-	if m.table.RowCount() > 0 {
-		b.WriteString(m.styles.Prompt.Render("Results"))
-		b.WriteString("\n")
-		b.WriteString(m.table.View())
-	} else if !m.loading {
-		b.WriteString(m.styles.Muted.Render("No results yet. Enter a query and press F5 or Ctrl+Enter to execute."))
-	}
-
 	return b.String()
 }
 
@@ -1083,12 +2263,22 @@ func (m Model) renderHistoryView() string {
 	b.WriteString(m.styles.Header.Render("Query History"))
 	b.WriteString("\n\n")
 
+	if m.confirmClear {
+		b.WriteString(m.styles.Warning.Render(fmt.Sprintf("Clear all %d history entries? This cannot be undone. (y/n)", len(m.history.Entries))))
+		b.WriteString("\n\n")
+	}
+
 	if len(m.historyList) == 0 {
 		b.WriteString(m.styles.Muted.Render("No history yet."))
 		return b.String()
 	}
 
-	for i, entry := range m.historyList {
+	pageSize := m.historyPageSize()
+	page := m.historyIndex / pageSize
+	start := page * pageSize
+	end := min(start+pageSize, len(m.historyList))
+	for i := start; i < end; i++ {
+		entry := m.historyList[i]
 		prefix := "  "
 		style := m.styles.Muted
 		if i == m.historyIndex {
@@ -1106,11 +2296,10 @@ func (m Model) renderHistoryView() string {
 			prefix, status, query, entry.ExecutedAt.Format("15:04:05"), entry.RowCount)
 		b.WriteString(style.Render(line))
 		b.WriteString("\n")
+	}
 
-		if i >= 20 {
-			b.WriteString(m.styles.Muted.Render(fmt.Sprintf("  ... and %d more", len(m.historyList)-20)))
-			break
-		}
+	if pages := (len(m.historyList) + pageSize - 1) / pageSize; pages > 1 {
+		b.WriteString(m.styles.Muted.Render(fmt.Sprintf("\nPage %d of %d (%d entries)", page+1, pages, len(m.historyList))))
 	}
 
 	return b.String()
@@ -1128,7 +2317,7 @@ func (m Model) renderTemplatesView() string {
 		b.WriteString("Name: ")
 		b.WriteString(m.templateInput.View())
 		b.WriteString("\n\n")
-		b.WriteString(m.styles.Muted.Render("Press Enter to save, Esc to cancel"))
+		b.WriteString(m.styles.Muted.Render(m.promptHint("save")))
 		return b.String()
 	}
 
@@ -1168,43 +2357,12 @@ func (m Model) renderTemplatesView() string {
 }
 
 func (m Model) renderHelpView() string {
-	help := `
-AZURE LOG ANALYTICS CLI - HELP
-
-NAVIGATION
-  Tab           Switch between query editor and results
-  F1            Show this help
-  F2            Show query history
-  F3            Change workspace
-  F4            Show saved templates
-  Esc           Return to query view / Dismiss suggestion
-  Ctrl+Q        Quit
-
-QUERY EDITOR
-  F5, Ctrl+Enter   Execute query
-  Ctrl+Space       AI query suggestion (Azure OpenAI)
-  Ctrl+S, F6       Save query as template
-  Tab              Accept AI suggestion (when shown)
-  Ctrl+L           Clear editor
-  Ctrl+Up/Down     Navigate query history
-
-RESULTS TABLE
-  j/k, Up/Down     Navigate rows
-  h/l, Left/Right  Scroll columns
-  Enter            View row details (full content)
-  PgUp/PgDown      Page navigation
-  Home/End, g/G    Jump to start/end
-
-KQL QUICK REFERENCE
-  TableName | take 10              Fetch 10 rows
-  TableName | where Column == "x"  Filter rows
-  TableName | project Col1, Col2   Select columns
-  TableName | summarize count()    Aggregate data
-  TableName | order by Time desc   Sort results
-
-Press Enter or Q to close help.
-`
-	return m.styles.Box.Render(help)
+	content := m.helpView.View()
+	if m.helpView.Scrollable() {
+		content += "\n\n" + m.styles.Muted.Render(m.helpView.ScrollInfo()+" · "+m.keys.Down.Help().Key+"/"+m.keys.Up.Help().Key+" to scroll")
+	}
+	content += "\n\n" + "Press " + m.keys.Close.Help().Key + " to close help."
+	return m.styles.Box.Render(content)
 }
 
 func (m Model) renderWorkspaceView() string {
@@ -1217,7 +2375,7 @@ func (m Model) renderWorkspaceView() string {
 	b.WriteString(m.workspaceInput.View())
 	b.WriteString("\n\n")
 
-	b.WriteString(m.styles.Muted.Render("Press Enter to connect, Esc to cancel"))
+	b.WriteString(m.styles.Muted.Render(m.promptHint("connect")))
 
 	// Show saved workspaces
 	if len(m.config.SavedWorkspaces) > 0 {
@@ -1235,11 +2393,14 @@ func (m Model) renderWorkspaceView() string {
 func (m Model) renderRowDetailView() string {
 	var b strings.Builder
 
-	row := m.table.GetSelectedRow()
-	columns := m.table.GetColumns()
+	columns, columnTypes, row := m.detailRow()
 	rowIdx := m.table.GetSelectedRowIndex()
 
-	b.WriteString(m.styles.Header.Render(fmt.Sprintf("Row Detail (Row %d/%d)", rowIdx+1, m.table.RowCount())))
+	title := fmt.Sprintf("Row Detail (Row %d/%d)", rowIdx+1, m.table.RowCount())
+	if m.fullRecord != nil {
+		title = fmt.Sprintf("Row Detail (Row %d/%d, full record)", rowIdx+1, m.table.RowCount())
+	}
+	b.WriteString(m.styles.Header.Render(title))
 	b.WriteString("\n\n")
 
 	if row == nil || len(columns) == 0 {
@@ -1248,23 +2409,12 @@ func (m Model) renderRowDetailView() string {
 	}
 
 	// Build list of fields to display (filter empty if enabled)
-	type fieldInfo struct {
-		name  string
-		value string
-	}
-	var fields []fieldInfo
+	fields := m.detailFields()
 	totalFields := len(columns)
-
-	for i, col := range columns {
-		if i >= len(row) {
-			break
+	for i, f := range fields {
+		if f.index < len(columnTypes) && columnTypes[f.index] == "dynamic" {
+			fields[i].value = PrettyDynamic(f.value)
 		}
-		value := row[i]
-		// Skip empty fields if hiding is enabled
-		if m.hideEmptyFields && value == "" {
-			continue
-		}
-		fields = append(fields, fieldInfo{name: col, value: value})
 	}
 
 	// Calculate visible rows based on height
@@ -1303,11 +2453,15 @@ func (m Model) renderRowDetailView() string {
 	for i := scrollPos; i < endIdx; i++ {
 		f := fields[i]
 
-		// Highlight current scroll position
+		// Highlight current scroll position and fields picked for a where clause
 		prefix := "  "
 		if i == scrollPos {
 			prefix = "▶ "
 		}
+		mark := "  "
+		if m.detailSelected[f.index] {
+			mark = "✓ "
+		}
 
 		// Format column name with padding
 		paddedName := f.name
@@ -1323,9 +2477,12 @@ func (m Model) renderRowDetailView() string {
 		if valueStr == "" {
 			valueStr = m.styles.Muted.Render("(empty)")
 		}
+		// Align continuation lines of pretty-printed values after the name
+		valueStr = strings.ReplaceAll(valueStr, "\n", "\n"+strings.Repeat(" ", maxNameWidth+6))
 
-		line := fmt.Sprintf("%s%s: %s",
+		line := fmt.Sprintf("%s%s%s: %s",
 			prefix,
+			m.styles.Success.Render(mark),
 			m.styles.Bold.Foreground(ColorSecondary).Render(paddedName),
 			valueStr)
 		b.WriteString(line)
@@ -1335,88 +2492,139 @@ func (m Model) renderRowDetailView() string {
 	// Scroll indicator with filter info
 	b.WriteString("\n")
 	if m.hideEmptyFields {
-		scrollInfo := fmt.Sprintf("Showing %d/%d fields (hiding %d empty) · h to show all",
-			len(fields), totalFields, totalFields-len(fields))
+		scrollInfo := fmt.Sprintf("Showing %d/%d fields (hiding %d empty) · %s to show all",
+			len(fields), totalFields, totalFields-len(fields), m.keys.ToggleEmpty.Help().Key)
 		b.WriteString(m.styles.Muted.Render(scrollInfo))
 	} else {
-		scrollInfo := fmt.Sprintf("Showing all %d fields · h to hide empty", totalFields)
+		scrollInfo := fmt.Sprintf("Showing all %d fields · %s to hide empty", totalFields, m.keys.ToggleEmpty.Help().Key)
 		b.WriteString(m.styles.Muted.Render(scrollInfo))
 	}
 
 	b.WriteString("\n\n")
-	b.WriteString(m.styles.Muted.Render("j/k to scroll · Esc to return"))
+	b.WriteString(m.styles.Muted.Render(fmt.Sprintf("%s/%s to scroll · %s to select · %s to add where clause · %s to copy it · %s to return",
+		m.keys.Down.Help().Key, m.keys.Up.Help().Key, m.keys.ToggleField.Help().Key,
+		m.keys.InsertWhere.Help().Key, m.keys.CopyWhere.Help().Key, m.keys.Back.Help().Key)))
 
 	return b.String()
 }
 
+// promptHint returns the hint under a text prompt, e.g. "Press Enter to
+// save, Esc to cancel", with the configured keys
+func (m Model) promptHint(action string) string {
+	return fmt.Sprintf("Press %s to %s, %s to cancel", m.keys.Select.Help().Key, action, m.keys.Back.Help().Key)
+}
+
 func (m Model) renderFooter() string {
-	var keys []string
+	k := m.keys
+	hint := func(keyName, desc string) string {
+		return m.styles.HelpKey.Render(keyName) + " " + desc
+	}
+	navigate := k.Down.Help().Key + "/" + k.Up.Help().Key
 
+	var keys []string
 	switch m.currentView {
 	case ViewQuery:
 		keys = []string{
-			m.styles.HelpKey.Render("F5") + " Execute",
-			m.styles.HelpKey.Render("Ctrl+Space") + " AI Suggest",
-			m.styles.HelpKey.Render("Tab") + " Results",
-			m.styles.HelpKey.Render("F2") + " History",
-			m.styles.HelpKey.Render("F4") + " Templates",
-			m.styles.HelpKey.Render("Ctrl+Q") + " Quit",
+			hint(k.Execute.Help().Key, "Execute"),
+			hint(k.AISuggest.Help().Key, "AI Suggest"),
+			hint(k.SwitchPane.Help().Key, "Results"),
+			hint(k.History.Help().Key, "History"),
+			hint(k.Templates.Help().Key, "Templates"),
+			hint(k.Quit.Help().Key, "Quit"),
 		}
 	case ViewResults:
 		keys = []string{
-			m.styles.HelpKey.Render("Enter") + " Details",
-			m.styles.HelpKey.Render("Tab") + " Editor",
-			m.styles.HelpKey.Render("j/k") + " Navigate",
-			m.styles.HelpKey.Render("h/l") + " Scroll",
-			m.styles.HelpKey.Render("Esc") + " Back",
-		}
-	case ViewRowDetail:
+			hint(k.Select.Help().Key, "Details"),
+			hint(k.SwitchPane.Help().Key, "Editor"),
+			hint(navigate, "Navigate"),
+			hint(k.Left.Help().Key+"/"+k.Right.Help().Key, "Scroll"),
+			hint(k.FreezeColumn.Help().Key, "Freeze"),
+			hint(k.Back.Help().Key, "Back"),
+		}
+	case ViewRowDetail, ViewHelp, ViewError:
 		keys = []string{
-			m.styles.HelpKey.Render("j/k") + " Scroll",
-			m.styles.HelpKey.Render("Esc") + " Back",
+			hint(navigate, "Scroll"),
+			hint(k.Back.Help().Key, "Back"),
 		}
 	case ViewHistory:
 		keys = []string{
-			m.styles.HelpKey.Render("Enter") + " Select",
-			m.styles.HelpKey.Render("j/k") + " Navigate",
-			m.styles.HelpKey.Render("Esc") + " Back",
+			hint(k.Select.Help().Key, "Select"),
+			hint(k.AppendQuery.Help().Key, "Append"),
+			hint(k.CopyHistory.Help().Key, "Copy"),
+			hint(navigate, "Navigate"),
+			hint(k.ClearHistory.Help().Key, "Clear"),
+			hint(k.Back.Help().Key, "Back"),
+		}
+	case ViewBookmarks:
+		keys = []string{
+			hint(k.Select.Help().Key, "Load"),
+			hint(k.NewBookmark.Help().Key, "New"),
+			hint(k.Delete.Help().Key, "Delete"),
+			hint(navigate, "Navigate"),
+			hint(k.Back.Help().Key, "Back"),
+		}
+	case ViewCatalog:
+		keys = []string{
+			hint(k.Select.Help().Key, "Load"),
+			hint(k.NewTemplate.Help().Key, "Copy to templates"),
+			hint(navigate, "Navigate"),
+			hint(k.Back.Help().Key, "Back"),
+		}
+	case ViewSchema:
+		keys = []string{
+			hint(k.Select.Help().Key, "Insert table"),
+			hint(k.SuggestionPrev.Help().Key+"/"+k.SuggestionNext.Help().Key, "Navigate"),
+			hint("Type", "Filter"),
+			hint(k.Back.Help().Key, "Back"),
+		}
+	case ViewTimeRange:
+		keys = []string{
+			hint(k.Select.Help().Key, "Apply"),
+			hint(navigate, "Navigate"),
+			hint(k.Back.Help().Key, "Back"),
 		}
 	case ViewTemplates:
 		keys = []string{
-			m.styles.HelpKey.Render("Enter") + " Load",
-			m.styles.HelpKey.Render("d") + " Delete",
-			m.styles.HelpKey.Render("j/k") + " Navigate",
-			m.styles.HelpKey.Render("Esc") + " Back",
+			hint(k.Select.Help().Key, "Load"),
+			hint(k.Delete.Help().Key, "Delete"),
+			hint(navigate, "Navigate"),
+			hint(k.Back.Help().Key, "Back"),
 		}
 	default:
 		keys = []string{
-			m.styles.HelpKey.Render("Esc") + " Back",
+			hint(k.Back.Help().Key, "Back"),
 		}
 	}
 
 	return m.styles.Help.Render(strings.Join(keys, "  •  "))
 }
 
-func formatCell(v interface{}) string {
+func formatCell(v interface{}, colType string) string {
 	if v == nil {
 		return ""
 	}
 
+	if colType == "dynamic" {
+		return FormatDynamic(v)
+	}
+
 	switch val := v.(type) {
 	case string:
+		if colType == "datetime" {
+			return FormatDatetime(val)
+		}
 		return val
 	case float64:
-		if val == float64(int64(val)) {
-			return fmt.Sprintf("%d", int64(val))
-		}
-		return fmt.Sprintf("%.2f", val)
+		return formatNumber(val)
+	case int64:
+		return formatNumber(float64(val))
 	case bool:
 		if val {
 			return "true"
 		}
 		return "false"
 	case time.Time:
-		return val.Format("2006-01-02 15:04:05")
+		return FormatDatetime(val)
 	default:
 		return fmt.Sprintf("%v", val)
 	}
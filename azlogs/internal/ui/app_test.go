@@ -0,0 +1,503 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestModel_EmptyResult(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	full := &azure.QueryResult{
+		RowCount: 1,
+		Tables: []azure.Table{{
+			Columns: []azure.Column{{Name: "Computer", Type: "string"}},
+			Rows:    [][]interface{}{{"web-01"}},
+		}},
+	}
+	tests := []struct {
+		name   string
+		result *azure.QueryResult
+	}{
+		{"no tables", &azure.QueryResult{}},
+		{"no rows", &azure.QueryResult{Tables: []azure.Table{{Columns: full.Tables[0].Columns}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, _ := NewModel("", azure.AuthDefault, azure.NewConfig()).Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+			m := model.(Model)
+			if out := m.renderMainView(); !strings.Contains(out, "No results yet") {
+				t.Errorf("Expected the not yet run message before any query, got %q", out)
+			}
+
+			m.processResults(full)
+			m.processResults(tt.result)
+			if m.table.RowCount() != 0 {
+				t.Errorf("Expected the previous result to be replaced, got %d rows", m.table.RowCount())
+			}
+			out := m.renderMainView()
+			if strings.Contains(out, "No results yet") || !strings.Contains(out, "returned 0 rows") {
+				t.Errorf("Expected the 0 rows message, got %q", out)
+			}
+			if status := m.renderStatusBar(); !strings.Contains(status, "0 rows in") {
+				t.Errorf("Expected 0 rows in the status bar, got %q", status)
+			}
+		})
+	}
+}
+
+func TestReplacesQuery(t *testing.T) {
+	tests := []struct {
+		current, suggestion string
+		expected            bool
+	}{
+		{"", "SigninLogs | take 10", false},
+		{"Signin", "SigninLogs | take 10", false},
+		{"SigninLogs | where", "SigninLogs | where ResultType != 0", false},
+		{"SigninLogs | where UserId == 'x'", "AuditLogs | take 10", true},
+	}
+
+	for _, tt := range tests {
+		if got := replacesQuery(tt.current, tt.suggestion); got != tt.expected {
+			t.Errorf("replacesQuery(%q, %q): expected %v, got %v", tt.current, tt.suggestion, tt.expected, got)
+		}
+	}
+}
+
+func TestModel_ConfirmReplaceSuggestion(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	m := NewModel("", azure.AuthDefault, azure.NewConfig())
+	m.editor.SetValue("SigninLogs | where UserId == 'x'")
+	m.suggestion = "AuditLogs | take 10"
+
+	model, _ := m.updateQueryView(tea.KeyMsg{Type: tea.KeyTab})
+	m = model.(Model)
+	if !m.confirmReplace || m.editor.Value() != "SigninLogs | where UserId == 'x'" {
+		t.Fatalf("Expected a confirmation before replacing the query, got %q", m.editor.Value())
+	}
+
+	model, _ = m.updateQueryView(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = model.(Model)
+	if m.editor.Value() != "AuditLogs | take 10" || m.suggestion != "" {
+		t.Fatalf("Expected the suggestion to replace the query, got %q", m.editor.Value())
+	}
+
+	m.editor.Undo()
+	if m.editor.Value() != "SigninLogs | where UserId == 'x'" {
+		t.Errorf("Expected undo to restore the query, got %q", m.editor.Value())
+	}
+}
+
+func TestModel_HistoryPaging(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	model, _ := NewModel("", azure.AuthDefault, azure.NewConfig()).Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	m := model.(Model)
+	for i := 0; i < 25; i++ {
+		m.lastQuery = fmt.Sprintf("T%d", i)
+		m.addToHistory(true, "")
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyF2})
+	m = model.(Model)
+	m.lastQuery = "Latest"
+	m.addToHistory(true, "")
+	if len(m.historyList) != 26 || m.historyList[0].Query != "Latest" {
+		t.Fatalf("Expected the new entry prepended to the cached list, got %d entries", len(m.historyList))
+	}
+
+	model, _ = m.updateHistoryView(tea.KeyMsg{Type: tea.KeyPgDown})
+	m = model.(Model)
+	if m.historyIndex != m.historyPageSize() {
+		t.Errorf("Expected PgDown to move a page, got index %d", m.historyIndex)
+	}
+	out := m.renderHistoryView()
+	if !strings.Contains(out, "Page 2 of") || strings.Contains(out, "Latest") {
+		t.Errorf("Expected only the second page, got %q", out)
+	}
+}
+
+func TestModel_StartupQuery(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	query := "AzureActivity | where TimeGenerated > ago(1h) | where Level == 'Error'"
+	tests := []struct {
+		name     string
+		disabled bool
+		expected string
+	}{
+		{"runs once connected", false, query},
+		{"--no-startup-query", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := azure.NewConfig()
+			config.StartupQuery = query
+			config.NoStartupQuery = tt.disabled
+
+			model, _ := NewModel("ws", azure.AuthDefault, config).Update(connectMsg{})
+			m := model.(Model)
+			if m.editor.Value() != tt.expected || m.loading != !tt.disabled {
+				t.Errorf("Expected %q in the editor (running %v), got %q (running %v)", tt.expected, !tt.disabled, m.editor.Value(), m.loading)
+			}
+
+			// Reconnecting later doesn't run it again
+			m.loading = false
+			model, _ = m.Update(connectMsg{})
+			if model.(Model).loading {
+				t.Error("Expected the startup query to run only once")
+			}
+		})
+	}
+}
+
+func TestModel_PasteSkipsAutocomplete(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	m := NewModel("", azure.AuthDefault, azure.NewConfig())
+	m.autocompleteEngine.SetTables([]string{"SigninLogs"})
+
+	model, _ := m.updateQueryView(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Sig")})
+	m = model.(Model)
+	if m.editor.Value() != "Sig" {
+		t.Fatalf("Expected the pasted text in the editor, got %q", m.editor.Value())
+	}
+	if m.suggestionPopup.IsVisible() || !m.pasted {
+		t.Error("Expected autocomplete to wait for the paste to settle")
+	}
+
+	model, _ = m.Update(debounceMsg{tag: m.suggestionDebounceTag})
+	m = model.(Model)
+	if !m.suggestionPopup.IsVisible() || m.pasted {
+		t.Error("Expected suggestions once the paste settled")
+	}
+}
+
+func TestModel_Autosave(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	m := NewModel("", azure.AuthDefault, azure.NewConfig())
+	m.config.TimeRange = "6h"
+	m.lastQuery = "SigninLogs | take 5"
+	m.addToHistory(true, "")
+
+	model, cmd := m.Update(autosaveTickMsg{})
+	if cmd == nil {
+		t.Error("Expected the next auto-save to be scheduled")
+	}
+	if m = model.(Model); m.lastError != "" {
+		t.Fatalf("Expected auto-save to succeed, got %q", m.lastError)
+	}
+
+	config := azure.NewConfig()
+	if err := config.Load(); err != nil || config.TimeRange != "6h" {
+		t.Errorf("Expected the config saved, got %q (%v)", config.TimeRange, err)
+	}
+	history := azure.NewHistory(10)
+	if err := history.Load(); err != nil || len(history.Entries) != 1 {
+		t.Errorf("Expected the history saved, got %d entries (%v)", len(history.Entries), err)
+	}
+}
+
+func TestModel_AutosaveSkipsUnchangedConfig(t *testing.T) {
+	dir := t.TempDir()
+	azure.SetConfigDir(dir)
+	defer azure.SetConfigDir("")
+
+	m := NewModel("", azure.AuthDefault, azure.NewConfig())
+	m.config.TimeRange = "6h"
+	m.autosave()
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.Remove(configPath); err != nil {
+		t.Fatalf("Expected the changed config saved: %v", err)
+	}
+
+	m.autosave()
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Error("Expected the unchanged config not to be saved again")
+	}
+
+	m.config.TimeRange = "12h"
+	m.autosave()
+	if _, err := os.Stat(configPath); err != nil {
+		t.Errorf("Expected the changed config saved again: %v", err)
+	}
+}
+
+func TestModel_ColumnWidthsPerTable(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	result := &azure.QueryResult{Tables: []azure.Table{{
+		Columns: []azure.Column{{Name: "Message", Type: "string"}},
+		Rows:    [][]interface{}{{strings.Repeat("x", 100)}},
+	}}}
+	model, _ := NewModel("", azure.AuthDefault, azure.NewConfig()).Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	m := model.(Model)
+	m.lastQuery = "AppTraces | take 10"
+	m.processResults(result)
+	m.currentView = ViewResults
+	m.table.Focus()
+
+	model, _ = m.updateResultsView(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(">")})
+	m = model.(Model)
+	if got := m.config.ColumnWidths["AppTraces"]["Message"]; got != 45 {
+		t.Fatalf("Expected Message's width saved for AppTraces as 45, got %d", got)
+	}
+
+	m.lastQuery = "AppTraces | where SeverityLevel > 2"
+	m.processResults(result)
+	if got := m.table.calculateColumnWidths()[0]; got != 45 {
+		t.Errorf("Expected the saved width applied to the next AppTraces result, got %d", got)
+	}
+	m.lastQuery = "AppRequests | take 10"
+	m.processResults(result)
+	if got := m.table.calculateColumnWidths()[0]; got != 40 {
+		t.Errorf("Expected the default width for another table, got %d", got)
+	}
+}
+
+func TestModel_WorkspaceSwitchDropsSchemas(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	columns := []azure.Column{{Name: "UserPrincipalName", Type: "string"}}
+	model, _ := NewModel("ws-a", azure.AuthDefault, azure.NewConfig()).Update(tablesMsg{workspace: "ws-a", tables: []string{"SigninLogs"}})
+	model, _ = model.Update(schemaMsg{workspace: "ws-a", tableName: "SigninLogs", columns: columns})
+	m := model.(Model)
+	if len(m.availableTables) != 1 || len(m.schemaCache["SigninLogs"]) != 1 {
+		t.Fatalf("Expected the first workspace's tables and schema, got %v and %v", m.availableTables, m.schemaCache)
+	}
+
+	m.currentView = ViewWorkspace
+	m.workspaceInput.SetValue("ws-b")
+	model, _ = m.updateWorkspaceView(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(Model)
+	if m.availableTables != nil || len(m.schemaCache) != 0 {
+		t.Fatalf("Expected the previous workspace's tables and schemas cleared, got %v and %v", m.availableTables, m.schemaCache)
+	}
+
+	// Fetches started for the previous workspace land late
+	model, _ = m.Update(tablesMsg{workspace: "ws-a", tables: []string{"SigninLogs"}})
+	model, _ = model.Update(schemaMsg{workspace: "ws-a", tableName: "SigninLogs", columns: columns})
+	model, _ = model.Update(ingestionMsg{workspace: "ws-a", rates: map[string]float64{"SigninLogs": 2}})
+	m = model.(Model)
+	if m.availableTables != nil || len(m.schemaCache) != 0 || m.ingestionRates != nil {
+		t.Errorf("Expected results for the previous workspace dropped, got %v, %v and %v", m.availableTables, m.schemaCache, m.ingestionRates)
+	}
+
+	model, _ = m.Update(ingestionMsg{workspace: "ws-b", rates: map[string]float64{"AppTraces": 1}})
+	if rates := model.(Model).ingestionRates; rates["AppTraces"] != 1 {
+		t.Errorf("Expected the current workspace's ingestion rates, got %v", rates)
+	}
+}
+
+func TestModel_HistoryCopyAndAppend(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	m := NewModel("", azure.AuthDefault, azure.NewConfig())
+	m.lastQuery = "AuditLogs | take 5"
+	m.addToHistory(true, "")
+	m.editor.SetValue("// failures\nSigninLogs | where UserId == 'x'\n")
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyF2})
+	m = model.(Model)
+
+	model, cmd := m.updateHistoryView(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = model.(Model)
+	if cmd == nil || m.currentView != ViewHistory || m.editor.Value() != "// failures\nSigninLogs | where UserId == 'x'\n" {
+		t.Fatalf("Expected a copy leaving the editor alone, got %q in view %d", m.editor.Value(), m.currentView)
+	}
+
+	model, _ = m.updateHistoryView(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = model.(Model)
+	expected := "// failures\n// SigninLogs | where UserId == 'x'\n\nAuditLogs | take 5"
+	if m.editor.Value() != expected || m.currentView != ViewQuery {
+		t.Errorf("Expected the entry appended after the commented-out query, got %q", m.editor.Value())
+	}
+	if got := ensureQueryLimit(StripKQLComments(m.editor.Value()), 100); strings.TrimSpace(got) != "AuditLogs | take 5" {
+		t.Errorf("Expected only the appended entry to run, got %q", got)
+	}
+}
+
+func TestModel_DuplicateColumns(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	m := NewModel("", azure.AuthDefault, azure.NewConfig())
+	result := &azure.QueryResult{RowCount: 1, Tables: []azure.Table{{
+		Columns: []azure.Column{{Name: "Name", Type: "string"}, {Name: "Name", Type: "string"}},
+		Rows:    [][]interface{}{{"web-01", "alice"}},
+	}}}
+	m.processResults(result)
+
+	if columns := m.table.GetColumns(); len(columns) != 2 || columns[1] != "Name_1" {
+		t.Errorf("Expected the second Name column renamed, got %v", columns)
+	}
+	if result.Tables[0].Columns[1].Name != "Name" {
+		t.Errorf("Expected the result, which the cache may share, left as is, got %q", result.Tables[0].Columns[1].Name)
+	}
+	if name := m.exportResult().Tables[0].Columns[1].Name; name != "Name_1" {
+		t.Errorf("Expected the renamed column in the result saved by exports, got %q", name)
+	}
+	if name := m.resultColumns[1].Name; name != "Name_1" {
+		t.Errorf("Expected the renamed column in row details, got %q", name)
+	}
+	if !strings.Contains(m.notice, "Name_1") {
+		t.Errorf("Expected a notice about the renamed column, got %q", m.notice)
+	}
+}
+
+func TestModel_NoTimeRangeAgainstCluster(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	config := azure.NewConfig()
+	config.TimeRange = "1h"
+	config.Cluster, config.Database = "help", "Samples"
+	model, _ := NewModel("", azure.AuthDefault, config).Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := model.(Model)
+
+	if got := m.activeTimeRange(); got != "" {
+		t.Errorf("Expected no time range against a cluster, got %q", got)
+	}
+	if strings.Contains(m.renderStatusBar(), "Range:") {
+		t.Error("Expected the status bar not to show a time range against a cluster")
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyF7})
+	m = model.(Model)
+	if m.currentView == ViewTimeRange || !strings.Contains(m.lastError, "Data Explorer") {
+		t.Errorf("Expected F7 to explain that time ranges don't apply, got view %v and error %q", m.currentView, m.lastError)
+	}
+}
+
+func TestModel_FreshnessTickStopsWithoutResult(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	m := NewModel("", azure.AuthDefault, azure.NewConfig())
+	if _, cmd := m.Update(freshnessTickMsg{}); cmd != nil {
+		t.Error("Expected no further ticks without a result")
+	}
+
+	m.resultAsOf = time.Now()
+	model, cmd := m.Update(freshnessTickMsg{})
+	if cmd == nil {
+		t.Fatal("Expected ticks to continue while a result is shown")
+	}
+	m = model.(Model)
+
+	m.loading = true
+	if _, cmd := m.Update(freshnessTickMsg{}); cmd != nil {
+		t.Error("Expected ticks to stop while a query is running")
+	}
+}
+
+func TestModel_BookmarksView(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	config := azure.NewConfig()
+	config.KeyBindings = map[string][]string{"newTemplate": {"N"}}
+	model, _ := NewModel("", azure.AuthDefault, config).Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := model.(Model)
+	for i := 0; i < 12; i++ {
+		m.bookmarks.Add(fmt.Sprintf("T%d | take 10", i), "", "")
+	}
+	m.editor.SetValue("AzureActivity | take 10")
+	m.openBookmarksView()
+
+	view := m.renderBookmarksView()
+	if strings.Count(view, "| take 10") != 10 || !strings.Contains(view, "... and 2 more") {
+		t.Errorf("Expected 10 bookmarks and 2 more, got:\n%s", view)
+	}
+
+	// Bookmarks have their own binding, unaffected by rebinding templates
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if !model.(Model).addingBookmark {
+		t.Error("Expected n to start bookmarking the current query")
+	}
+}
+
+// schemaErrorClient fails every schema fetch
+type schemaErrorClient struct {
+	azure.QueryClient
+}
+
+func (schemaErrorClient) GetTableSchema(context.Context, string) ([]azure.Column, error) {
+	return nil, errors.New("table not found")
+}
+
+func TestModel_FailedSchemaFetchBacksOff(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	m := NewModel("ws-a", azure.AuthDefault, azure.NewConfig())
+	m.client = schemaErrorClient{}
+
+	cmd := m.fetchSchema("Missing")
+	if cmd == nil {
+		t.Fatal("Expected the schema to be fetched")
+	}
+	model, _ := m.Update(cmd())
+	m = model.(Model)
+	if m.schemaFailures["Missing"].err == nil {
+		t.Fatal("Expected the failure to be remembered")
+	}
+	if m.fetchSchema("Missing") != nil {
+		t.Error("Expected a recently failed schema not to be fetched again")
+	}
+
+	m.schemaFailures["Missing"] = fetchFailure{err: errors.New("table not found"), at: time.Now().Add(-2 * fetchRetryAfter)}
+	if m.fetchSchema("Missing") == nil {
+		t.Error("Expected the schema to be retried once the failure is old")
+	}
+}
+
+func TestModel_RecentTablesReplaceCurrentWord(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	model, _ := NewModel("ws-1", azure.AuthDefault, azure.NewConfig()).Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := model.(Model)
+	m.recentTables.Add("ws-1", []string{"SigninLogs", "AppTraces"})
+	altT := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t"), Alt: true}
+
+	m.editor.SetValue("AppTraces | take 10\n| union Sig")
+	model, _ = m.updateQueryView(altT)
+	m = model.(Model)
+	if selected := m.suggestionPopup.Selected(); selected == nil || selected.Text != "SigninLogs" || len(m.suggestionPopup.suggestions) != 1 {
+		t.Fatalf("Expected only SigninLogs to match the word at the cursor, got %+v", m.suggestionPopup.suggestions)
+	}
+	model, _ = m.updateQueryView(tea.KeyMsg{Type: tea.KeyTab})
+	m = model.(Model)
+	if got := m.editor.Value(); got != "AppTraces | take 10\n| union SigninLogs" {
+		t.Errorf("Expected the typed word replaced by the table, got %q", got)
+	}
+
+	m.editor.SetValue("AppTraces | take 10")
+	model, _ = m.updateQueryView(altT)
+	m = model.(Model)
+	if m.suggestionPopup.IsVisible() || !strings.Contains(m.notice, `"10"`) {
+		t.Errorf("Expected no tables offered for the word 10, got notice %q", m.notice)
+	}
+}
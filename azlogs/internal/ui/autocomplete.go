@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 	"unicode"
@@ -12,12 +13,14 @@ import (
 type ContextType int
 
 const (
-	ContextUnknown ContextType = iota
-	ContextTableName            // Start of query, after union/join
-	ContextOperator             // After | pipe
-	ContextColumnName           // After where, project, by, etc.
-	ContextFunction             // In summarize, expecting aggregation
-	ContextValue                // After ==, in string literal (no suggestions)
+	ContextUnknown    ContextType = iota
+	ContextTableName              // Start of query, after union/join
+	ContextOperator               // After | pipe
+	ContextColumnName             // After where, project, by, etc.
+	ContextFunction               // In summarize, expecting aggregation
+	ContextValue                  // After ==, in string literal (no suggestions)
+	ContextComparison             // After a column in a where clause
+	ContextFunctionArgs           // Inside a function's argument list (signature hint only)
 )
 
 // ParsedContext contains information about the cursor position and what's expected
@@ -28,6 +31,7 @@ type ParsedContext struct {
 	WordStartPos     int      // Position where current word starts
 	ReferencedTables []string // All tables referenced in query
 	AfterKeyword     string   // The keyword before current position (e.g., "where", "project")
+	FunctionName     string   // Function whose arguments are being typed
 }
 
 // Suggestion represents an autocomplete suggestion
@@ -70,10 +74,51 @@ var kqlTimeFunctions = []string{
 	"bin(", "format_datetime(",
 }
 
+// Argument signatures for the functions above, shown while typing arguments
+var kqlSignatures = map[string]string{
+	"count":           "count()",
+	"sum":             "sum(Expr)",
+	"avg":             "avg(Expr)",
+	"min":             "min(Expr)",
+	"max":             "max(Expr)",
+	"dcount":          "dcount(Expr [, Accuracy])",
+	"percentile":      "percentile(Expr, Percentile)",
+	"stdev":           "stdev(Expr)",
+	"variance":        "variance(Expr)",
+	"countif":         "countif(Predicate)",
+	"sumif":           "sumif(Expr, Predicate)",
+	"avgif":           "avgif(Expr, Predicate)",
+	"minif":           "minif(Expr, Predicate)",
+	"maxif":           "maxif(Expr, Predicate)",
+	"make_list":       "make_list(Expr [, MaxSize])",
+	"make_set":        "make_set(Expr [, MaxSize])",
+	"arg_max":         "arg_max(ExprToMaximize, * | ExprToReturn [, ...])",
+	"arg_min":         "arg_min(ExprToMinimize, * | ExprToReturn [, ...])",
+	"ago":             "ago(Timespan)",
+	"now":             "now([Offset])",
+	"datetime":        "datetime(Value)",
+	"timespan":        "timespan(Value)",
+	"startofday":      "startofday(Date [, Offset])",
+	"startofweek":     "startofweek(Date [, Offset])",
+	"startofmonth":    "startofmonth(Date [, Offset])",
+	"endofday":        "endofday(Date [, Offset])",
+	"endofweek":       "endofweek(Date [, Offset])",
+	"endofmonth":      "endofmonth(Date [, Offset])",
+	"bin":             "bin(Value, RoundTo)",
+	"format_datetime": "format_datetime(Datetime, Format)",
+}
+
+// Column usage boosts: each recorded use adds usageBoostPerUse, up to maxUsageBoost
+const (
+	usageBoostPerUse = 5
+	maxUsageBoost    = 90
+)
+
 // AutocompleteEngine provides instant local autocomplete suggestions
 type AutocompleteEngine struct {
 	tables  []string
 	schemas map[string][]azure.Column
+	usage   *azure.ColumnUsage
 }
 
 // NewAutocompleteEngine creates a new autocomplete engine
@@ -93,6 +138,35 @@ func (e *AutocompleteEngine) SetSchemas(schemas map[string][]azure.Column) {
 	e.schemas = schemas
 }
 
+// MissingSchemas returns the tables that have no cached schema
+func (e *AutocompleteEngine) MissingSchemas(tables []string) []string {
+	var missing []string
+	for _, table := range tables {
+		if _, ok := e.schemas[table]; !ok {
+			missing = append(missing, table)
+		}
+	}
+	return missing
+}
+
+// SetUsage sets the column usage counts used to rank column suggestions
+func (e *AutocompleteEngine) SetUsage(usage *azure.ColumnUsage) {
+	e.usage = usage
+}
+
+// usageBoost returns the score boost for how often a column has been used
+// in its tables
+func (e *AutocompleteEngine) usageBoost(col mergedColumn) int {
+	if e.usage == nil {
+		return 0
+	}
+	uses := 0
+	for _, table := range col.Tables {
+		uses += e.usage.Count(table, col.Name)
+	}
+	return min(uses*usageBoostPerUse, maxUsageBoost)
+}
+
 // ParseContext analyzes the query at cursor position to determine context
 func (e *AutocompleteEngine) ParseContext(query string, cursorPos int) ParsedContext {
 	ctx := ParsedContext{
@@ -106,10 +180,11 @@ func (e *AutocompleteEngine) ParseContext(query string, cursorPos int) ParsedCon
 
 	// Get text before cursor
 	beforeCursor := query[:cursorPos]
-	beforeCursor = strings.TrimRight(beforeCursor, " \t\n")
 
-	// Find current word being typed
-	ctx.CurrentWord, ctx.WordStartPos = e.findCurrentWord(beforeCursor)
+	// Find current word being typed; after whitespace a new word is starting
+	if strings.TrimRight(beforeCursor, " \t\n") == beforeCursor {
+		ctx.CurrentWord, ctx.WordStartPos = e.findCurrentWord(beforeCursor)
+	}
 
 	// Find referenced tables
 	ctx.ReferencedTables = e.findReferencedTables(query)
@@ -120,6 +195,14 @@ func (e *AutocompleteEngine) ParseContext(query string, cursorPos int) ParsedCon
 	// Determine context type
 	ctx.Type, ctx.AfterKeyword = e.determineContextType(beforeCursor)
 
+	// Inside a known function's arguments only its signature is shown
+	if !inStringLiteral(beforeCursor) {
+		if fn := enclosingFunction(beforeCursor); kqlSignatures[fn] != "" {
+			ctx.Type = ContextFunctionArgs
+			ctx.FunctionName = fn
+		}
+	}
+
 	return ctx
 }
 
@@ -194,13 +277,15 @@ func (e *AutocompleteEngine) determineContextType(beforeCursor string) (ContextT
 	}
 
 	// Check if last non-word char is pipe with space
-	lastPipe := strings.LastIndex(trimmed, "|")
+	lastPipe := strings.LastIndex(beforeCursor, "|")
 	if lastPipe != -1 {
-		afterPipe := strings.TrimSpace(trimmed[lastPipe+1:])
+		// Trailing whitespace is kept: it separates a finished word from one
+		// still being typed
+		afterPipe := strings.TrimLeft(beforeCursor[lastPipe+1:], " \t\n")
 		afterPipeLower := strings.ToLower(afterPipe)
 
 		// Just after pipe, might be typing operator
-		if len(afterPipe) == 0 || !strings.Contains(afterPipe, " ") {
+		if len(afterPipe) == 0 || !strings.ContainsAny(afterPipe, " \t\n") {
 			return ContextOperator, ""
 		}
 
@@ -211,11 +296,8 @@ func (e *AutocompleteEngine) determineContextType(beforeCursor string) (ContextT
 				// Find the keyword
 				idx := strings.LastIndex(afterPipeLower, kw)
 				if idx != -1 {
-					afterKw := afterPipe[idx+len(kw):]
-					// If there's content after keyword and no operator yet
-					if len(strings.TrimSpace(afterKw)) >= 0 {
-						return ContextColumnName, strings.TrimSpace(kw)
-					}
+					keyword := strings.TrimSpace(kw)
+					return clauseContext(keyword, afterPipe[idx+len(kw):]), keyword
 				}
 			}
 		}
@@ -240,6 +322,171 @@ func (e *AutocompleteEngine) determineContextType(beforeCursor string) (ContextT
 	return ContextUnknown, ""
 }
 
+// enclosingFunction returns the lowercased name of the function whose
+// argument list is still open at the end of text, or "" if there is none
+func enclosingFunction(text string) string {
+	depth := 0
+	for i := len(text) - 1; i >= 0; i-- {
+		switch text[i] {
+		case ')':
+			depth++
+		case '(':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			end := i
+			start := end
+			for start > 0 && isAlphaNum(text[start-1]) {
+				start--
+			}
+			return strings.ToLower(text[start:end])
+		case '|':
+			return ""
+		}
+	}
+	return ""
+}
+
+// Signature returns the argument signature to show for the context, if any
+func (e *AutocompleteEngine) Signature(ctx ParsedContext) string {
+	if ctx.Type != ContextFunctionArgs {
+		return ""
+	}
+	return kqlSignatures[ctx.FunctionName]
+}
+
+// clauseContext looks at the expression being typed after a column keyword.
+// Once a comparison has been written, or inside a string literal or an
+// in (...) list, a value is expected and column names would be noise.
+func clauseContext(keyword, clause string) ContextType {
+	if inStringLiteral(clause) || inValueList(clause) {
+		return ContextValue
+	}
+
+	expr := currentExpression(clause)
+	switch keyword {
+	case "where":
+		if hasComparison(expr) {
+			return ContextValue
+		}
+		// A completed column name is followed by a comparison operator
+		fields := strings.Fields(expr)
+		typing := strings.TrimRight(expr, " \t\n") == expr
+		if (len(fields) == 1 && !typing) || (len(fields) == 2 && typing) {
+			return ContextComparison
+		}
+	case "project", "extend":
+		// In "Alias = expression" the expression is built from columns
+		if _, rhs, ok := strings.Cut(expr, "="); ok {
+			if hasComparison(rhs) {
+				return ContextValue
+			}
+			return ContextColumnName
+		}
+		// A bare word after extend is the name of a new column
+		if keyword == "extend" && strings.TrimSpace(expr) != "" {
+			return ContextValue
+		}
+	}
+
+	return ContextColumnName
+}
+
+// currentExpression returns the part of a clause after the last comma,
+// opening parenthesis or logical operator
+func currentExpression(clause string) string {
+	start := strings.LastIndexAny(clause, ",(") + 1
+	lower := strings.ToLower(clause)
+	for _, sep := range []string{" and ", " or ", " not "} {
+		if idx := strings.LastIndex(lower, sep); idx != -1 && idx+len(sep) > start {
+			start = idx + len(sep)
+		}
+	}
+	return clause[start:]
+}
+
+// comparisonWords are the word comparison operators, without their negated
+// (!) and case-sensitive (_cs, ~) variants
+var comparisonWords = map[string]bool{
+	"contains": true, "has": true, "hasprefix": true, "hassuffix": true,
+	"startswith": true, "endswith": true, "matches": true, "in": true,
+	"between": true, "has_any": true, "has_all": true, "like": true,
+}
+
+// hasComparison reports whether an expression contains a completed
+// comparison operator
+func hasComparison(expr string) bool {
+	if strings.ContainsAny(expr, "=<>") {
+		return true
+	}
+
+	fields := strings.Fields(strings.ToLower(expr))
+	// A word at the end of the expression may still be being typed
+	if len(fields) > 0 && strings.TrimRight(expr, " \t\n") == expr {
+		fields = fields[:len(fields)-1]
+	}
+	for _, f := range fields {
+		f = strings.TrimPrefix(f, "!")
+		f = strings.TrimSuffix(strings.TrimSuffix(f, "~"), "_cs")
+		if comparisonWords[f] {
+			return true
+		}
+	}
+	return false
+}
+
+// inStringLiteral reports whether text ends inside an unterminated string
+func inStringLiteral(text string) bool {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		}
+	}
+	return quote != 0
+}
+
+// inValueList reports whether text ends inside the parentheses of an
+// in/has_any style list
+func inValueList(text string) bool {
+	depth := 0
+	open := -1
+	for i := len(text) - 1; i >= 0; i-- {
+		switch text[i] {
+		case ')':
+			depth++
+		case '(':
+			if depth == 0 {
+				open = i
+			} else {
+				depth--
+			}
+		}
+		if open != -1 {
+			break
+		}
+	}
+	if open == -1 {
+		return false
+	}
+
+	fields := strings.Fields(strings.ToLower(text[:open]))
+	if len(fields) == 0 {
+		return false
+	}
+	op := strings.TrimSuffix(strings.TrimPrefix(fields[len(fields)-1], "!"), "~")
+	return op == "in" || op == "has_any" || op == "has_all"
+}
+
 // GetSuggestions returns suggestions based on context
 func (e *AutocompleteEngine) GetSuggestions(ctx ParsedContext, limit int) []Suggestion {
 	var suggestions []Suggestion
@@ -250,13 +497,21 @@ func (e *AutocompleteEngine) GetSuggestions(ctx ParsedContext, limit int) []Sugg
 	case ContextOperator:
 		suggestions = e.getOperatorSuggestions(ctx.CurrentWord)
 	case ContextColumnName:
-		suggestions = e.getColumnSuggestions(ctx.CurrentTable, ctx.CurrentWord)
+		suggestions = e.getColumnSuggestions(ctx.ReferencedTables, ctx.CurrentWord)
 	case ContextFunction:
 		suggestions = e.getFunctionSuggestions(ctx.CurrentWord)
+	case ContextComparison:
+		suggestions = e.getComparisonSuggestions(ctx.CurrentWord)
+	case ContextValue, ContextFunctionArgs:
+		// Values are free-form; function arguments get a signature hint instead
 	default:
+		// Only offer mixed suggestions once a word is being typed
+		if ctx.CurrentWord == "" {
+			break
+		}
 		// Mixed suggestions
 		suggestions = append(suggestions, e.getOperatorSuggestions(ctx.CurrentWord)...)
-		suggestions = append(suggestions, e.getColumnSuggestions(ctx.CurrentTable, ctx.CurrentWord)...)
+		suggestions = append(suggestions, e.getColumnSuggestions(ctx.ReferencedTables, ctx.CurrentWord)...)
 	}
 
 	// Sort by score descending
@@ -292,6 +547,20 @@ func (e *AutocompleteEngine) getTableSuggestions(prefix string) []Suggestion {
 		}
 	}
 
+	// Fall back to fuzzy matches so a near miss still gets suggestions
+	if len(suggestions) == 0 {
+		for _, table := range e.tables {
+			if score, ok := fuzzyScore(table, prefix); ok {
+				suggestions = append(suggestions, Suggestion{
+					Text:        table,
+					Type:        "table",
+					Description: "Table",
+					Score:       score,
+				})
+			}
+		}
+	}
+
 	return suggestions
 }
 
@@ -321,12 +590,12 @@ func (e *AutocompleteEngine) getOperatorSuggestions(prefix string) []Suggestion
 	return suggestions
 }
 
-func (e *AutocompleteEngine) getColumnSuggestions(tableName, prefix string) []Suggestion {
+func (e *AutocompleteEngine) getColumnSuggestions(tables []string, prefix string) []Suggestion {
 	var suggestions []Suggestion
 	prefixLower := strings.ToLower(prefix)
 
-	columns, ok := e.schemas[tableName]
-	if !ok {
+	columns := e.mergedColumns(tables)
+	if len(columns) == 0 {
 		return suggestions
 	}
 
@@ -341,18 +610,69 @@ func (e *AutocompleteEngine) getColumnSuggestions(tableName, prefix string) []Su
 			if col.Name == "TimeGenerated" || col.Name == "ResourceId" || col.Name == "OperationName" {
 				score += 50
 			}
+			// Boost the user's frequently used columns
+			score += e.usageBoost(col)
 			suggestions = append(suggestions, Suggestion{
 				Text:        col.Name,
 				Type:        "column",
-				Description: col.Type,
+				Description: col.description(len(tables) > 1),
 				Score:       score,
 			})
 		}
 	}
 
+	// Fall back to fuzzy matches so a near miss still gets suggestions
+	if len(suggestions) == 0 {
+		for _, col := range columns {
+			if score, ok := fuzzyScore(col.Name, prefix); ok {
+				suggestions = append(suggestions, Suggestion{
+					Text:        col.Name,
+					Type:        "column",
+					Description: col.description(len(tables) > 1),
+					Score:       score + e.usageBoost(col),
+				})
+			}
+		}
+	}
+
 	return suggestions
 }
 
+// mergedColumn is a column name found in one or more referenced tables
+type mergedColumn struct {
+	azure.Column
+	Tables []string
+}
+
+// description returns the column type, tagged with its source tables when
+// the query references more than one
+func (c mergedColumn) description(tagTables bool) string {
+	if !tagTables {
+		return c.Type
+	}
+	return fmt.Sprintf("%s (%s)", c.Type, strings.Join(c.Tables, ", "))
+}
+
+// mergedColumns returns the columns of all the given tables, listing a
+// column shared by several tables once
+func (e *AutocompleteEngine) mergedColumns(tables []string) []mergedColumn {
+	var merged []mergedColumn
+	index := make(map[string]int)
+
+	for _, table := range tables {
+		for _, col := range e.schemas[table] {
+			if i, ok := index[col.Name]; ok {
+				merged[i].Tables = append(merged[i].Tables, table)
+				continue
+			}
+			index[col.Name] = len(merged)
+			merged = append(merged, mergedColumn{Column: col, Tables: []string{table}})
+		}
+	}
+
+	return merged
+}
+
 func (e *AutocompleteEngine) getFunctionSuggestions(prefix string) []Suggestion {
 	var suggestions []Suggestion
 	prefixLower := strings.ToLower(prefix)
@@ -378,3 +698,32 @@ func (e *AutocompleteEngine) getFunctionSuggestions(prefix string) []Suggestion
 
 	return suggestions
 }
+
+func (e *AutocompleteEngine) getComparisonSuggestions(prefix string) []Suggestion {
+	var suggestions []Suggestion
+	prefixLower := strings.ToLower(prefix)
+
+	for i, op := range kqlComparisons {
+		if !strings.HasPrefix(op, prefixLower) {
+			continue
+		}
+		// Keep the list order, which puts the common comparisons first
+		score := 100 - i
+		if op == prefixLower {
+			score = 200
+		}
+		description := "Comparison"
+		if op == "and" || op == "or" || op == "not" {
+			description = "Logical"
+			score -= 50
+		}
+		suggestions = append(suggestions, Suggestion{
+			Text:        op,
+			Type:        "operator",
+			Description: description,
+			Score:       score,
+		})
+	}
+
+	return suggestions
+}
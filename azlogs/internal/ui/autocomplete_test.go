@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestAutocompleteEngine_ParseContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected ContextType
+		word     string
+	}{
+		{"start of query", "Sig", ContextTableName, "Sig"},
+		{"after pipe", "T | ", ContextOperator, ""},
+		{"typing operator", "T | wh", ContextOperator, "wh"},
+		{"where column", "T | where Res", ContextColumnName, "Res"},
+		{"where after keyword", "T | where ", ContextColumnName, ""},
+		{"where after column", "T | where Foo ", ContextComparison, ""},
+		{"where typing comparison", "T | where Foo sta", ContextComparison, "sta"},
+		{"where after comparison", "T | where Foo == ", ContextValue, ""},
+		{"where after comparison without space", "T | where Foo==", ContextValue, ""},
+		{"where after word comparison", "T | where Foo has ", ContextValue, ""},
+		{"where negated comparison", "T | where Foo !contains_cs ", ContextValue, ""},
+		{"where in string literal", `T | where Foo == "ab`, ContextValue, "ab"},
+		{"where in value list", `T | where Foo in ("a", `, ContextValue, ""},
+		{"where second condition", "T | where Foo == 1 and Ba", ContextColumnName, "Ba"},
+		{"where second condition column", "T | where Foo == 1 and Bar ", ContextComparison, ""},
+		{"project column", "T | project Foo, Ba", ContextColumnName, "Ba"},
+		{"project alias expression", "T | project X = ", ContextColumnName, ""},
+		{"extend new column name", "T | extend Dur", ContextValue, "Dur"},
+		{"extend expression", "T | extend Dur = End", ContextColumnName, "End"},
+		{"extend comparison", "T | extend IsErr = Code != ", ContextValue, ""},
+		{"summarize by", "T | summarize count() by Co", ContextColumnName, "Co"},
+	}
+
+	e := NewAutocompleteEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := e.ParseContext(tt.query, len(tt.query))
+			if ctx.Type != tt.expected {
+				t.Errorf("Expected context %d, got %d", tt.expected, ctx.Type)
+			}
+			if ctx.CurrentWord != tt.word {
+				t.Errorf("Expected current word %q, got %q", tt.word, ctx.CurrentWord)
+			}
+		})
+	}
+}
+
+func TestAutocompleteEngine_NoSuggestionsForValues(t *testing.T) {
+	e := NewAutocompleteEngine()
+	e.SetTables([]string{"T"})
+
+	ctx := e.ParseContext("T | where Foo == ", len("T | where Foo == "))
+	if got := e.GetSuggestions(ctx, 10); len(got) != 0 {
+		t.Errorf("Expected no suggestions for a value, got %v", got)
+	}
+}
+
+func TestAutocompleteEngine_ComparisonSuggestions(t *testing.T) {
+	e := NewAutocompleteEngine()
+
+	query := "T | where Foo "
+	got := e.GetSuggestions(e.ParseContext(query, len(query)), 10)
+	if len(got) == 0 || got[0].Text != "==" {
+		t.Fatalf("Expected comparison suggestions starting with ==, got %v", got)
+	}
+	for _, s := range got {
+		if s.Type != "operator" {
+			t.Errorf("Expected only operator suggestions, got %v", s)
+		}
+	}
+
+	query = "T | where Foo sta"
+	got = e.GetSuggestions(e.ParseContext(query, len(query)), 10)
+	if len(got) != 1 || got[0].Text != "startswith" {
+		t.Errorf("Expected startswith, got %v", got)
+	}
+}
+
+func TestAutocompleteEngine_JoinColumns(t *testing.T) {
+	e := NewAutocompleteEngine()
+	e.SetTables([]string{"SigninLogs", "AuditLogs"})
+	e.SetSchemas(map[string][]azure.Column{
+		"SigninLogs": {{Name: "TimeGenerated", Type: "datetime"}, {Name: "UserId", Type: "string"}},
+		"AuditLogs":  {{Name: "TimeGenerated", Type: "datetime"}, {Name: "Result", Type: "string"}},
+	})
+
+	query := "SigninLogs | join AuditLogs on UserId | project "
+	got := e.GetSuggestions(e.ParseContext(query, len(query)), 10)
+
+	descriptions := map[string]string{}
+	for _, s := range got {
+		if _, dup := descriptions[s.Text]; dup {
+			t.Errorf("Expected %s to be suggested once", s.Text)
+		}
+		descriptions[s.Text] = s.Description
+	}
+
+	expected := map[string]string{
+		"TimeGenerated": "datetime (SigninLogs, AuditLogs)",
+		"UserId":        "string (SigninLogs)",
+		"Result":        "string (AuditLogs)",
+	}
+	for name, desc := range expected {
+		if descriptions[name] != desc {
+			t.Errorf("Expected %s described as %q, got %q", name, desc, descriptions[name])
+		}
+	}
+}
+
+func TestAutocompleteEngine_SingleTableColumnDescription(t *testing.T) {
+	e := NewAutocompleteEngine()
+	e.SetTables([]string{"SigninLogs"})
+	e.SetSchemas(map[string][]azure.Column{
+		"SigninLogs": {{Name: "UserId", Type: "string"}},
+	})
+
+	query := "SigninLogs | project Us"
+	got := e.GetSuggestions(e.ParseContext(query, len(query)), 10)
+	if len(got) != 1 || got[0].Description != "string" {
+		t.Errorf("Expected UserId described by its type only, got %v", got)
+	}
+}
+
+func TestAutocompleteEngine_FunctionSignature(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"T | summarize percentile(", "percentile(Expr, Percentile)"},
+		{"T | summarize percentile(Duration, ", "percentile(Expr, Percentile)"},
+		{"T | where TimeGenerated > ago(", "ago(Timespan)"},
+		{"T | summarize count() by bin(TimeGenerated, ", "bin(Value, RoundTo)"},
+		{"T | summarize percentile(Duration, 95)", ""},
+		{"T | where Name in (", ""},
+		{`T | where Name == "ago(`, ""},
+	}
+
+	e := NewAutocompleteEngine()
+	for _, tt := range tests {
+		ctx := e.ParseContext(tt.query, len(tt.query))
+		if got := e.Signature(ctx); got != tt.expected {
+			t.Errorf("Signature for %q: expected %q, got %q", tt.query, tt.expected, got)
+		}
+		if tt.expected != "" && len(e.GetSuggestions(ctx, 10)) != 0 {
+			t.Errorf("Expected no selectable suggestions inside %q", tt.query)
+		}
+	}
+}
+
+func TestSuggestionPopup_HintOnly(t *testing.T) {
+	p := NewSuggestionPopup()
+	p.SetSuggestions(nil)
+	p.SetHint("ago(Timespan)")
+
+	if p.IsVisible() {
+		t.Error("Expected a hint alone not to capture suggestion keys")
+	}
+	if !strings.Contains(p.View(), "ago(Timespan)") {
+		t.Errorf("Expected the hint to be rendered, got %q", p.View())
+	}
+
+	p.Hide()
+	if p.HasHint() {
+		t.Error("Expected Hide to clear the hint")
+	}
+}
+
+func TestAutocompleteEngine_UsageRanking(t *testing.T) {
+	e := NewAutocompleteEngine()
+	e.SetTables([]string{"SigninLogs"})
+	e.SetSchemas(map[string][]azure.Column{
+		"SigninLogs": {{Name: "ResultType", Type: "string"}, {Name: "ResultDescription", Type: "string"}},
+	})
+
+	usage := azure.NewColumnUsage()
+	for i := 0; i < 3; i++ {
+		usage.Record("SigninLogs", "ResultDescription")
+	}
+	e.SetUsage(usage)
+
+	query := "SigninLogs | project Res"
+	got := e.GetSuggestions(e.ParseContext(query, len(query)), 10)
+	if len(got) != 2 || got[0].Text != "ResultDescription" {
+		t.Errorf("Expected the frequently used ResultDescription first, got %v", got)
+	}
+}
+
+func TestContainsIdentifier(t *testing.T) {
+	tests := []struct {
+		text     string
+		name     string
+		expected bool
+	}{
+		{"T | where ResultType == 0", "ResultType", true},
+		{"T | where ResultTypeName == 0", "ResultType", false},
+		{"T | project XResultType, ResultType", "ResultType", true},
+		{"ResultType", "ResultType", true},
+		{"T | take 10", "ResultType", false},
+	}
+
+	for _, tt := range tests {
+		if got := containsIdentifier(tt.text, tt.name); got != tt.expected {
+			t.Errorf("containsIdentifier(%q, %q): expected %v, got %v", tt.text, tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestAutocompleteEngine_MissingSchemas(t *testing.T) {
+	e := NewAutocompleteEngine()
+	e.SetTables([]string{"SigninLogs", "AuditLogs", "Syslog"})
+	e.SetSchemas(map[string][]azure.Column{
+		"SigninLogs": {{Name: "UserId", Type: "string"}},
+	})
+
+	query := "SigninLogs | join AuditLogs on UserId"
+	ctx := e.ParseContext(query, len(query))
+	missing := e.MissingSchemas(ctx.ReferencedTables)
+	if len(missing) != 1 || missing[0] != "AuditLogs" {
+		t.Errorf("Expected only AuditLogs to be missing, got %v", missing)
+	}
+}
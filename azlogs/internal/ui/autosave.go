@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// autosaveTickMsg triggers the next periodic save
+type autosaveTickMsg struct{}
+
+// autosaveTick schedules the next periodic save, or nothing when auto-save
+// is disabled
+func autosaveTick(interval time.Duration) tea.Cmd {
+	if interval <= 0 {
+		return nil
+	}
+	return tea.Tick(interval, func(_ time.Time) tea.Msg {
+		return autosaveTickMsg{}
+	})
+}
+
+// saveState writes history, and config and templates if they changed since
+// they were last saved, to disk. It runs in Update, never in a command, so
+// saves can't overlap or race with changes
+func (m *Model) saveState() error {
+	return errors.Join(
+		m.history.Save(),
+		saveChanged(m.config, &m.savedConfig, m.config.Save),
+		saveChanged(m.templates, &m.savedTemplates, m.templates.Save),
+	)
+}
+
+// stateJSON returns the JSON of config or templates, to tell whether they
+// changed since the last save
+func stateJSON(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// saveChanged saves v unless its JSON matches saved, the JSON as last
+// saved, which is updated after a successful save
+func saveChanged(v interface{}, saved *[]byte, save func() error) error {
+	data := stateJSON(v)
+	if data != nil && bytes.Equal(data, *saved) {
+		return nil
+	}
+	if err := save(); err != nil {
+		return err
+	}
+	*saved = data
+	return nil
+}
+
+// autosave saves state so a crash or kill loses little of the session. A
+// failure is reported once rather than on every tick
+func (m *Model) autosave() {
+	err := m.saveState()
+	if err != nil && !m.autosaveFailed {
+		m.lastError = fmt.Sprintf("Auto-save failed: %v", err)
+		azure.Logger().Warn("auto-save failed", "error", err)
+	}
+	m.autosaveFailed = err != nil
+
+	// Saving merges in other sessions' history; show it unless the list is
+	// being browsed
+	if m.currentView != ViewHistory {
+		m.historyList = nil
+	}
+}
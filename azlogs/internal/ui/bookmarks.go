@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openBookmarksView shows the bookmarks list
+func (m *Model) openBookmarksView() {
+	m.bookmarkList = m.bookmarks.GetAll()
+	m.bookmarkIndex = 0
+	m.addingBookmark = false
+	m.currentView = ViewBookmarks
+}
+
+// startBookmark opens the bookmarks view prompting for a note on the current query
+func (m *Model) startBookmark() {
+	query := strings.TrimSpace(m.editor.Value())
+	if query == "" {
+		m.lastError = "Nothing to bookmark: the query is empty"
+		return
+	}
+
+	m.openBookmarksView()
+	m.addingBookmark = true
+	m.bookmarkInput.SetValue("")
+	m.bookmarkInput.Focus()
+}
+
+func (m Model) updateBookmarksView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Handle the note prompt for a new bookmark
+	if m.addingBookmark {
+		if key.Matches(msg, m.keys.Select) {
+			m.bookmarks.Add(strings.TrimSpace(m.editor.Value()), m.bookmarkInput.Value(), m.workspaceID)
+			if err := m.bookmarks.Save(); err != nil {
+				m.lastError = fmt.Sprintf("Failed to save bookmarks: %v", err)
+			}
+			m.bookmarkList = m.bookmarks.GetAll()
+			m.bookmarkIndex = 0
+			m.addingBookmark = false
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.bookmarkInput, cmd = m.bookmarkInput.Update(msg)
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Select):
+		if m.bookmarkIndex >= 0 && m.bookmarkIndex < len(m.bookmarkList) {
+			m.editor.SetValue(m.bookmarkList[m.bookmarkIndex].Query)
+			m.currentView = ViewQuery
+			m.editor.Focus()
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Delete):
+		if len(m.bookmarkList) > 0 && m.bookmarkIndex < len(m.bookmarkList) {
+			m.bookmarks.Delete(m.bookmarkList[m.bookmarkIndex].ID)
+			m.bookmarks.Save()
+			m.bookmarkList = m.bookmarks.GetAll()
+			if m.bookmarkIndex >= len(m.bookmarkList) && m.bookmarkIndex > 0 {
+				m.bookmarkIndex--
+			}
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Up):
+		if m.bookmarkIndex > 0 {
+			m.bookmarkIndex--
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		if m.bookmarkIndex < len(m.bookmarkList)-1 {
+			m.bookmarkIndex++
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.NewBookmark):
+		m.startBookmark()
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m Model) renderBookmarksView() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.Header.Render("Bookmarks"))
+	b.WriteString("\n\n")
+
+	// Handle the note prompt for a new bookmark
+	if m.addingBookmark {
+		b.WriteString("Bookmark Current Query\n\n")
+		b.WriteString(m.styles.Muted.Render(truncateString(cellReplacer.Replace(strings.TrimSpace(m.editor.Value())), 70)))
+		b.WriteString("\n\n")
+		b.WriteString("Note: ")
+		b.WriteString(m.bookmarkInput.View())
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Muted.Render(m.promptHint("save")))
+		return b.String()
+	}
+
+	if len(m.bookmarkList) == 0 {
+		b.WriteString(m.styles.Muted.Render("No bookmarks yet."))
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Muted.Render("Press Ctrl+O in the query view to bookmark the current query with a note."))
+		return b.String()
+	}
+
+	for i, bm := range m.bookmarkList {
+		prefix := "  "
+		style := m.styles.Muted
+		if i == m.bookmarkIndex {
+			prefix = "▶ "
+			style = m.styles.Bold
+		}
+
+		note := bm.Description
+		if note == "" {
+			note = "(no note)"
+		}
+
+		line := fmt.Sprintf("%s%s  %s", prefix, bm.CreatedAt.Format("2006-01-02 15:04"), note)
+		b.WriteString(style.Render(line))
+		b.WriteString("\n")
+		b.WriteString(m.styles.Muted.Render("    " + truncateString(cellReplacer.Replace(bm.Query), 70)))
+		b.WriteString("\n")
+
+		if i == 9 && len(m.bookmarkList) > 10 {
+			b.WriteString(m.styles.Muted.Render(fmt.Sprintf("  ... and %d more", len(m.bookmarkList)-10)))
+			break
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// openCatalogView shows the bundled query catalog
+func (m *Model) openCatalogView() {
+	if m.catalog == nil {
+		catalog, err := azure.Catalog()
+		if err != nil {
+			m.lastError = err.Error()
+			return
+		}
+		m.catalog = catalog
+	}
+	m.catalogIndex = min(m.catalogIndex, len(m.catalog)-1)
+	m.currentView = ViewCatalog
+}
+
+func (m Model) updateCatalogView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Select):
+		if m.catalogIndex >= 0 && m.catalogIndex < len(m.catalog) {
+			m.editor.SetValue(m.catalog[m.catalogIndex].Query)
+			m.currentView = ViewQuery
+			m.editor.Focus()
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.NewTemplate):
+		// Copy into the user's own, editable templates
+		if m.catalogIndex >= 0 && m.catalogIndex < len(m.catalog) {
+			q := m.catalog[m.catalogIndex]
+			m.templates.Add(q.Name, q.Query, q.Description, []string{"catalog", strings.ToLower(q.Category)})
+			if err := m.templates.Save(); err != nil {
+				m.lastError = fmt.Sprintf("Failed to save templates: %v", err)
+				return m, nil
+			}
+			m.notice = fmt.Sprintf("Copied %q to templates", q.Name)
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Up):
+		if m.catalogIndex > 0 {
+			m.catalogIndex--
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		if m.catalogIndex < len(m.catalog)-1 {
+			m.catalogIndex++
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m Model) renderCatalogView() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.Header.Render("Query Catalog"))
+	b.WriteString("\n\n")
+
+	if len(m.catalog) == 0 {
+		b.WriteString(m.styles.Muted.Render("The query catalog is empty."))
+		return b.String()
+	}
+
+	// List the queries under their category headings, scrolled to keep
+	// the selected one in view
+	var lines []string
+	selectedLine := 0
+	for i, q := range m.catalog {
+		if i == 0 || q.Category != m.catalog[i-1].Category {
+			lines = append(lines, m.styles.Bold.Render(q.Category))
+		}
+		if i == m.catalogIndex {
+			selectedLine = len(lines)
+			lines = append(lines, m.styles.Bold.Render("  ▶ "+q.Name))
+		} else {
+			lines = append(lines, m.styles.Muted.Render("    "+q.Name))
+		}
+	}
+
+	selected := m.catalog[m.catalogIndex]
+	queryLines := strings.Split(selected.Query, "\n")
+	visible := max(m.height-len(queryLines)-12, 5)
+	start := max(min(selectedLine-visible/2, len(lines)-visible), 0)
+	end := min(start+visible, len(lines))
+	b.WriteString(strings.Join(lines[start:end], "\n"))
+	b.WriteString("\n\n")
+
+	b.WriteString(selected.Description)
+	b.WriteString("\n")
+	for _, line := range queryLines {
+		b.WriteString(m.styles.Muted.Render("  " + truncateString(line, max(m.width-4, 20))))
+		b.WriteString("\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDatetimeLayout is the layout datetime cells are shown in unless the
+// config sets datetime_format
+const DefaultDatetimeLayout = "2006-01-02 15:04:05"
+
+// Datetime display settings, applied from the config and --timezone
+var (
+	datetimeLocation = time.UTC
+	datetimeLayout   = DefaultDatetimeLayout
+)
+
+// Number display settings, applied from the config
+var (
+	numberPrecision    = -1 // Decimals shown for fractional numbers, -1 for as many as needed
+	thousandsSeparator = false
+)
+
+// SetNumberFormat sets how many decimals fractional numbers are shown with
+// (-1 for as many as the value needs) and whether digits are grouped with
+// thousands separators
+func SetNumberFormat(precision int, thousands bool) {
+	numberPrecision = precision
+	thousandsSeparator = thousands
+}
+
+// formatNumber formats a numeric cell for display. Integer values never get
+// decimals.
+func formatNumber(f float64) string {
+	var s string
+	switch {
+	case f == float64(int64(f)):
+		s = strconv.FormatInt(int64(f), 10)
+	case numberPrecision >= 0:
+		s = strconv.FormatFloat(f, 'f', numberPrecision, 64)
+	default:
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	if thousandsSeparator {
+		s = groupThousands(s)
+	}
+	return s
+}
+
+// groupThousands inserts commas between groups of three digits in the
+// integer part of a formatted number
+func groupThousands(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, frac, hasFrac := strings.Cut(s, ".")
+
+	var b strings.Builder
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+
+	if hasFrac {
+		return sign + b.String() + "." + frac
+	}
+	return sign + b.String()
+}
+
+// LoadLocation resolves a timezone setting: "" or "UTC", "local", or an IANA
+// zone name such as "Europe/Berlin"
+func LoadLocation(name string) (*time.Location, error) {
+	switch strings.ToLower(name) {
+	case "", "utc":
+		return time.UTC, nil
+	case "local":
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// SetDatetimeFormat sets the zone datetime values are converted to and the
+// layout they are shown in. An empty layout selects DefaultDatetimeLayout.
+func SetDatetimeFormat(loc *time.Location, layout string) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if layout == "" {
+		layout = DefaultDatetimeLayout
+	}
+	datetimeLocation = loc
+	datetimeLayout = layout
+}
+
+// FormatDatetime converts a datetime value, either a time.Time or the
+// RFC 3339 string Log Analytics returns, to the display zone and layout with
+// the zone abbreviation appended. Values that can't be parsed are returned
+// as-is.
+func FormatDatetime(v interface{}) string {
+	var t time.Time
+	switch val := v.(type) {
+	case time.Time:
+		t = val
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, val)
+		if err != nil {
+			return val
+		}
+		t = parsed
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+
+	t = t.In(datetimeLocation)
+	s := t.Format(datetimeLayout)
+	// Label the zone unless the layout already does
+	if !strings.Contains(datetimeLayout, "MST") && !strings.Contains(datetimeLayout, "07") {
+		s += " " + t.Format("MST")
+	}
+	return s
+}
+
+// FormatDynamic renders a dynamic value as compact JSON. Strings that hold
+// JSON are compacted, other strings are returned as-is.
+func FormatDynamic(v interface{}) string {
+	if s, ok := v.(string); ok {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, []byte(s)); err != nil {
+			return s
+		}
+		return buf.String()
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// ansiPattern matches ANSI escape sequences that may be embedded in log data
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b[@-_]`)
+
+// StripANSI removes terminal escape sequences from a value, so data never
+// controls the terminal it is printed to
+func StripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// PrettyDynamic renders a dynamic value as indented JSON for the row detail
+// view. Values that aren't JSON objects or arrays are returned unchanged.
+func PrettyDynamic(s string) string {
+	if !strings.HasPrefix(s, "{") && !strings.HasPrefix(s, "[") {
+		return s
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(s), "", "  "); err != nil {
+		return s
+	}
+	return buf.String()
+}
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDatetime(t *testing.T) {
+	berlin, err := LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("Timezone data not available: %v", err)
+	}
+	defer SetDatetimeFormat(time.UTC, "")
+
+	tests := []struct {
+		name     string
+		loc      *time.Location
+		layout   string
+		value    interface{}
+		expected string
+	}{
+		{"utc string", time.UTC, "", "2024-01-15T10:30:00.123Z", "2024-01-15 10:30:00 UTC"},
+		{"converted to zone", berlin, "", "2024-01-15T10:30:00Z", "2024-01-15 11:30:00 CET"},
+		{"time value", berlin, "", time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC), "2024-07-01 14:00:00 CEST"},
+		{"custom layout", time.UTC, "02 Jan 15:04", "2024-01-15T10:30:00Z", "15 Jan 10:30 UTC"},
+		{"layout with zone", time.UTC, time.RFC3339, "2024-01-15T10:30:00Z", "2024-01-15T10:30:00Z"},
+		{"not a datetime", time.UTC, "", "yesterday", "yesterday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetDatetimeFormat(tt.loc, tt.layout)
+			if got := FormatDatetime(tt.value); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	if loc, err := LoadLocation(""); err != nil || loc != time.UTC {
+		t.Errorf("Expected UTC by default, got %v (%v)", loc, err)
+	}
+	if loc, err := LoadLocation("local"); err != nil || loc != time.Local {
+		t.Errorf("Expected the local zone, got %v (%v)", loc, err)
+	}
+	if _, err := LoadLocation("Not/AZone"); err == nil {
+		t.Error("Expected an error for an unknown zone")
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	defer SetNumberFormat(-1, false)
+
+	tests := []struct {
+		name      string
+		precision int
+		thousands bool
+		value     float64
+		expected  string
+	}{
+		{"integer", -1, false, 1234567, "1234567"},
+		{"integer grouped", -1, true, 1234567, "1,234,567"},
+		{"negative grouped", -1, true, -1234567.5, "-1,234,567.5"},
+		{"keeps needed decimals", -1, false, 0.000123, "0.000123"},
+		{"fixed precision", 3, false, 2.0 / 3, "0.667"},
+		{"integer-valued float ignores precision", 2, false, 42, "42"},
+		{"small number not grouped", -1, true, 999.25, "999.25"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetNumberFormat(tt.precision, tt.thousands)
+			if got := formatNumber(tt.value); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFormatDynamic(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"object", map[string]interface{}{"b": 1.0, "a": "x<y"}, `{"a":"x<y","b":1}`},
+		{"array", []interface{}{"a", 2.0, nil}, `["a",2,null]`},
+		{"json string compacted", "{ \"k\": [1, 2] }", `{"k":[1,2]}`},
+		{"plain string", "not json", "not json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDynamic(tt.value); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPrettyDynamic(t *testing.T) {
+	if got := PrettyDynamic(`{"a":[1]}`); got != "{\n  \"a\": [\n    1\n  ]\n}" {
+		t.Errorf("Expected indented JSON, got %q", got)
+	}
+	if got := PrettyDynamic("plain"); got != "plain" {
+		t.Errorf("Expected 'plain', got %q", got)
+	}
+}
@@ -0,0 +1,416 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// sparkBlocks are the bar heights of a sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// chartHeight is the number of lines the chart panel takes above the table
+const chartHeight = 3
+
+// chartKind is how the chart panel draws a result
+type chartKind int
+
+const (
+	chartTimeSeries chartKind = iota // Sparkline of a value over time
+	chartBar                         // A bar per category
+	chartPie                         // Each category's share of the total
+)
+
+// renderChartKinds maps the chart types of the KQL render operator to how
+// they are drawn
+var renderChartKinds = map[string]chartKind{
+	"timechart":        chartTimeSeries,
+	"linechart":        chartTimeSeries,
+	"areachart":        chartTimeSeries,
+	"stackedareachart": chartTimeSeries,
+	"scatterchart":     chartTimeSeries,
+	"anomalychart":     chartTimeSeries,
+	"barchart":         chartBar,
+	"columnchart":      chartBar,
+	"piechart":         chartPie,
+}
+
+// pieShades draw the largest slices of a pie chart, the last one for the
+// rest combined
+var pieShades = []rune("█▓▒░")
+
+// seriesPoint is one point of a time series
+type seriesPoint struct {
+	at    time.Time
+	value float64
+}
+
+// isNumericType reports whether a column type can be charted as a value
+func isNumericType(colType string) bool {
+	switch colType {
+	case "int", "long", "real", "decimal":
+		return true
+	}
+	return false
+}
+
+// chartColumns returns the datetime columns usable as the x axis and the
+// numeric columns usable as the y axis
+func chartColumns(columnTypes []string) (xs, ys []int) {
+	for i, colType := range columnTypes {
+		switch {
+		case colType == "datetime":
+			xs = append(xs, i)
+		case isNumericType(colType):
+			ys = append(ys, i)
+		}
+	}
+	return xs, ys
+}
+
+// categoryColumns returns the columns usable as bar or pie chart
+// categories: anything but numbers and dynamic values
+func categoryColumns(columnTypes []string) []int {
+	var xs []int
+	for i, colType := range columnTypes {
+		if !isNumericType(colType) && colType != "dynamic" {
+			xs = append(xs, i)
+		}
+	}
+	return xs
+}
+
+// chartAxes returns the columns a kind of chart can use as its x axis and
+// values
+func chartAxes(kind chartKind, columnTypes []string) (xs, ys []int) {
+	xs, ys = chartColumns(columnTypes)
+	if kind != chartTimeSeries {
+		xs = categoryColumns(columnTypes)
+	}
+	return xs, ys
+}
+
+// isTimeSeries reports whether a result has the shape of a time series, such
+// as `summarize count() by bin(TimeGenerated, 1h)`, with at most one other
+// column splitting the series
+func isTimeSeries(columnTypes []string) bool {
+	xs, ys := chartColumns(columnTypes)
+	return len(xs) > 0 && len(ys) > 0 && len(columnTypes) <= 3
+}
+
+// numericValue converts a raw result value to a number
+func numericValue(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case int64:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case string:
+		f, err := strconv.ParseFloat(val, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// timeSeries extracts the points of a series from result rows, summing the
+// values of rows with the same time and ordering the points by time. Rows
+// whose time or value can't be read are skipped.
+func timeSeries(rows [][]interface{}, x, y int) []seriesPoint {
+	sums := make(map[time.Time]float64)
+	for _, row := range rows {
+		if x >= len(row) || y >= len(row) {
+			continue
+		}
+		s, ok := row[x].(string)
+		if !ok {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			continue
+		}
+		value, ok := numericValue(row[y])
+		if !ok {
+			continue
+		}
+		sums[at] += value
+	}
+
+	points := make([]seriesPoint, 0, len(sums))
+	for at, value := range sums {
+		points = append(points, seriesPoint{at: at, value: value})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].at.Before(points[j].at) })
+	return points
+}
+
+// categoryPoint is the total value of one category
+type categoryPoint struct {
+	label string
+	value float64
+}
+
+// categorySeries sums the values of result rows by category, in the order
+// the categories first appear. Rows whose value can't be read are skipped.
+func categorySeries(rows [][]interface{}, x, y int, xType string) []categoryPoint {
+	index := make(map[string]int)
+	var points []categoryPoint
+	for _, row := range rows {
+		if x >= len(row) || y >= len(row) {
+			continue
+		}
+		value, ok := numericValue(row[y])
+		if !ok {
+			continue
+		}
+		label := formatCell(row[x], xType)
+		i, seen := index[label]
+		if !seen {
+			i = len(points)
+			index[label] = i
+			points = append(points, categoryPoint{label: label})
+		}
+		points[i].value += value
+	}
+	return points
+}
+
+// sparkline renders values as a line of at most width bars. With more values
+// than bars, each bar shows the largest value it covers so spikes stay
+// visible. Bars are scaled from zero, or from the minimum if it is negative.
+func sparkline(values []float64, width int) string {
+	if len(values) == 0 || width <= 0 {
+		return ""
+	}
+	if len(values) > width {
+		buckets := make([]float64, width)
+		for i := range buckets {
+			start, end := i*len(values)/width, (i+1)*len(values)/width
+			buckets[i] = values[start]
+			for _, v := range values[start:end] {
+				buckets[i] = math.Max(buckets[i], v)
+			}
+		}
+		values = buckets
+	}
+
+	lo, hi := 0.0, values[0]
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+
+	var b strings.Builder
+	top := len(sparkBlocks) - 1
+	for _, v := range values {
+		level := 0
+		if hi > lo {
+			level = int(math.Round((v - lo) / (hi - lo) * float64(top)))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// chartTitle describes the charted series, noun naming its points
+func chartTitle(columns []azure.Column, x, y int, values []float64, noun string) string {
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	return fmt.Sprintf("%s by %s · %d %s · min %s · max %s",
+		columns[y].Name, columns[x].Name, len(values), noun,
+		strconv.FormatFloat(lo, 'f', -1, 64), strconv.FormatFloat(hi, 'f', -1, 64))
+}
+
+// barChart draws categories as bars of equal width, with their labels
+// below. Categories that don't fit are left out; shown is how many fit.
+func barChart(points []categoryPoint, width int) (bars, labels string, shown int) {
+	slot := max(2, min(10, width/max(1, len(points))))
+	shown = min(len(points), max(1, width/slot))
+
+	values := make([]float64, shown)
+	for i, p := range points[:shown] {
+		values[i] = p.value
+	}
+	var b, l strings.Builder
+	for i, r := range []rune(sparkline(values, shown)) {
+		b.WriteString(strings.Repeat(string(r), slot-1) + " ")
+		label := truncateString(points[i].label, slot-1)
+		l.WriteString(label + strings.Repeat(" ", slot-cellWidth(label)))
+	}
+	return strings.TrimRight(b.String(), " "), strings.TrimRight(l.String(), " "), shown
+}
+
+// pieChart draws each category's share of the total as a segment of a bar,
+// largest first, with a legend. Categories past the first few are combined
+// as "other"; those with no positive value are left out.
+func pieChart(points []categoryPoint, width int) (bar, legend string) {
+	var slices []categoryPoint
+	total := 0.0
+	for _, p := range points {
+		if p.value > 0 {
+			slices = append(slices, p)
+			total += p.value
+		}
+	}
+	if total == 0 {
+		return "", ""
+	}
+	sort.SliceStable(slices, func(i, j int) bool { return slices[i].value > slices[j].value })
+	if top := len(pieShades) - 1; len(slices) > len(pieShades) {
+		other := categoryPoint{label: "other"}
+		for _, p := range slices[top:] {
+			other.value += p.value
+		}
+		slices = append(slices[:top], other)
+	}
+
+	var b strings.Builder
+	entries := make([]string, len(slices))
+	drawn, cumulative := 0, 0.0
+	for i, p := range slices {
+		cumulative += p.value
+		end := int(math.Round(cumulative / total * float64(width)))
+		b.WriteString(strings.Repeat(string(pieShades[i]), end-drawn))
+		drawn = end
+		entries[i] = fmt.Sprintf("%c %s %.0f%%", pieShades[i], p.label, p.value/total*100)
+	}
+	return b.String(), truncateString(strings.Join(entries, " · "), width)
+}
+
+// resetChart picks the chart for a new result. A chart type the query asked
+// for with the render operator is shown if the result fits it; otherwise
+// the chart is shown when the result looks like a time series
+func (m *Model) resetChart(columnTypes []string, render string) {
+	m.chartHint = ""
+	if kind, ok := renderChartKinds[render]; ok {
+		if xs, ys := chartAxes(kind, columnTypes); len(xs) > 0 && len(ys) > 0 {
+			m.chartKind, m.chartHint = kind, render
+			m.chartX, m.chartY = xs[0], ys[0]
+			m.showChart = true
+			m.layoutTable()
+			return
+		}
+	}
+
+	xs, ys := chartColumns(columnTypes)
+	m.chartKind = chartTimeSeries
+	m.chartX, m.chartY = -1, -1
+	if len(xs) > 0 && len(ys) > 0 {
+		m.chartX, m.chartY = xs[0], ys[0]
+	}
+	m.showChart = isTimeSeries(columnTypes)
+	m.layoutTable()
+}
+
+// toggleChart shows or hides the chart panel if the result can be charted
+func (m *Model) toggleChart() {
+	if m.chartX < 0 {
+		m.notice = "Nothing to chart: the result needs a datetime and a numeric column"
+		return
+	}
+	m.showChart = !m.showChart
+	m.layoutTable()
+}
+
+// cycleChartColumn moves the x or y axis of the chart to the next column of
+// a suitable type
+func (m *Model) cycleChartColumn(axis *int, candidates []int) {
+	for i, col := range candidates {
+		if col == *axis {
+			*axis = candidates[(i+1)%len(candidates)]
+			return
+		}
+	}
+}
+
+// chartShown reports whether the chart panel is displayed
+func (m Model) chartShown() bool {
+	return m.showChart && m.chartX >= 0 && m.table.RowCount() > 0
+}
+
+// renderChart renders the chart panel: a title, the chart and its axis or
+// legend
+func (m Model) renderChart() string {
+	width := m.width - 6
+	if width < 10 {
+		width = 10
+	}
+	if m.chartKind != chartTimeSeries {
+		return m.renderCategoryChart(width)
+	}
+
+	points := timeSeries(m.currentRows(), m.chartX, m.chartY)
+	if len(points) == 0 {
+		return m.styles.Muted.Render("No chartable values") + "\n\n\n"
+	}
+
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.value
+	}
+	line := sparkline(values, width)
+
+	first := FormatDatetime(points[0].at)
+	last := FormatDatetime(points[len(points)-1].at)
+	gap := cellWidth(line) - cellWidth(first) - cellWidth(last)
+	if gap < 1 {
+		gap = 1
+	}
+
+	var b strings.Builder
+	b.WriteString(m.styles.Muted.Render(m.chartTitle(values, "points")) + "\n")
+	b.WriteString(m.styles.Success.Render(line) + "\n")
+	b.WriteString(m.styles.Muted.Render(first+strings.Repeat(" ", gap)+last) + "\n")
+	return b.String()
+}
+
+// renderCategoryChart renders a bar or pie chart of the values by category
+func (m Model) renderCategoryChart(width int) string {
+	points := categorySeries(m.currentRows(), m.chartX, m.chartY, m.resultColumns[m.chartX].Type)
+	if len(points) == 0 {
+		return m.styles.Muted.Render("No chartable values") + "\n\n\n"
+	}
+
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.value
+	}
+	title := m.chartTitle(values, "categories")
+	var line, legend string
+	if m.chartKind == chartPie {
+		if line, legend = pieChart(points, width); line == "" {
+			return m.styles.Muted.Render("No positive values to chart") + "\n\n\n"
+		}
+	} else {
+		var shown int
+		if line, legend, shown = barChart(points, width); shown < len(points) {
+			title += fmt.Sprintf(" · first %d shown", shown)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(m.styles.Muted.Render(title) + "\n")
+	b.WriteString(m.styles.Success.Render(line) + "\n")
+	b.WriteString(m.styles.Muted.Render(legend) + "\n")
+	return b.String()
+}
+
+// chartTitle describes the charted series, noting the render operator's
+// chart type when the query chose it
+func (m Model) chartTitle(values []float64, noun string) string {
+	title := chartTitle(m.resultColumns, m.chartX, m.chartY, values, noun)
+	if m.chartHint != "" {
+		title += " · render " + m.chartHint
+	}
+	return title
+}
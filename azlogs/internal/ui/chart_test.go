@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		width    int
+		expected string
+	}{
+		{"scaled from zero", []float64{0, 1, 2, 7}, 10, "▁▂▃█"},
+		{"flat series", []float64{5, 5, 5}, 10, "███"},
+		{"all zero", []float64{0, 0}, 10, "▁▁"},
+		{"negative values", []float64{-7, 0}, 10, "▁█"},
+		{"buckets keep spikes", []float64{0, 7, 0, 0, 0, 0}, 3, "█▁▁"},
+		{"empty", nil, 10, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sparkline(tt.values, tt.width); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTimeSeries(t *testing.T) {
+	rows := [][]interface{}{
+		{"2024-01-01T02:00:00Z", "web", 3.0},
+		{"2024-01-01T01:00:00Z", "web", 1.0},
+		{"2024-01-01T01:00:00Z", "db", 4.0},
+		{"not a time", "db", 9.0},
+		{"2024-01-01T03:00:00Z", "db", nil},
+	}
+
+	points := timeSeries(rows, 0, 2)
+	if len(points) != 2 {
+		t.Fatalf("Expected 2 points, got %d", len(points))
+	}
+	if !points[0].at.Equal(time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)) || points[0].value != 5 {
+		t.Errorf("Expected rows at the same time summed, got %v = %v", points[0].at, points[0].value)
+	}
+	if points[1].value != 3 {
+		t.Errorf("Expected the later point second, got %v", points[1].value)
+	}
+}
+
+func TestIsTimeSeries(t *testing.T) {
+	tests := []struct {
+		types    []string
+		expected bool
+	}{
+		{[]string{"datetime", "long"}, true},
+		{[]string{"datetime", "string", "long"}, true},
+		{[]string{"datetime", "string", "string", "long"}, false},
+		{[]string{"string", "long"}, false},
+		{[]string{"datetime", "string"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := isTimeSeries(tt.types); got != tt.expected {
+			t.Errorf("isTimeSeries(%v): expected %v, got %v", tt.types, tt.expected, got)
+		}
+	}
+}
+
+func TestCategorySeries(t *testing.T) {
+	rows := [][]interface{}{
+		{"web", 3.0},
+		{"db", 4.0},
+		{"web", 1.0},
+		{"cache", nil},
+	}
+
+	points := categorySeries(rows, 0, 1, "string")
+	if len(points) != 2 || points[0] != (categoryPoint{"web", 4}) || points[1] != (categoryPoint{"db", 4}) {
+		t.Errorf("Expected web then db summed, got %v", points)
+	}
+}
+
+func TestBarChart(t *testing.T) {
+	points := []categoryPoint{{"web", 7}, {"database", 0}, {"cache", 3}}
+
+	bars, labels, shown := barChart(points, 12)
+	if bars != "███ ▁▁▁ ▄▄▄" || labels != "web dat cac" || shown != 3 {
+		t.Errorf("Expected three labeled bars, got %q / %q (%d shown)", bars, labels, shown)
+	}
+	if _, _, shown := barChart(points, 4); shown != 2 {
+		t.Errorf("Expected 2 bars to fit in 4 columns, got %d", shown)
+	}
+}
+
+func TestPieChart(t *testing.T) {
+	points := []categoryPoint{{"a", 1}, {"b", 6}, {"c", 1}, {"d", 1}, {"e", 1}, {"f", -3}}
+
+	if bar, _ := pieChart(points, 10); bar != "██████▓▒░░" {
+		t.Errorf("Expected segments by share, largest first, got %q", bar)
+	}
+	if _, legend := pieChart(points, 60); legend != "█ b 60% · ▓ a 10% · ▒ c 10% · ░ other 20%" {
+		t.Errorf("Expected the legend with the rest as other, got %q", legend)
+	}
+	if bar, _ := pieChart([]categoryPoint{{"a", 0}}, 10); bar != "" {
+		t.Errorf("Expected nothing to draw without positive values, got %q", bar)
+	}
+}
+
+func TestModel_RenderHint(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	columns := []azure.Column{{Name: "Computer", Type: "string"}, {Name: "Count", Type: "long"}}
+	tests := []struct {
+		name   string
+		render string
+		shown  bool
+		kind   chartKind
+	}{
+		{"no hint", "", false, chartTimeSeries},
+		{"piechart", "piechart", true, chartPie},
+		{"columnchart", "columnchart", true, chartBar},
+		{"timechart without a datetime", "timechart", false, chartTimeSeries},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, _ := NewModel("", azure.AuthDefault, azure.NewConfig()).Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+			m := model.(Model)
+			m.processResults(&azure.QueryResult{RowCount: 2, Render: tt.render, Tables: []azure.Table{{
+				Columns: columns,
+				Rows:    [][]interface{}{{"web", 3.0}, {"db", 1.0}},
+			}}})
+			if m.chartShown() != tt.shown || m.chartKind != tt.kind {
+				t.Fatalf("Expected chart shown %v as kind %d, got %v as %d", tt.shown, tt.kind, m.chartShown(), m.chartKind)
+			}
+			if tt.shown && !strings.Contains(m.renderChart(), "render "+tt.render) {
+				t.Errorf("Expected the chart title to note the render hint, got %q", m.renderChart())
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/clipboard/clipboard"
+)
+
+// pasteMsg carries the clipboard contents read for the query editor
+type pasteMsg struct {
+	text string
+	run  bool // Execute the pasted query
+	err  error
+}
+
+// copiedMsg reports the result of copying to the clipboard
+type copiedMsg struct {
+	what string // What was copied, for the confirmation
+	err  error
+}
+
+// copyToClipboard writes text to the system clipboard
+func copyToClipboard(text, what string) tea.Cmd {
+	return func() tea.Msg {
+		cb, err := clipboard.New()
+		if err == nil {
+			err = cb.Copy([]byte(text))
+		}
+		return copiedMsg{what: what, err: err}
+	}
+}
+
+// clipboardError describes a clipboard failure for the status line
+func clipboardError(err error) string {
+	if errors.Is(err, clipboard.ErrNoClipboardTool) {
+		return "Clipboard unavailable: install xclip, xsel or wl-clipboard"
+	}
+	return fmt.Sprintf("Clipboard failed: %v", err)
+}
+
+// pasteQuery reads the system clipboard
+func pasteQuery(run bool) tea.Cmd {
+	return func() tea.Msg {
+		cb, err := clipboard.New()
+		if err != nil {
+			return pasteMsg{err: err}
+		}
+		data, err := cb.Paste()
+		if err != nil {
+			return pasteMsg{err: err}
+		}
+		return pasteMsg{text: string(data), run: run}
+	}
+}
+
+// updatePaste replaces the query with pasted text, running it if requested
+func (m Model) updatePaste(msg pasteMsg) (tea.Model, tea.Cmd) {
+	text := strings.TrimSpace(msg.text)
+	switch {
+	case msg.err != nil:
+		m.lastError = clipboardError(msg.err)
+		return m, nil
+	case text == "":
+		m.lastError = "Clipboard is empty"
+		return m, nil
+	}
+
+	m.editor.SetValue(text)
+	m.suggestion = ""
+	m.suggestionPopup.Hide()
+	m.lastError = ""
+	if msg.run && m.currentView == ViewQuery {
+		return m.runQuery(false)
+	}
+	return m, nil
+}
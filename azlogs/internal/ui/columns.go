@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// UniqueColumns renames columns whose name an earlier column already has,
+// as joins can produce, to Name_1, Name_2 and so on. It returns the
+// columns, a new slice only if any were renamed, and the new names.
+func UniqueColumns(columns []azure.Column) ([]azure.Column, []string) {
+	seen := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		seen[col.Name] = true
+	}
+
+	var renamed []string
+	used := make(map[string]bool, len(columns))
+	unique := columns
+	for i, col := range columns {
+		if !used[col.Name] {
+			used[col.Name] = true
+			continue
+		}
+		if len(renamed) == 0 {
+			unique = append([]azure.Column(nil), columns...)
+		}
+		name := col.Name
+		for n := 1; seen[name]; n++ {
+			name = fmt.Sprintf("%s_%d", col.Name, n)
+		}
+		seen[name], used[name] = true, true
+		unique[i].Name = name
+		renamed = append(renamed, name)
+	}
+	return unique, renamed
+}
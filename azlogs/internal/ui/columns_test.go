@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestUniqueColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		columns  []string
+		expected []string
+		renamed  []string
+	}{
+		{"no duplicates", []string{"Name", "Count"}, []string{"Name", "Count"}, nil},
+		{"join", []string{"Name", "Id", "Name", "Name"}, []string{"Name", "Id", "Name_1", "Name_2"}, []string{"Name_1", "Name_2"}},
+		{"suffix taken", []string{"Name", "Name_1", "Name"}, []string{"Name", "Name_1", "Name_2"}, []string{"Name_2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns := make([]azure.Column, len(tt.columns))
+			for i, name := range tt.columns {
+				columns[i] = azure.Column{Name: name, Type: "string"}
+			}
+
+			unique, renamed := UniqueColumns(columns)
+			names := make([]string, len(unique))
+			for i, col := range unique {
+				names[i] = col.Name
+			}
+			if !reflect.DeepEqual(names, tt.expected) || !reflect.DeepEqual(renamed, tt.renamed) {
+				t.Errorf("Expected %v renaming %v, got %v renaming %v", tt.expected, tt.renamed, names, renamed)
+			}
+			if columns[len(columns)-1].Name != tt.columns[len(tt.columns)-1] {
+				t.Error("Expected the original columns left unchanged")
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// costWarningGB is the estimated scan size above which the hint is shown
+// as a warning
+const costWarningGB = 10
+
+type ingestionMsg struct {
+	workspace string
+	rates     map[string]float64
+	err       error
+}
+
+// loadIngestionRates fetches each table's daily ingestion in the background
+// for the cost hint. It is best effort: without a Usage table, or read access
+// to it, no hint is shown
+func (m *Model) loadIngestionRates() tea.Cmd {
+	if m.client == nil || m.config.Cluster != "" {
+		return nil
+	}
+	client, workspace := m.client, m.workspaceID
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		rates, err := azure.IngestionRates(ctx, client)
+		return ingestionMsg{workspace: workspace, rates: rates, err: err}
+	}
+}
+
+// agoPattern matches an ago() call with a timespan literal such as ago(7d)
+var agoPattern = regexp.MustCompile(`\bago\s*\(\s*(\d+(?:\.\d+)?[dhms])\s*\)`)
+
+// queryLookback returns how far back a query reads: the active time range,
+// or else the longest ago() in the query. It is 0 when the query has no
+// bound that can be measured
+func queryLookback(query, timeRange string) time.Duration {
+	if d, err := parseTimeRange(timeRange); err == nil && d > 0 {
+		return d
+	}
+	var longest time.Duration
+	for _, match := range agoPattern.FindAllStringSubmatch(strings.ToLower(StripKQLComments(query)), -1) {
+		if d, err := parseTimeRange(match[1]); err == nil && d > longest {
+			longest = d
+		}
+	}
+	return longest
+}
+
+// describeLookback returns a duration in days, or in hours under a day
+func describeLookback(d time.Duration) string {
+	n, unit := d.Hours(), "hour"
+	if d >= 24*time.Hour {
+		n, unit = n/24, "day"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%.3g %s", n, unit)
+}
+
+// costEstimate returns a rough hint of how much data a query scans from the
+// tables' daily ingestion, and whether it is large enough to warn about. The
+// hint is empty when none of the tables' ingestion is known
+func costEstimate(tables []string, lookback time.Duration, rates map[string]float64) (string, bool) {
+	var perDay float64
+	var parts []string
+	for _, table := range slices.Sorted(slices.Values(tables)) {
+		if gb, ok := rates[table]; ok {
+			perDay += gb
+			parts = append(parts, fmt.Sprintf("%s ingests ~%.2f GB/day", table, gb))
+		}
+	}
+	if len(parts) == 0 {
+		return "", false
+	}
+	ingests := strings.Join(parts, ", ")
+
+	if lookback == 0 {
+		return fmt.Sprintf("No time bound: scans all retained data (%s)", ingests), perDay > 0
+	}
+	gb := perDay * lookback.Hours() / 24
+	return fmt.Sprintf("Scans ~%.2f GB over %s (%s)", gb, describeLookback(lookback), ingests), gb >= costWarningGB
+}
+
+// renderCostHint renders the estimated scan size of the query in the editor
+func (m Model) renderCostHint() string {
+	if len(m.ingestionRates) == 0 {
+		return ""
+	}
+	query := m.editor.Value()
+	hint, warn := costEstimate(m.parseTablesFromQuery(query), queryLookback(query, m.activeTimeRange()), m.ingestionRates)
+	if hint == "" {
+		return ""
+	}
+	if warn {
+		return m.styles.Warning.Render(" ⚠ " + hint)
+	}
+	return m.styles.Muted.Render(" " + hint)
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueryLookback(t *testing.T) {
+	tests := []struct {
+		query     string
+		timeRange string
+		expected  time.Duration
+	}{
+		{"SigninLogs | where TimeGenerated > ago(3d)", "", 72 * time.Hour},
+		{"SigninLogs | where TimeGenerated > ago(1h) or TimeGenerated < ago(2d)", "", 48 * time.Hour},
+		{"SigninLogs | where TimeGenerated > ago(3d)", "4h", 4 * time.Hour},
+		{"SigninLogs // | where TimeGenerated > ago(3d)", "", 0},
+		{"SigninLogs | take 10", "", 0},
+	}
+
+	for _, tt := range tests {
+		if got := queryLookback(tt.query, tt.timeRange); got != tt.expected {
+			t.Errorf("queryLookback(%q, %q): expected %v, got %v", tt.query, tt.timeRange, tt.expected, got)
+		}
+	}
+}
+
+func TestCostEstimate(t *testing.T) {
+	rates := map[string]float64{"SigninLogs": 0.5, "Perf": 12}
+
+	tests := []struct {
+		name     string
+		tables   []string
+		lookback time.Duration
+		expected string
+		warn     bool
+	}{
+		{"bounded", []string{"SigninLogs"}, 72 * time.Hour, "Scans ~1.50 GB over 3 days (SigninLogs ingests ~0.50 GB/day)", false},
+		{"large scan warns", []string{"SigninLogs", "Perf"}, 24 * time.Hour, "Scans ~12.50 GB over 1 day (Perf ingests ~12.00 GB/day, SigninLogs ingests ~0.50 GB/day)", true},
+		{"hours", []string{"Perf"}, 4 * time.Hour, "Scans ~2.00 GB over 4 hours (Perf ingests ~12.00 GB/day)", false},
+		{"unbounded warns", []string{"SigninLogs"}, 0, "No time bound: scans all retained data (SigninLogs ingests ~0.50 GB/day)", true},
+		{"unknown table", []string{"AuditLogs"}, 24 * time.Hour, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warn := costEstimate(tt.tables, tt.lookback, rates)
+			if got != tt.expected || warn != tt.warn {
+				t.Errorf("Expected %q (warn %v), got %q (warn %v)", tt.expected, tt.warn, got, warn)
+			}
+		})
+	}
+}
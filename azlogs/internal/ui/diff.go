@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// rowMark is how a result row differs from the diff baseline
+type rowMark int
+
+const (
+	rowUnchanged rowMark = iota
+	rowAdded
+	rowChanged
+	rowRemoved // Only in the baseline, shown after the current rows
+)
+
+// resultBaseline is a result captured for comparison with the next run of
+// the same query
+type resultBaseline struct {
+	query   string
+	columns []azure.Column
+	rows    [][]interface{}
+	at      time.Time
+}
+
+// diffSummary counts the rows that differ from the baseline
+type diffSummary struct {
+	added, removed, changed int
+}
+
+// String describes the differences, e.g. "+3 -1 ~2"
+func (s diffSummary) String() string {
+	return fmt.Sprintf("+%d -%d ~%d", s.added, s.removed, s.changed)
+}
+
+// sameQuery reports whether two queries are the same apart from surrounding
+// whitespace
+func sameQuery(a, b string) bool {
+	return strings.TrimSpace(a) == strings.TrimSpace(b)
+}
+
+// rowHash identifies a row by all of its values
+func rowHash(row []interface{}) string {
+	parts := make([]string, len(row))
+	for i, v := range row {
+		parts[i] = fmt.Sprintf("%T:%v", v, v)
+	}
+	return strings.Join(parts, "\x1f")
+}
+
+// columnIndex returns the index of the named column, or -1
+func columnIndex(columns []azure.Column, name string) int {
+	for i, col := range columns {
+		if col.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// diffRows compares rows with the baseline's. Rows are matched on the key
+// column when keyColumn names one present in both results, so rows with the
+// same key but other values are marked changed; otherwise rows are matched on
+// all of their values and only additions and removals are found. It returns
+// a mark per row and the baseline rows missing from rows.
+func diffRows(base resultBaseline, columns []azure.Column, rows [][]interface{}, keyColumn string) ([]rowMark, [][]interface{}, diffSummary) {
+	marks := make([]rowMark, len(rows))
+	var summary diffSummary
+
+	baseKey, key := columnIndex(base.columns, keyColumn), columnIndex(columns, keyColumn)
+	keyed := keyColumn != "" && baseKey >= 0 && key >= 0
+	keyOf := func(row []interface{}, col int) string {
+		if !keyed {
+			return rowHash(row)
+		}
+		if col >= len(row) {
+			return ""
+		}
+		return rowHash(row[col : col+1])
+	}
+
+	// Baseline rows by key, as a multiset so duplicate rows pair up one to one
+	remaining := make(map[string][]int)
+	for i, row := range base.rows {
+		k := keyOf(row, baseKey)
+		remaining[k] = append(remaining[k], i)
+	}
+
+	matched := make([]bool, len(base.rows))
+	for i, row := range rows {
+		k := keyOf(row, key)
+		candidates := remaining[k]
+		if len(candidates) == 0 {
+			marks[i] = rowAdded
+			summary.added++
+			continue
+		}
+		b := candidates[0]
+		remaining[k] = candidates[1:]
+		matched[b] = true
+		if keyed && rowHash(base.rows[b]) != rowHash(row) {
+			marks[i] = rowChanged
+			summary.changed++
+		}
+	}
+
+	var removed [][]interface{}
+	for i, row := range base.rows {
+		if !matched[i] {
+			removed = append(removed, row)
+		}
+	}
+	summary.removed = len(removed)
+	return marks, removed, summary
+}
+
+// sameColumns reports whether two results have the same column names in the
+// same order
+func sameColumns(a, b []azure.Column) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name {
+			return false
+		}
+	}
+	return true
+}
+
+// toggleBaseline captures the displayed result as the diff baseline, or
+// clears the baseline if one is set
+func (m *Model) toggleBaseline() {
+	if m.baseline != nil {
+		m.baseline = nil
+		m.notice = "Diff baseline cleared"
+		return
+	}
+	if m.resultColumns == nil {
+		return
+	}
+
+	m.baseline = &resultBaseline{
+		query:   m.lastQuery,
+		columns: m.resultColumns,
+		rows:    m.currentRows(),
+		at:      time.Now(),
+	}
+	m.notice = "Diff baseline set, rerun the query to compare"
+}
+
+// currentRows returns the raw rows of the displayed result, leaving out the
+// baseline rows a diff lists as removed
+func (m Model) currentRows() [][]interface{} {
+	if m.diff == nil {
+		return m.resultRows
+	}
+	return m.resultRows[:len(m.resultRows)-m.diff.removed]
+}
+
+// keyColumnName returns the name of the frozen column, which keys the diff
+func (m Model) keyColumnName() string {
+	columns := m.table.GetColumns()
+	if col := m.table.FrozenColumn(); col >= 0 && col < len(columns) {
+		return columns[col]
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestDiffRows(t *testing.T) {
+	columns := []azure.Column{{Name: "Computer", Type: "string"}, {Name: "Count", Type: "long"}}
+	base := resultBaseline{
+		columns: columns,
+		rows: [][]interface{}{
+			{"web-1", 10.0},
+			{"web-2", 5.0},
+			{"db-1", 3.0},
+			{"db-1", 3.0},
+		},
+	}
+	rows := [][]interface{}{
+		{"web-1", 10.0},
+		{"web-2", 7.0},
+		{"db-1", 3.0},
+		{"cache-1", 1.0},
+	}
+
+	tests := []struct {
+		name        string
+		keyColumn   string
+		marks       []rowMark
+		removed     int
+		wantSummary diffSummary
+	}{
+		{
+			name:        "whole rows",
+			marks:       []rowMark{rowUnchanged, rowAdded, rowUnchanged, rowAdded},
+			removed:     2,
+			wantSummary: diffSummary{added: 2, removed: 2},
+		},
+		{
+			name:        "keyed",
+			keyColumn:   "Computer",
+			marks:       []rowMark{rowUnchanged, rowChanged, rowUnchanged, rowAdded},
+			removed:     1,
+			wantSummary: diffSummary{added: 1, removed: 1, changed: 1},
+		},
+		{
+			name:        "unknown key column falls back to whole rows",
+			keyColumn:   "Missing",
+			marks:       []rowMark{rowUnchanged, rowAdded, rowUnchanged, rowAdded},
+			removed:     2,
+			wantSummary: diffSummary{added: 2, removed: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			marks, removed, summary := diffRows(base, columns, rows, tt.keyColumn)
+			if !reflect.DeepEqual(marks, tt.marks) {
+				t.Errorf("Expected marks %v, got %v", tt.marks, marks)
+			}
+			if len(removed) != tt.removed {
+				t.Errorf("Expected %d removed rows, got %d", tt.removed, len(removed))
+			}
+			if summary != tt.wantSummary {
+				t.Errorf("Expected summary %s, got %s", tt.wantSummary, summary)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -29,6 +30,9 @@ type QueryEditor struct {
 	styles      *Styles
 	focused     bool
 	placeholder string
+	keys        *KeyMap
+	history     *undoHistory
+	typing      bool // The last edit inserted text, later inserts join its undo step
 }
 
 // NewQueryEditor creates a new query editor
@@ -39,6 +43,10 @@ func NewQueryEditor() QueryEditor {
 	ta.SetHeight(10)
 	ta.SetWidth(80)
 	ta.CharLimit = 0 // No limit
+	if noColor {
+		ta.FocusedStyle = textarea.Style{}
+		ta.BlurredStyle = textarea.Style{}
+	}
 	ta.Focus()
 
 	return QueryEditor{
@@ -46,9 +54,16 @@ func NewQueryEditor() QueryEditor {
 		styles:      DefaultStyles(),
 		focused:     true,
 		placeholder: "Enter KQL query...",
+		keys:        DefaultKeyMap(),
+		history:     &undoHistory{},
 	}
 }
 
+// SetKeyMap sets the keybindings used for line editing
+func (e *QueryEditor) SetKeyMap(keys *KeyMap) {
+	e.keys = keys
+}
+
 // Init initializes the editor
 func (e QueryEditor) Init() tea.Cmd {
 	return textarea.Blink
@@ -56,11 +71,88 @@ func (e QueryEditor) Init() tea.Cmd {
 
 // Update handles messages
 func (e QueryEditor) Update(msg tea.Msg) (QueryEditor, tea.Cmd) {
+	// Line editing shortcuts the textarea doesn't provide
+	if msg, ok := msg.(tea.KeyMsg); ok && e.focused {
+		switch {
+		case key.Matches(msg, e.keys.DuplicateLine):
+			e.editLines(duplicateLine)
+			return e, nil
+		case key.Matches(msg, e.keys.MoveLineUp):
+			e.editLines(func(lines []string, row int) ([]string, int) {
+				return moveLine(lines, row, -1)
+			})
+			return e, nil
+		case key.Matches(msg, e.keys.MoveLineDown):
+			e.editLines(func(lines []string, row int) ([]string, int) {
+				return moveLine(lines, row, 1)
+			})
+			return e, nil
+		case key.Matches(msg, e.keys.DeleteLine):
+			e.editLines(deleteLine)
+			return e, nil
+		case key.Matches(msg, e.keys.Undo):
+			e.Undo()
+			return e, nil
+		case key.Matches(msg, e.keys.Redo):
+			e.Redo()
+			return e, nil
+		}
+	}
+
+	before := e.snapshot()
 	var cmd tea.Cmd
 	e.textarea, cmd = e.textarea.Update(msg)
+
+	if e.textarea.Value() != before.value {
+		// Typed words are undone one at a time rather than per character
+		k, _ := msg.(tea.KeyMsg)
+		inserting := k.Type == tea.KeyRunes && len(k.Runes) == 1 && strings.TrimSpace(string(k.Runes)) != ""
+		if !inserting || !e.typing {
+			e.history.record(before)
+		}
+		e.typing = inserting
+	}
 	return e, cmd
 }
 
+// snapshot captures the text and cursor position for the undo history
+func (e QueryEditor) snapshot() editSnapshot {
+	info := e.textarea.LineInfo()
+	return editSnapshot{
+		value: e.textarea.Value(),
+		row:   e.textarea.Line(),
+		col:   info.StartColumn + info.ColumnOffset,
+	}
+}
+
+// checkpoint records the current state as an undo step before a change
+// made outside of typing
+func (e *QueryEditor) checkpoint() {
+	e.history.record(e.snapshot())
+	e.typing = false
+}
+
+// restore replaces the text and cursor position with a snapshot
+func (e *QueryEditor) restore(s editSnapshot) {
+	e.textarea.SetValue(s.value)
+	e.setCursor(s.row, s.col)
+	e.typing = false
+}
+
+// Undo reverts the last edit
+func (e *QueryEditor) Undo() {
+	if s, ok := e.history.undoTo(e.snapshot()); ok {
+		e.restore(s)
+	}
+}
+
+// Redo reapplies the last undone edit
+func (e *QueryEditor) Redo() {
+	if s, ok := e.history.redoTo(e.snapshot()); ok {
+		e.restore(s)
+	}
+}
+
 // View renders the editor
 func (e QueryEditor) View() string {
 	var b strings.Builder
@@ -97,19 +189,23 @@ func (e QueryEditor) Value() string {
 	return e.textarea.Value()
 }
 
-// SetValue sets the query text
+// SetValue sets the query text. The previous text can be restored with Undo.
 func (e *QueryEditor) SetValue(s string) {
+	if s != e.textarea.Value() {
+		e.checkpoint()
+	}
 	e.textarea.SetValue(s)
 }
 
 // SetSize sets the editor dimensions
 func (e *QueryEditor) SetSize(width, height int) {
-	e.textarea.SetWidth(width - 4) // Account for border
-	e.textarea.SetHeight(height)
+	e.textarea.SetWidth(max(width-4, 10)) // Account for border
+	e.textarea.SetHeight(max(height, 1))
 }
 
 // Reset clears the editor
 func (e *QueryEditor) Reset() {
+	e.checkpoint()
 	e.textarea.Reset()
 }
 
@@ -134,22 +230,144 @@ func (e QueryEditor) CursorPosition() int {
 
 // InsertText inserts text at the current cursor position
 func (e *QueryEditor) InsertText(text string) {
+	e.checkpoint()
 	e.textarea.InsertString(text)
 }
 
+// ToggleComment comments or uncomments the line under the cursor
+func (e *QueryEditor) ToggleComment() {
+	lines := strings.Split(e.textarea.Value(), "\n")
+	row := e.textarea.Line()
+	if row >= len(lines) {
+		return
+	}
+	info := e.textarea.LineInfo()
+	col := info.StartColumn + info.ColumnOffset
+
+	e.checkpoint()
+	var shift int
+	lines[row], shift = toggleLineComment(lines[row])
+	col = max(col+shift, 0)
+
+	e.textarea.SetValue(strings.Join(lines, "\n"))
+	e.setCursor(row, col)
+}
+
+// editLines applies a line editing helper to the text, keeping the cursor
+// column on the line it ends up on
+func (e *QueryEditor) editLines(edit func(lines []string, row int) ([]string, int)) {
+	lines := strings.Split(e.textarea.Value(), "\n")
+	info := e.textarea.LineInfo()
+	col := info.StartColumn + info.ColumnOffset
+
+	lines, row := edit(lines, e.textarea.Line())
+	if value := strings.Join(lines, "\n"); value != e.textarea.Value() {
+		e.checkpoint()
+		e.textarea.SetValue(value)
+	}
+	e.setCursor(row, col)
+}
+
+// setCursor moves the cursor to a column on a logical line. SetValue leaves
+// the cursor on the last line, so this walks up to the target line.
+func (e *QueryEditor) setCursor(row, col int) {
+	for e.textarea.Line() > row {
+		e.textarea.CursorUp()
+	}
+	e.textarea.SetCursor(col)
+}
+
+// toggleLineComment comments out a line, or uncomments it if it's already a
+// comment, returning the new line and how far text after the indent moved
+func toggleLineComment(line string) (string, int) {
+	body := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(body)]
+
+	if rest, ok := strings.CutPrefix(body, "//"); ok {
+		shift := -2
+		if r, ok := strings.CutPrefix(rest, " "); ok {
+			rest = r
+			shift--
+		}
+		return indent + rest, shift
+	}
+	if body == "" {
+		return line, 0
+	}
+	return indent + "// " + body, 3
+}
+
+// commentOut comments out every line of text that isn't blank or already
+// a comment
+func commentOut(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if body := strings.TrimSpace(line); body != "" && !strings.HasPrefix(body, "//") {
+			lines[i], _ = toggleLineComment(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// commentStart returns the index of the "//" starting a comment on a line,
+// ignoring slashes inside string literals, or -1 if there is none
+func commentStart(line string) int {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++ // Skip escaped char
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '/' && i+1 < len(line) && line[i+1] == '/':
+			return i
+		}
+	}
+	return -1
+}
+
+// StripKQLComments removes line comments from a query, leaving "//" inside
+// string literals alone
+func StripKQLComments(query string) string {
+	lines := strings.Split(query, "\n")
+	for i, line := range lines {
+		if idx := commentStart(line); idx >= 0 {
+			lines[i] = line[:idx]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // Highlight styles
 var (
-	keywordStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#5c6bc0")).Bold(true)
-	operatorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff9800"))
-	pipeStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#757575")).Bold(true)
-	stringStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#4caf50"))
-	numberStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#e91e63"))
-	functionStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ffc107"))
+	keywordStyle  = lipgloss.NewStyle().Foreground(activeTheme.Keyword).Bold(true)
+	operatorStyle = lipgloss.NewStyle().Foreground(activeTheme.Operator)
+	pipeStyle     = lipgloss.NewStyle().Foreground(activeTheme.Pipe).Bold(true)
+	stringStyle   = lipgloss.NewStyle().Foreground(activeTheme.String)
+	numberStyle   = lipgloss.NewStyle().Foreground(activeTheme.Number)
+	functionStyle = lipgloss.NewStyle().Foreground(activeTheme.Function)
+	commentStyle  = lipgloss.NewStyle().Foreground(activeTheme.Muted).Italic(true)
 )
 
+// setHighlightColors updates the highlight styles to use the theme's colors
+func setHighlightColors(theme Theme) {
+	keywordStyle = keywordStyle.Foreground(theme.Keyword)
+	operatorStyle = operatorStyle.Foreground(theme.Operator)
+	pipeStyle = pipeStyle.Foreground(theme.Pipe)
+	stringStyle = stringStyle.Foreground(theme.String)
+	numberStyle = numberStyle.Foreground(theme.Number)
+	functionStyle = functionStyle.Foreground(theme.Function)
+	commentStyle = commentStyle.Foreground(theme.Muted)
+}
+
 // HighlightKQL applies syntax highlighting to KQL
 func HighlightKQL(query string) string {
-	if query == "" {
+	if query == "" || noColor {
 		return query
 	}
 
@@ -157,6 +375,16 @@ func HighlightKQL(query string) string {
 	i := 0
 
 	for i < len(query) {
+		// Check for line comments
+		if query[i] == '/' && i+1 < len(query) && query[i+1] == '/' {
+			start := i
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+			result.WriteString(commentStyle.Render(query[start:i]))
+			continue
+		}
+
 		// Check for pipe
 		if query[i] == '|' {
 			result.WriteString(pipeStyle.Render("|"))
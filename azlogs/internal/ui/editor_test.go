@@ -0,0 +1,99 @@
+package ui
+
+import "testing"
+
+func TestToggleLineComment(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		expected  string
+		wantShift int
+	}{
+		{"comment line", "| where x > 1", "// | where x > 1", 3},
+		{"keeps indentation", "  | take 10", "  // | take 10", 3},
+		{"uncomment", "// | where x > 1", "| where x > 1", -3},
+		{"uncomment without space", "//| take 10", "| take 10", -2},
+		{"uncomment indented", "    // T", "    T", -3},
+		{"blank line unchanged", "   ", "   ", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, shift := toggleLineComment(tt.line)
+			if got != tt.expected || shift != tt.wantShift {
+				t.Errorf("Expected %q (shift %d), got %q (shift %d)", tt.expected, tt.wantShift, got, shift)
+			}
+		})
+	}
+}
+
+func TestStripKQLComments(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"T // note", "T "},
+		{"// T\n| take 5", "\n| take 5"},
+		{`T | where Url == "https://example.com"`, `T | where Url == "https://example.com"`},
+		{`T | where s == 'a\'//b' // x`, `T | where s == 'a\'//b' `},
+	}
+
+	for _, tt := range tests {
+		if got := StripKQLComments(tt.query); got != tt.expected {
+			t.Errorf("StripKQLComments(%q): expected %q, got %q", tt.query, tt.expected, got)
+		}
+	}
+}
+
+func TestEnsureQueryLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"adds limit", "T", "T | take 100"},
+		{"keeps existing limit", "T | take 5", "T | take 5"},
+		{"commented-out limit doesn't count", "T\n// | take 5", "T\n// | take 5\n| take 100"},
+		{"trailing comment", "T // all rows", "T // all rows\n| take 100"},
+		{"limit before comment", "T\n| limit 5 // five", "T\n| limit 5 // five"},
+		{"nolimit directive", "T | summarize count() by Computer //nolimit", "T | summarize count() by Computer //nolimit"},
+		{"nolimit on its own line", "T\n// NoLimit \n| count", "T\n// NoLimit \n| count"},
+		{"nolimit in a longer comment", "T // nolimit please", "T // nolimit please\n| take 100"},
+		{"before render", "T | summarize count() by bin(TimeGenerated, 1h) | render timechart", "T | summarize count() by bin(TimeGenerated, 1h) | take 100 | render timechart"},
+		{"before render on its own line", "T\n| render piechart // share", "T\n| take 100\n| render piechart // share"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ensureQueryLimit(tt.query, 100); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsUnboundedQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected bool
+	}{
+		{"bare table", "SecurityEvent", true},
+		{"filter without time", "SecurityEvent | where EventID == 4625", true},
+		{"ago filter", "SecurityEvent | where TimeGenerated > ago(1h)", false},
+		{"between filter", "T | where TimeGenerated between (datetime(2024-01-01) .. datetime(2024-01-02))", false},
+		{"take", "T | take 10", false},
+		{"top", "T | top 5 by TimeGenerated", false},
+		{"commented-out filter doesn't count", "T\n// | where TimeGenerated > ago(1h)", true},
+		{"take inside a word doesn't count", "T | where Name == 'x' | project Mistake", true},
+		{"empty", "  ", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isUnboundedQuery(tt.query); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
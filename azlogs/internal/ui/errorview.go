@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// errorPreviewLines is how many lines of an error the status area shows
+// before pointing to the error detail view
+const errorPreviewLines = 3
+
+// prettyErrorJSON indents the JSON objects embedded in an error message, such
+// as the error details in Azure responses, leaving the rest of the text as is
+func prettyErrorJSON(text string) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(text, '{')
+		if i < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		b.WriteString(text[:i])
+
+		dec := json.NewDecoder(strings.NewReader(text[i:]))
+		var raw json.RawMessage
+		var out bytes.Buffer
+		if dec.Decode(&raw) != nil || json.Indent(&out, raw, "", "  ") != nil {
+			b.WriteByte('{')
+			text = text[i+1:]
+			continue
+		}
+		b.Write(out.Bytes())
+		text = text[i+int(dec.InputOffset()):]
+	}
+}
+
+// openErrorView shows the last error in full in a scrollable view
+func (m *Model) openErrorView() {
+	if m.lastError == "" {
+		m.notice = "No error to show"
+		return
+	}
+	m.errorView.SetContent(prettyErrorJSON(m.lastError))
+	m.currentView = ViewError
+}
+
+func (m Model) updateErrorView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keys.Close) {
+		m.currentView = ViewQuery
+		m.editor.Focus()
+		return m, nil
+	}
+	if key.Matches(msg, m.keys.CopyPanel) {
+		return m, copyToClipboard(prettyErrorJSON(m.lastError), "Error")
+	}
+
+	var cmd tea.Cmd
+	m.errorView, cmd = m.errorView.Update(msg)
+	return m, cmd
+}
+
+func (m Model) renderErrorView() string {
+	content := m.styles.Header.Render("Error Details") + "\n\n" + m.errorView.View()
+	if m.errorView.Scrollable() {
+		content += "\n\n" + m.styles.Muted.Render(m.errorView.ScrollInfo()+" · "+m.keys.Down.Help().Key+"/"+m.keys.Up.Help().Key+" to scroll")
+	}
+	content += "\n\n" + "Press " + m.keys.CopyPanel.Help().Key + " to copy, " + m.keys.Close.Help().Key + " to close."
+	return m.styles.Box.Render(content)
+}
+
+// renderErrorPreview renders the last error below the current view, cut to a
+// few lines that fit the terminal, with a pointer to the full error if cut
+func (m Model) renderErrorPreview() string {
+	lines := strings.Split("Error: "+m.lastError, "\n")
+	cut := len(lines) > errorPreviewLines
+	if cut {
+		lines = lines[:errorPreviewLines]
+	}
+	if m.width > 0 {
+		for i, line := range lines {
+			if runewidth.StringWidth(line) > m.width-2 {
+				lines[i] = runewidth.Truncate(line, m.width-2, "…")
+				cut = true
+			}
+		}
+	}
+
+	preview := m.styles.Error.Render(strings.Join(lines, "\n"))
+	if cut {
+		preview += "\n" + m.styles.Muted.Render("Press "+m.keys.ErrorDetail.Help().Key+" for the full error")
+	}
+	return preview
+}
@@ -0,0 +1,27 @@
+package ui
+
+import "testing"
+
+func TestPrettyErrorJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			"embedded object",
+			`RESPONSE 400: {"error":{"code":"SemanticError","innererror":{"line":1,"pos":7}}} (request id)`,
+			"RESPONSE 400: {\n  \"error\": {\n    \"code\": \"SemanticError\",\n    \"innererror\": {\n      \"line\": 1,\n      \"pos\": 7\n    }\n  }\n} (request id)",
+		},
+		{"unbalanced brace kept", `Syntax error near '{' at line 2`, `Syntax error near '{' at line 2`},
+		{"no json", "Query cannot be empty", "Query cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prettyErrorJSON(tt.text); got != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
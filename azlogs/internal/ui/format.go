@@ -0,0 +1,234 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+)
+
+// formatOperators are the tabular operators FormatKQL lowercases after a pipe
+var formatOperators = func() map[string]bool {
+	ops := map[string]bool{}
+	for _, op := range kqlOperators {
+		ops[op] = true
+	}
+	for _, op := range []string{
+		"project-away", "project-keep", "project-rename", "project-reorder",
+		"mv-apply", "top-nested", "top-hitters", "sample", "sample-distinct",
+		"getschema", "as", "lookup", "scan", "facet", "fork", "partition",
+		"reduce", "consume", "sort", "search", "find", "print",
+	} {
+		ops[op] = true
+	}
+	return ops
+}()
+
+// FormatKQL reformats a query for readability: whitespace is normalized,
+// each top-level pipe operator starts its own line, statements separated by
+// ';' are put on their own lines and operator names are lowercased. String
+// literals and comments are kept exactly as written, and pipes inside
+// parentheses (e.g. join subqueries) are left inline.
+func FormatKQL(query string) string {
+	f := kqlFormatter{}
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			f.pendingSpace = true
+			if r == '\n' {
+				f.newlineSeen = true
+			}
+			i++
+
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			// Comments on their own line stay on their own line
+			if f.newlineSeen {
+				f.flush()
+			}
+			end := i
+			for end < len(runes) && runes[end] != '\n' {
+				end++
+			}
+			f.pendingSpace = true
+			f.write(strings.TrimRight(string(runes[i:end]), " \t\r"))
+			f.flush()
+			i = end
+
+		case r == '`' && i+2 < len(runes) && runes[i+1] == '`' && runes[i+2] == '`':
+			i = f.writeLiteral(runes, i, i+3, "```", false)
+
+		case r == '"' || r == '\'':
+			verbatim := i > 0 && runes[i-1] == '@'
+			i = f.writeLiteral(runes, i, i+1, string(r), verbatim)
+
+		case r == '(' || r == '[' || r == '{':
+			f.write(string(r))
+			f.depth++
+			i++
+
+		case r == ')' || r == ']' || r == '}':
+			f.pendingSpace = false
+			f.write(string(r))
+			if f.depth > 0 {
+				f.depth--
+			}
+			i++
+
+		case r == ',':
+			f.pendingSpace = false
+			f.write(",")
+			f.pendingSpace = true
+			i++
+
+		case r == '|' && f.depth == 0:
+			f.flush()
+			f.write("|")
+			f.pendingSpace = true
+			f.expectOperator = true
+			i++
+
+		case r == ';' && f.depth == 0:
+			f.pendingSpace = false
+			f.write(";")
+			f.flush()
+			f.inStatement = false
+			i++
+
+		case isWordRune(r):
+			end := i
+			for end < len(runes) && isWordRune(runes[end]) {
+				end++
+			}
+			// Hyphenated operators such as project-away or mv-expand
+			if f.expectOperator {
+				for end+1 < len(runes) && runes[end] == '-' && unicode.IsLetter(runes[end+1]) {
+					end++
+					for end < len(runes) && isWordRune(runes[end]) {
+						end++
+					}
+				}
+			}
+			f.writeWord(string(runes[i:end]))
+			i = end
+
+		default:
+			f.write(string(r))
+			i++
+		}
+	}
+
+	f.flush()
+	return strings.Join(f.lines, "\n")
+}
+
+// kqlFormatter accumulates formatted lines for FormatKQL
+type kqlFormatter struct {
+	lines          []string
+	line           strings.Builder
+	depth          int
+	pendingSpace   bool
+	newlineSeen    bool   // A line break was skipped since the last output
+	inStatement    bool   // Output has started for the current statement
+	expectOperator bool   // The next word follows a pipe
+	operator       string // Current tabular operator
+}
+
+// write appends text to the current line, inserting a pending space unless
+// the line is empty or ends with an opening bracket
+func (f *kqlFormatter) write(s string) {
+	if f.line.Len() == 0 {
+		// Continuation lines of a statement (e.g. after a comment) are indented
+		if f.inStatement && s != "|" && !strings.HasPrefix(s, "//") {
+			f.line.WriteString("  ")
+		}
+	} else if f.pendingSpace {
+		last := f.line.String()[f.line.Len()-1]
+		if last != '(' && last != '[' && last != '{' && last != ' ' {
+			f.line.WriteByte(' ')
+		}
+	}
+	f.pendingSpace = false
+	f.newlineSeen = false
+	f.line.WriteString(s)
+	if !strings.HasPrefix(s, "//") {
+		f.inStatement = true
+	}
+}
+
+// writeWord writes an identifier, lowercasing operator names
+func (f *kqlFormatter) writeWord(word string) {
+	lower := strings.ToLower(word)
+	switch {
+	case f.expectOperator:
+		if formatOperators[lower] {
+			word = lower
+		}
+		f.operator = lower
+		f.expectOperator = false
+	case lower == "by" && f.depth == 0 && (f.operator == "order" || f.operator == "sort" || f.operator == "summarize"):
+		word = lower
+	}
+	f.write(word)
+}
+
+// writeLiteral copies a string literal starting at start (whose opening
+// delimiter ends at bodyStart) unchanged, returning the index after it
+func (f *kqlFormatter) writeLiteral(runes []rune, start, bodyStart int, delim string, verbatim bool) int {
+	d := []rune(delim)
+	end := bodyStart
+	for end < len(runes) {
+		if !verbatim && len(d) == 1 && runes[end] == '\\' {
+			end += 2
+			continue
+		}
+		if hasRunesAt(runes, end, d) {
+			// Verbatim strings escape the delimiter by doubling it
+			if verbatim && hasRunesAt(runes, end+1, d) {
+				end += 2
+				continue
+			}
+			end += len(d)
+			break
+		}
+		end++
+	}
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	// A verbatim prefix (@) was already written as part of the code
+	if verbatim {
+		f.pendingSpace = false
+	}
+	f.write(string(runes[start:end]))
+	return end
+}
+
+// flush finishes the current line
+func (f *kqlFormatter) flush() {
+	line := strings.TrimRight(f.line.String(), " ")
+	if strings.TrimSpace(line) != "" {
+		f.lines = append(f.lines, line)
+	}
+	f.line.Reset()
+	f.pendingSpace = false
+}
+
+// hasRunesAt reports whether runes contains sub at index i
+func hasRunesAt(runes []rune, i int, sub []rune) bool {
+	if i+len(sub) > len(runes) {
+		return false
+	}
+	for j, r := range sub {
+		if runes[i+j] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// isWordRune reports whether r can be part of an identifier
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
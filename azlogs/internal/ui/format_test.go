@@ -0,0 +1,83 @@
+package ui
+
+import "testing"
+
+func TestFormatKQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "one-liner",
+			input: "AzureActivity | where Level == 'Error' | take 10",
+			want:  "AzureActivity\n| where Level == 'Error'\n| take 10",
+		},
+		{
+			name:  "normalizes whitespace",
+			input: "  AzureActivity\n\n   |   where   x  ==  1\n|take 5  ",
+			want:  "AzureActivity\n| where x == 1\n| take 5",
+		},
+		{
+			name:  "lowercases operators",
+			input: "T | WHERE x > 1 | Summarize count() BY Category | ORDER BY count_ DESC | Project-Away y",
+			want:  "T\n| where x > 1\n| summarize count() by Category\n| order by count_ DESC\n| project-away y",
+		},
+		{
+			name:  "preserves string literals",
+			input: `T | where Message == "a  |  b; // not a comment" and Path == @'C:\dir''s' | take 1`,
+			want:  "T\n| where Message == \"a  |  b; // not a comment\" and Path == @'C:\\dir''s'\n| take 1",
+		},
+		{
+			name:  "preserves escaped quotes",
+			input: `T | where Name == "say \"hi\" | there"`,
+			want:  "T\n| where Name == \"say \\\"hi\\\" | there\"",
+		},
+		{
+			name:  "preserves comments",
+			input: "// Errors today\nT   |  where x  // only x\n  and y\n| take 5",
+			want:  "// Errors today\nT\n| where x // only x\n  and y\n| take 5",
+		},
+		{
+			name:  "nested parentheses in summarize",
+			input: "T | summarize   avg(toint( Duration ) ) , dcount(User) by bin( TimeGenerated , 1h ) , Category",
+			want:  "T\n| summarize avg(toint(Duration)), dcount(User) by bin(TimeGenerated, 1h), Category",
+		},
+		{
+			name:  "join subquery stays inline",
+			input: "A | join kind=inner (B | where x == 1 | project Id, (Name)) on Id | take 10",
+			want:  "A\n| join kind=inner (B | where x == 1 | project Id, (Name)) on Id\n| take 10",
+		},
+		{
+			name:  "let statements",
+			input: "let threshold = 5; let T = (Logs | where Count > threshold); T | count",
+			want:  "let threshold = 5;\nlet T = (Logs | where Count > threshold);\nT\n| count",
+		},
+		{
+			name:  "multi-line string literal",
+			input: "print x = ```a  |\n  b``` | take 1",
+			want:  "print x = ```a  |\n  b```\n| take 1",
+		},
+		{
+			name:  "empty",
+			input: "   ",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatKQL(tt.input); got != tt.want {
+				t.Errorf("FormatKQL(%q)\ngot:\n%s\nwant:\n%s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatKQL_Idempotent(t *testing.T) {
+	input := "let x = 1; T | where a == \"b\" // note\n| summarize count() by bin(TimeGenerated, 5m) | join (U | take 1) on Id"
+	once := FormatKQL(input)
+	if twice := FormatKQL(once); twice != once {
+		t.Errorf("Expected formatting to be stable\nfirst:\n%s\nsecond:\n%s", once, twice)
+	}
+}
@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// identityColumns identify a single record, most specific first. _ItemId is
+// unique per record in every Log Analytics table; the others are narrowed
+// by TimeGenerated when the result has it
+var identityColumns = []string{"_ItemId", "Id", "RequestId", "CorrelationId", "OperationId"}
+
+// sourceTablePattern matches the table a query starts from
+var sourceTablePattern = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*(\||$)`)
+
+// sourceTable returns the table the query reads from, after any let
+// statements, or "" when it doesn't start from a single table
+func sourceTable(query string) string {
+	for _, stmt := range strings.Split(StripKQLComments(query), ";") {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" || strings.HasPrefix(stmt, "let ") {
+			continue
+		}
+		if m := sourceTablePattern.FindStringSubmatch(stmt); m != nil {
+			return m[1]
+		}
+		return ""
+	}
+	return ""
+}
+
+// fullRowQuery builds a query for every field of a result row's record,
+// looked up by an identifying column in the table the row came from. Union
+// results name the table in their Type column
+func fullRowQuery(query string, columns []azure.Column, row []interface{}) (string, error) {
+	index := make(map[string]int, len(columns))
+	for i, col := range columns {
+		index[col.Name] = i
+	}
+
+	table := sourceTable(query)
+	if i, ok := index["Type"]; ok && i < len(row) {
+		if name, ok := row[i].(string); ok && identifierPattern.MatchString(name) {
+			table = name
+		}
+	}
+	if table == "" {
+		return "", fmt.Errorf("can't tell which table the row came from")
+	}
+
+	for _, name := range identityColumns {
+		i, ok := index[name]
+		if !ok || i >= len(row) || row[i] == nil || row[i] == "" {
+			continue
+		}
+		selected := []int{i}
+		if t, ok := index["TimeGenerated"]; ok && name != "_ItemId" && t < len(row) && row[t] != nil {
+			selected = append(selected, t)
+		}
+		// take 2 tells a unique match from an ambiguous one
+		return fmt.Sprintf("%s\n| %s\n| take 2", kqlIdentifier(table), whereClause(columns, row, selected)), nil
+	}
+	return "", fmt.Errorf("no identifying column in the results: keep one of %s in the query", strings.Join(identityColumns, ", "))
+}
+
+// fullRecord is a row's whole record fetched by fetchFullRow, shown in row
+// detail in place of the selected result row
+type fullRecord struct {
+	columns []azure.Column
+	row     []interface{}
+}
+
+// fullRowMsg carries the result of fetching a row's full record
+type fullRowMsg struct {
+	result *azure.QueryResult
+	err    error
+}
+
+// fetchFullRow queries for the selected row's whole record, for when the
+// query projected columns away. The query runs on its own, leaving the
+// editor and results alone, and the record opens in row detail
+func (m Model) fetchFullRow() (tea.Model, tea.Cmd) {
+	idx := m.table.GetSelectedRowIndex()
+	if !m.connected || m.fetchingFullRow || idx < 0 || idx >= len(m.resultRows) {
+		return m, nil
+	}
+	query, err := fullRowQuery(m.lastQuery, m.resultColumns, m.resultRows[idx])
+	if err != nil {
+		m.lastError = "Can't fetch the full row: " + err.Error()
+		return m, nil
+	}
+
+	m.fetchingFullRow = true
+	m.notice = "Fetching the full record..."
+	timespan := timeSpanFor(m.activeTimeRange(), time.Now())
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(m.config.QueryTimeout)*time.Second)
+		defer cancel()
+		result, err := m.client.Query(ctx, query, timespan)
+		return fullRowMsg{result: result, err: err}
+	}
+}
+
+// updateFullRow opens the fetched record in row detail
+func (m Model) updateFullRow(msg fullRowMsg) (tea.Model, tea.Cmd) {
+	m.fetchingFullRow = false
+	m.notice = ""
+	if msg.err != nil {
+		m.lastError = "Can't fetch the full row: " + msg.err.Error()
+		return m, nil
+	}
+
+	var table azure.Table
+	if len(msg.result.Tables) > 0 {
+		table = msg.result.Tables[0]
+	}
+	switch {
+	case len(table.Rows) == 0:
+		m.lastError = "The record wasn't found; it may be outside the time range"
+		return m, nil
+	case len(table.Rows) > 1:
+		m.notice = "More than one record matched; showing the first"
+	}
+	m.fullRecord = &fullRecord{columns: table.Columns, row: table.Rows[0]}
+	m.detailSelected = nil
+	m.currentView = ViewRowDetail
+	m.detailScrollPos = 0
+	return m, nil
+}
+
+// detailRow returns the column names, types and formatted values shown in
+// row detail: the fetched full record, or else the selected result row
+func (m Model) detailRow() (columns, columnTypes, row []string) {
+	if m.fullRecord == nil {
+		return m.table.GetColumns(), m.table.GetColumnTypes(), m.table.GetSelectedRow()
+	}
+	for i, col := range m.fullRecord.columns {
+		columns = append(columns, col.Name)
+		columnTypes = append(columnTypes, col.Type)
+		var value interface{}
+		if i < len(m.fullRecord.row) {
+			value = m.fullRecord.row[i]
+		}
+		row = append(row, formatCell(value, col.Type))
+	}
+	return columns, columnTypes, row
+}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestSourceTable(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"SigninLogs | project UserPrincipalName", "SigninLogs"},
+		{"// failures\nAppRequests\n| where Success == false", "AppRequests"},
+		{"let since = ago(1h);\nAuditLogs | where TimeGenerated > since", "AuditLogs"},
+		{"union SigninLogs, AuditLogs | take 10", ""},
+		{"print now()", ""},
+	}
+
+	for _, tt := range tests {
+		if got := sourceTable(tt.query); got != tt.expected {
+			t.Errorf("sourceTable(%q): expected %q, got %q", tt.query, tt.expected, got)
+		}
+	}
+}
+
+func TestFullRowQuery(t *testing.T) {
+	columns := []azure.Column{
+		{Name: "TimeGenerated", Type: "datetime"},
+		{Name: "CorrelationId", Type: "string"},
+		{Name: "Type", Type: "string"},
+		{Name: "_ItemId", Type: "string"},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		row      []interface{}
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "item id",
+			query:    "SigninLogs | project TimeGenerated, CorrelationId, Type, _ItemId",
+			row:      []interface{}{"2024-01-01T00:00:00Z", "c1", "SigninLogs", "i1"},
+			expected: "SigninLogs\n| where _ItemId == \"i1\"\n| take 2",
+		},
+		{
+			name:     "correlation id narrowed by time",
+			query:    "SigninLogs | project TimeGenerated, CorrelationId",
+			row:      []interface{}{"2024-01-01T00:00:00Z", "c1", nil, nil},
+			expected: "SigninLogs\n| where CorrelationId == \"c1\" and TimeGenerated == datetime(2024-01-01T00:00:00Z)\n| take 2",
+		},
+		{
+			name:     "union uses the Type column",
+			query:    "union SigninLogs, AADNonInteractiveUserSignInLogs",
+			row:      []interface{}{nil, "c1", "AADNonInteractiveUserSignInLogs", nil},
+			expected: "AADNonInteractiveUserSignInLogs\n| where CorrelationId == \"c1\"\n| take 2",
+		},
+		{
+			name:    "no identifying column",
+			query:   "SigninLogs | project TimeGenerated",
+			row:     []interface{}{"2024-01-01T00:00:00Z", nil, nil, nil},
+			wantErr: true,
+		},
+		{
+			name:    "unknown table",
+			query:   "union SigninLogs, AuditLogs",
+			row:     []interface{}{nil, "c1", nil, nil},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fullRowQuery(tt.query, columns, tt.row)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestModel_UpdateFullRow(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	m := NewModel("ws-1", azure.AuthDefault, azure.NewConfig())
+	m.editor.SetValue("SigninLogs | project _ItemId")
+	m.processResults(&azure.QueryResult{Tables: []azure.Table{{
+		Columns: []azure.Column{{Name: "_ItemId", Type: "string"}},
+		Rows:    [][]interface{}{{"i1"}},
+	}}})
+	result := m.result
+	m.currentView = ViewRowDetail
+
+	model, _ := m.updateFullRow(fullRowMsg{result: &azure.QueryResult{Tables: []azure.Table{{
+		Columns: []azure.Column{{Name: "_ItemId", Type: "string"}, {Name: "UserPrincipalName", Type: "string"}},
+		Rows:    [][]interface{}{{"i1", "ada@contoso.com"}},
+	}}}})
+	m = model.(Model)
+	if m.editor.Value() != "SigninLogs | project _ItemId" || m.result != result {
+		t.Error("Expected the editor and results left alone")
+	}
+	if columns, _, row := m.detailRow(); len(columns) != 2 || row[1] != "ada@contoso.com" {
+		t.Errorf("Expected the full record in row detail, got %v %v", columns, row)
+	}
+
+	// Leaving row detail goes back to the result row
+	model, _ = m.updateRowDetailView(tea.KeyMsg{Type: tea.KeyEsc})
+	m = model.(Model)
+	if columns, _, _ := m.detailRow(); m.fullRecord != nil || len(columns) != 1 {
+		t.Errorf("Expected the selected result row again, got %v", columns)
+	}
+
+	model, _ = m.updateFullRow(fullRowMsg{result: &azure.QueryResult{Tables: []azure.Table{{}}}})
+	if m = model.(Model); m.fullRecord != nil || m.lastError == "" {
+		t.Error("Expected an error when the record wasn't found")
+	}
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// fuzzyBaseScore keeps fuzzy matches below prefix matches (100)
+	fuzzyBaseScore = 90
+	// fuzzyGapPenalty is subtracted for each character skipped between matches
+	fuzzyGapPenalty = 3
+)
+
+// fuzzyScore reports whether pattern is a case-insensitive subsequence of
+// candidate and scores the match. Characters skipped before the first match
+// cost one point each and gaps between matched characters cost
+// fuzzyGapPenalty each, so "sigin" ranks SigninLogs above SecurityIncident.
+func fuzzyScore(candidate, pattern string) (int, bool) {
+	if pattern == "" {
+		return 0, false
+	}
+
+	p := []rune(strings.ToLower(pattern))
+	score := fuzzyBaseScore
+	pi, last := 0, -1
+
+	for ci, r := range []rune(candidate) {
+		if pi == len(p) {
+			break
+		}
+		if unicode.ToLower(r) != p[pi] {
+			continue
+		}
+		if last < 0 {
+			score -= ci
+		} else {
+			score -= (ci - last - 1) * fuzzyGapPenalty
+		}
+		last = ci
+		pi++
+	}
+
+	if pi < len(p) {
+		return 0, false
+	}
+	return max(score, 1), true
+}
+
+// matchPositions returns the indexes of the runes of candidate that pattern
+// matches case-insensitively, picked left to right as fuzzyScore does, so a
+// prefix match highlights the prefix. It returns nil when pattern doesn't
+// match.
+func matchPositions(candidate, pattern string) []int {
+	p := []rune(strings.ToLower(pattern))
+	if len(p) == 0 {
+		return nil
+	}
+
+	var positions []int
+	for ci, r := range []rune(candidate) {
+		if len(positions) == len(p) {
+			break
+		}
+		if unicode.ToLower(r) == p[len(positions)] {
+			positions = append(positions, ci)
+		}
+	}
+	if len(positions) < len(p) {
+		return nil
+	}
+	return positions
+}
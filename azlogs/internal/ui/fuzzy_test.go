@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		candidate string
+		pattern   string
+		wantMatch bool
+	}{
+		{"SigninLogs", "sigin", true},
+		{"SigninLogs", "slogs", true},
+		{"AzureDiagnostics", "azdiag", true},
+		{"SigninLogs", "logsin", false},
+		{"SigninLogs", "", false},
+	}
+
+	for _, tt := range tests {
+		score, ok := fuzzyScore(tt.candidate, tt.pattern)
+		if ok != tt.wantMatch {
+			t.Errorf("fuzzyScore(%q, %q): expected match %v, got %v", tt.candidate, tt.pattern, tt.wantMatch, ok)
+		}
+		if ok && (score < 1 || score >= 100) {
+			t.Errorf("fuzzyScore(%q, %q): expected score in [1, 100), got %d", tt.candidate, tt.pattern, score)
+		}
+	}
+}
+
+func TestFuzzyScore_PrefersTighterMatches(t *testing.T) {
+	tight, _ := fuzzyScore("SigninLogs", "sigin")
+	loose, _ := fuzzyScore("SecurityIncident", "sigin")
+	if tight <= loose {
+		t.Errorf("Expected SigninLogs (%d) to outscore SecurityIncident (%d)", tight, loose)
+	}
+}
+
+func TestAutocompleteEngine_FuzzyFallback(t *testing.T) {
+	e := NewAutocompleteEngine()
+	e.SetTables([]string{"SecurityIncident", "SigninLogs", "Syslog"})
+	e.SetSchemas(map[string][]azure.Column{
+		"SigninLogs": {{Name: "UserPrincipalName", Type: "string"}, {Name: "ResultType", Type: "string"}},
+	})
+
+	tables := e.getTableSuggestions("sigin")
+	if len(tables) == 0 || tables[0].Text != "SigninLogs" {
+		t.Errorf("Expected SigninLogs as a fuzzy table match, got %v", tables)
+	}
+
+	// Prefix matches suppress fuzzy ones
+	tables = e.getTableSuggestions("sy")
+	if len(tables) != 1 || tables[0].Text != "Syslog" {
+		t.Errorf("Expected only the prefix match Syslog, got %v", tables)
+	}
+
+	columns := e.getColumnSuggestions([]string{"SigninLogs"}, "upn")
+	if len(columns) != 1 || columns[0].Text != "UserPrincipalName" {
+		t.Errorf("Expected UserPrincipalName as a fuzzy column match, got %v", columns)
+	}
+}
+
+func TestMatchPositions(t *testing.T) {
+	tests := []struct {
+		candidate string
+		pattern   string
+		expected  []int
+	}{
+		{"SigninLogs", "sig", []int{0, 1, 2}},
+		{"SigninLogs", "slogs", []int{0, 6, 7, 8, 9}},
+		{"SigninLogs", "logsin", nil},
+		{"SigninLogs", "", nil},
+	}
+
+	for _, tt := range tests {
+		if got := matchPositions(tt.candidate, tt.pattern); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("matchPositions(%q, %q): expected %v, got %v", tt.candidate, tt.pattern, tt.expected, got)
+		}
+	}
+}
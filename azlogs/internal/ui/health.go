@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// healthCheckInterval is how often the connection is checked, and how often
+// reconnecting is retried once it has been lost
+const healthCheckInterval = 2 * time.Minute
+
+// healthTickMsg triggers the next connection check
+type healthTickMsg struct{}
+
+// healthMsg reports the result of a connection check
+type healthMsg struct {
+	err error
+}
+
+// healthTick schedules the next connection check
+func healthTick() tea.Cmd {
+	return tea.Tick(healthCheckInterval, func(_ time.Time) tea.Msg {
+		return healthTickMsg{}
+	})
+}
+
+// checkHealth verifies that the credential can still get a token
+func (m *Model) checkHealth() tea.Cmd {
+	auth := m.auth
+	return func() tea.Msg {
+		if auth == nil {
+			return healthMsg{err: fmt.Errorf("not connected")}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		return healthMsg{err: auth.Validate(ctx)}
+	}
+}
+
+// startReconnect marks the connection as lost and connects again
+func (m *Model) startReconnect() tea.Cmd {
+	m.connected = false
+	m.reconnecting = true
+	if m.connecting {
+		return nil // A connection attempt is already in flight
+	}
+	m.connecting = true
+	return m.Connect(m.authMethod)
+}
+
+// updateHealth handles connection check messages
+func (m Model) updateHealth(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case healthTickMsg:
+		switch {
+		case m.reconnecting:
+			return m, tea.Batch(healthTick(), m.startReconnect())
+		case m.connected:
+			return m, tea.Batch(healthTick(), m.checkHealth())
+		}
+		m.healthTicking = false
+		return m, nil
+
+	case healthMsg:
+		if msg.err != nil {
+			if m.reconnecting {
+				return m, nil // Retried on the next tick
+			}
+			m.lastError = "Connection lost, reconnecting: " + msg.err.Error()
+			azure.Logger().Warn("connection lost, reconnecting", "error", msg.err)
+			return m, m.startReconnect()
+		}
+		if m.reconnecting {
+			m.reconnecting = false
+			m.connected = true
+			m.lastError = ""
+			azure.Logger().Info("reconnected")
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
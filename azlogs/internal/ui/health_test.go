@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestModel_HealthTick(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	tests := []struct {
+		name         string
+		connected    bool
+		reconnecting bool
+		connecting   bool
+		wantCmd      bool
+		wantTicking  bool
+	}{
+		{"connected checks the credential", true, false, false, true, true},
+		{"reconnecting retries", false, true, false, true, true},
+		{"reconnect already in flight keeps ticking", false, true, true, true, true},
+		{"disconnected stops ticking", false, false, false, false, false},
+	}
+
+	for _, tt := range tests {
+		m := NewModel("ws-1", azure.AuthDefault, azure.NewConfig())
+		m.connected, m.reconnecting, m.connecting = tt.connected, tt.reconnecting, tt.connecting
+		m.healthTicking = true
+
+		model, cmd := m.updateHealth(healthTickMsg{})
+		m = model.(Model)
+		if (cmd != nil) != tt.wantCmd {
+			t.Errorf("%s: expected a command %v, got %v", tt.name, tt.wantCmd, cmd != nil)
+		}
+		if m.healthTicking != tt.wantTicking {
+			t.Errorf("%s: expected ticking %v, got %v", tt.name, tt.wantTicking, m.healthTicking)
+		}
+	}
+}
+
+func TestModel_HealthCheck(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	m := NewModel("ws-1", azure.AuthDefault, azure.NewConfig())
+	m.connected, m.connecting = true, false
+
+	// A failed check while connected starts reconnecting
+	model, cmd := m.updateHealth(healthMsg{err: errors.New("token expired")})
+	m = model.(Model)
+	if cmd == nil || !m.reconnecting || m.connected || !m.connecting {
+		t.Fatalf("Expected a reconnect to start, got reconnecting %v, connected %v, connecting %v", m.reconnecting, m.connected, m.connecting)
+	}
+	if !strings.Contains(m.lastError, "token expired") {
+		t.Errorf("Expected the error to be shown, got %q", m.lastError)
+	}
+
+	// Further failures while reconnecting wait for the next tick
+	m.connecting = false
+	model, cmd = m.updateHealth(healthMsg{err: errors.New("token expired")})
+	m = model.(Model)
+	if cmd != nil || m.connecting {
+		t.Error("Expected no reconnect until the next tick")
+	}
+
+	// A passing check completes the reconnect
+	model, _ = m.updateHealth(healthMsg{})
+	m = model.(Model)
+	if m.reconnecting || !m.connected || m.lastError != "" {
+		t.Errorf("Expected to be connected again, got reconnecting %v, connected %v, error %q", m.reconnecting, m.connected, m.lastError)
+	}
+}
+
+func TestModel_CheckHealthWithoutAuth(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	m := NewModel("ws-1", azure.AuthDefault, azure.NewConfig())
+	msg, ok := m.checkHealth()().(healthMsg)
+	if !ok || msg.err == nil {
+		t.Errorf("Expected a failed check without a credential, got %+v", msg)
+	}
+}
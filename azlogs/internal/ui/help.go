@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// helpSection lists the bindings that apply in one view
+type helpSection struct {
+	title    string
+	views    []View // Views the section applies to, listed first when active
+	bindings []key.Binding
+	extras   [][2]string // Non-keyboard actions such as mouse input
+}
+
+// helpSections groups the key map by the views its bindings are handled in
+func (k *KeyMap) helpSections() []helpSection {
+	return []helpSection{
+		{
+			title: "GLOBAL",
+			bindings: []key.Binding{
+				k.Help, k.History, k.Workspace, k.Templates, k.TimeRange,
+				k.SchemaExplorer, k.Bookmarks, k.Catalog, k.Bookmark, k.ErrorDetail, k.OpenPortal, k.Back, k.Quit,
+			},
+		},
+		{
+			title: "QUERY EDITOR",
+			views: []View{ViewQuery},
+			bindings: []key.Binding{
+				k.Execute, k.ForceExecute, k.SwitchPane, k.AISuggest, k.ShowReference, k.RecentTables, k.SaveTemplate,
+				k.ClearEditor, k.FormatQuery, k.ToggleComment, k.DuplicateLine,
+				k.MoveLineUp, k.MoveLineDown, k.DeleteLine, k.PasteQuery, k.PasteAndRun,
+				k.Undo, k.Redo, k.HistoryPrev, k.HistoryNext,
+			},
+		},
+		{
+			title:    "SUGGESTIONS",
+			views:    []View{ViewQuery},
+			bindings: []key.Binding{k.SuggestionPrev, k.SuggestionNext, k.AcceptSuggestion},
+		},
+		{
+			title: "RESULTS TABLE",
+			views: []View{ViewResults},
+			bindings: []key.Binding{
+				k.Up, k.Down, k.Left, k.Right, k.PageUp, k.PageDown, k.Top, k.Bottom,
+				withDesc(k.Select, "View row details (full content)"), k.FreezeColumn, k.WidenColumns, k.NarrowColumns, k.WidenColumn, k.NarrowColumn, k.AutoFitColumns,
+				k.HideColumn, k.ShowColumns, k.ProjectColumns, k.CopyProject, k.ProjectAway, k.CopyColumn, k.CopyCell, k.CopyMarkdown, k.CopyText, k.InsertIn, k.CopyIn, k.SaveVariable,
+				k.SaveSnapshot, k.SaveReport, k.Baseline, k.ToggleChart, k.ChartX, k.ChartY,
+			},
+			extras: [][2]string{
+				{"Mouse wheel", "Scroll rows"},
+				{"Click", "Select row (click again for details)"},
+			},
+		},
+		{
+			title:    "ROW DETAILS",
+			views:    []View{ViewRowDetail},
+			bindings: []key.Binding{k.Up, k.Down, k.Top, k.Bottom, k.ToggleEmpty, k.ToggleField, k.InsertWhere, k.CopyWhere, withDesc(k.CopyPanel, "Copy all fields as text"), k.FetchFullRow, k.Close},
+		},
+		{
+			title:    "ERROR DETAILS",
+			views:    []View{ViewError},
+			bindings: []key.Binding{k.Up, k.Down, withDesc(k.CopyPanel, "Copy the full error"), k.Close},
+		},
+		{
+			title:    "HISTORY",
+			views:    []View{ViewHistory},
+			bindings: []key.Binding{k.Up, k.Down, k.PageUp, k.PageDown, withDesc(k.Select, "Load query into editor"), k.AppendQuery, k.CopyHistory, k.ClearHistory, k.Confirm},
+		},
+		{
+			title:    "TEMPLATES",
+			views:    []View{ViewTemplates},
+			bindings: []key.Binding{k.Up, k.Down, withDesc(k.Select, "Load query into editor"), k.NewTemplate, k.Delete},
+		},
+		{
+			title:    "BOOKMARKS",
+			views:    []View{ViewBookmarks},
+			bindings: []key.Binding{k.Up, k.Down, withDesc(k.Select, "Load query into editor"), k.NewBookmark, k.Delete},
+		},
+		{
+			title:    "QUERY CATALOG",
+			views:    []View{ViewCatalog},
+			bindings: []key.Binding{k.Up, k.Down, withDesc(k.Select, "Load query into editor"), withDesc(k.NewTemplate, "Copy query to templates")},
+		},
+		{
+			title: "SCHEMA EXPLORER",
+			views: []View{ViewSchema},
+			bindings: []key.Binding{
+				withDesc(k.SuggestionPrev, "Previous table"), withDesc(k.SuggestionNext, "Next table"),
+				withDesc(k.Select, "Insert table name into the query"),
+			},
+			extras: [][2]string{{"Typing", "Filter tables"}},
+		},
+	}
+}
+
+// withDesc returns a copy of a binding described for a particular view
+func withDesc(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// kqlQuickReference is appended to the generated help
+const kqlQuickReference = `KQL QUICK REFERENCE
+  TableName | take 10              Fetch 10 rows
+  TableName | where Column == "x"  Filter rows
+  TableName | project Col1, Col2   Select columns
+  TableName | summarize count()    Aggregate data
+  TableName | order by Time desc   Sort results
+`
+
+// buildHelp renders the help view content from the key map, listing the
+// section for the view help was opened from first
+func buildHelp(keys *KeyMap, active View) string {
+	sections := keys.helpSections()
+	var current, rest []helpSection
+	for _, s := range sections {
+		if containsView(s.views, active) {
+			current = append(current, s)
+		} else {
+			rest = append(rest, s)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("AZURE LOG ANALYTICS CLI - HELP\n\n")
+	for _, s := range append(current, rest...) {
+		title := s.title
+		if containsView(s.views, active) {
+			title += " (current view)"
+		}
+		b.WriteString(title + "\n")
+
+		var lines [][2]string
+		for _, binding := range s.bindings {
+			if !binding.Enabled() {
+				continue
+			}
+			lines = append(lines, [2]string{binding.Help().Key, binding.Help().Desc})
+		}
+		lines = append(lines, s.extras...)
+		for _, line := range lines {
+			fmt.Fprintf(&b, "  %-18s %s\n", line[0], line[1])
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(kqlQuickReference)
+	return b.String()
+}
+
+// containsView reports whether views includes v
+func containsView(views []View, v View) bool {
+	for _, view := range views {
+		if view == v {
+			return true
+		}
+	}
+	return false
+}
+
+// keyNames are display names for keys whose bubbletea names aren't readable
+var keyNames = map[string]string{
+	" ":      "Space",
+	"ctrl+@": "Ctrl+Space",
+	"ctrl+ ": "Ctrl+Space",
+	"ctrl+_": "Ctrl+/", // Terminals send Ctrl+/ as Ctrl+_
+	"pgup":   "PgUp",
+	"pgdown": "PgDown",
+	"esc":    "Esc",
+}
+
+// keyLabel returns the display name of a bubbletea key name, e.g. "ctrl+r"
+// as "Ctrl+R" and "alt+F" as "Shift+Alt+F"
+func keyLabel(k string) string {
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	// Plain characters are shown as typed (G is Shift+G)
+	if len(k) == 1 {
+		return k
+	}
+
+	parts := strings.Split(k, "+")
+	last := parts[len(parts)-1]
+	var prefix []string
+	for _, mod := range parts[:len(parts)-1] {
+		prefix = append(prefix, strings.ToUpper(mod[:1])+mod[1:])
+	}
+
+	switch {
+	case len(last) == 1 && unicode.IsUpper(rune(last[0])):
+		prefix = append([]string{"Shift"}, prefix...)
+	case len(last) == 1:
+		last = strings.ToUpper(last)
+	case len(last) <= 3 && last[0] == 'f':
+		last = strings.ToUpper(last)
+	default:
+		last = strings.ToUpper(last[:1]) + last[1:]
+	}
+	return strings.Join(append(prefix, last), "+")
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeyLabel(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"ctrl+r", "Ctrl+R"},
+		{"f5", "F5"},
+		{"alt+F", "Shift+Alt+F"},
+		{"alt+up", "Alt+Up"},
+		{"ctrl+enter", "Ctrl+Enter"},
+		{"ctrl+_", "Ctrl+/"},
+		{"pgdown", "PgDown"},
+		{" ", "Space"},
+		{"G", "G"},
+		{"+", "+"},
+	}
+
+	for _, tt := range tests {
+		if got := keyLabel(tt.key); got != tt.expected {
+			t.Errorf("keyLabel(%q): expected %q, got %q", tt.key, tt.expected, got)
+		}
+	}
+}
+
+func TestBuildHelp_ReflectsKeyBindings(t *testing.T) {
+	keys := DefaultKeyMap()
+	if err := keys.Apply(map[string][]string{"execute": {"ctrl+x"}}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	help := buildHelp(keys, ViewQuery)
+	if !strings.Contains(help, "Ctrl+X ") || strings.Contains(help, "Ctrl+Enter") {
+		t.Errorf("Expected help to show the overridden execute key:\n%s", help)
+	}
+}
+
+func TestModel_FooterReflectsKeyBindings(t *testing.T) {
+	m := Model{keys: DefaultKeyMap(), styles: DefaultStyles()}
+	if err := m.keys.Apply(map[string][]string{"execute": {"ctrl+x"}, "clearHistory": {"D"}, "back": {"ctrl+g"}}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	m.currentView = ViewQuery
+	if footer := m.renderFooter(); !strings.Contains(footer, "Ctrl+X") || strings.Contains(footer, "F5") {
+		t.Errorf("Expected the footer to show the overridden execute key, got %q", footer)
+	}
+	m.currentView = ViewHistory
+	if footer := m.renderFooter(); !strings.Contains(footer, "D") || strings.Contains(footer, "X") {
+		t.Errorf("Expected the footer to show the overridden clear key, got %q", footer)
+	}
+	if hint := m.promptHint("save"); hint != "Press Enter to save, Ctrl+G to cancel" {
+		t.Errorf("Expected the prompt hint to show the overridden back key, got %q", hint)
+	}
+}
+
+func TestBuildHelp_CurrentViewFirst(t *testing.T) {
+	help := buildHelp(DefaultKeyMap(), ViewHistory)
+	first := strings.SplitN(help, "\n", 4)[2]
+	if first != "HISTORY (current view)" {
+		t.Errorf("Expected the history section first, got %q", first)
+	}
+}
@@ -0,0 +1,327 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyMap defines the keybindings for every action in the application
+type KeyMap struct {
+	// Global
+	Quit           key.Binding
+	Help           key.Binding
+	History        key.Binding
+	Workspace      key.Binding
+	Templates      key.Binding
+	TimeRange      key.Binding
+	Bookmarks      key.Binding
+	Catalog        key.Binding
+	SchemaExplorer key.Binding
+	Bookmark       key.Binding
+	ErrorDetail    key.Binding
+	OpenPortal     key.Binding
+	Back           key.Binding
+
+	// Query editor
+	Execute       key.Binding
+	ForceExecute  key.Binding
+	SwitchPane    key.Binding
+	AISuggest     key.Binding
+	ShowReference key.Binding
+	RecentTables  key.Binding
+	ClearEditor   key.Binding
+	FormatQuery   key.Binding
+	ToggleComment key.Binding
+	DuplicateLine key.Binding
+	MoveLineUp    key.Binding
+	MoveLineDown  key.Binding
+	DeleteLine    key.Binding
+	PasteQuery    key.Binding
+	PasteAndRun   key.Binding
+	Undo          key.Binding
+	Redo          key.Binding
+	SaveTemplate  key.Binding
+	HistoryPrev   key.Binding
+	HistoryNext   key.Binding
+
+	// Suggestion popup
+	SuggestionPrev   key.Binding
+	SuggestionNext   key.Binding
+	AcceptSuggestion key.Binding
+
+	// Navigation (results, lists and detail views)
+	Up       key.Binding
+	Down     key.Binding
+	Left     key.Binding
+	Right    key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+	Select   key.Binding
+	Close    key.Binding
+
+	// Results table
+	FreezeColumn   key.Binding
+	WidenColumns   key.Binding
+	NarrowColumns  key.Binding
+	WidenColumn    key.Binding
+	NarrowColumn   key.Binding
+	AutoFitColumns key.Binding
+	HideColumn     key.Binding
+	ShowColumns    key.Binding
+	ProjectColumns key.Binding
+	CopyProject    key.Binding
+	ProjectAway    key.Binding
+	CopyColumn     key.Binding
+	CopyCell       key.Binding
+	CopyMarkdown   key.Binding
+	CopyText       key.Binding
+	SaveVariable   key.Binding
+	InsertIn       key.Binding
+	CopyIn         key.Binding
+	SaveSnapshot   key.Binding
+	SaveReport     key.Binding
+	Baseline       key.Binding
+	ToggleChart    key.Binding
+	ChartX         key.Binding
+	ChartY         key.Binding
+
+	// Row detail
+	ToggleEmpty  key.Binding
+	ToggleField  key.Binding
+	InsertWhere  key.Binding
+	CopyWhere    key.Binding
+	CopyPanel    key.Binding
+	FetchFullRow key.Binding
+
+	// Templates
+	Delete      key.Binding
+	NewTemplate key.Binding
+
+	// Bookmarks
+	NewBookmark key.Binding
+
+	// History
+	ClearHistory key.Binding
+	CopyHistory  key.Binding
+	AppendQuery  key.Binding
+	Confirm      key.Binding
+}
+
+// DefaultKeyMap returns the default keybindings
+func DefaultKeyMap() *KeyMap {
+	return &KeyMap{
+		Quit:           key.NewBinding(key.WithKeys("ctrl+c", "ctrl+q"), key.WithHelp("Ctrl+Q", "Quit")),
+		Help:           key.NewBinding(key.WithKeys("f1"), key.WithHelp("F1", "Show help")),
+		History:        key.NewBinding(key.WithKeys("f2"), key.WithHelp("F2", "Show query history")),
+		Workspace:      key.NewBinding(key.WithKeys("f3"), key.WithHelp("F3", "Change workspace")),
+		Templates:      key.NewBinding(key.WithKeys("f4"), key.WithHelp("F4", "Show saved templates")),
+		TimeRange:      key.NewBinding(key.WithKeys("f7"), key.WithHelp("F7", "Select time range")),
+		Bookmarks:      key.NewBinding(key.WithKeys("f9"), key.WithHelp("F9", "Show bookmarks")),
+		Catalog:        key.NewBinding(key.WithKeys("f10", "alt+l"), key.WithHelp("F10", "Browse the catalog of common queries")),
+		SchemaExplorer: key.NewBinding(key.WithKeys("f8"), key.WithHelp("F8", "Explore tables and their columns")),
+		Bookmark:       key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("Ctrl+O", "Bookmark query with a note")),
+		ErrorDetail:    key.NewBinding(key.WithKeys("alt+e"), key.WithHelp("Alt+E", "Show the full last error")),
+		OpenPortal:     key.NewBinding(key.WithKeys("alt+o"), key.WithHelp("Alt+O", "Open the query in the Azure portal")),
+		Back:           key.NewBinding(key.WithKeys("esc"), key.WithHelp("Esc", "Return to query view / dismiss suggestion")),
+
+		Execute:       key.NewBinding(key.WithKeys("ctrl+enter", "f5"), key.WithHelp("F5", "Execute query (recent results are served from cache)")),
+		ForceExecute:  key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("Ctrl+R", "Execute query, bypassing the cache")),
+		SwitchPane:    key.NewBinding(key.WithKeys("tab"), key.WithHelp("Tab", "Accept AI suggestion, or switch between editor and results")),
+		AISuggest:     key.NewBinding(key.WithKeys("ctrl+@", "ctrl+ ", "alt+s"), key.WithHelp("Ctrl+Space", "AI query suggestion (Azure OpenAI)")),
+		ShowReference: key.NewBinding(key.WithKeys("alt+k"), key.WithHelp("Alt+K", "Show reference for the KQL operator or function under the cursor")),
+		RecentTables:  key.NewBinding(key.WithKeys("alt+t"), key.WithHelp("Alt+T", "Insert a recently queried table")),
+		ClearEditor:   key.NewBinding(key.WithKeys("ctrl+l"), key.WithHelp("Ctrl+L", "Clear editor")),
+		FormatQuery:   key.NewBinding(key.WithKeys("alt+F"), key.WithHelp("Shift+Alt+F", "Format query (one pipe operator per line)")),
+		// Terminals send Ctrl+/ as Ctrl+_
+		ToggleComment: key.NewBinding(key.WithKeys("ctrl+_"), key.WithHelp("Ctrl+/", "Comment/uncomment line")),
+		DuplicateLine: key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("Ctrl+D", "Duplicate line")),
+		MoveLineUp:    key.NewBinding(key.WithKeys("alt+up"), key.WithHelp("Alt+Up", "Move line up")),
+		MoveLineDown:  key.NewBinding(key.WithKeys("alt+down"), key.WithHelp("Alt+Down", "Move line down")),
+		DeleteLine:    key.NewBinding(key.WithKeys("ctrl+k"), key.WithHelp("Ctrl+K", "Delete line")),
+		PasteQuery:    key.NewBinding(key.WithKeys("alt+v"), key.WithHelp("Alt+V", "Replace query with clipboard")),
+		PasteAndRun:   key.NewBinding(key.WithKeys("alt+V"), key.WithHelp("Shift+Alt+V", "Run query from clipboard")),
+		Undo:          key.NewBinding(key.WithKeys("ctrl+z"), key.WithHelp("Ctrl+Z", "Undo")),
+		Redo:          key.NewBinding(key.WithKeys("ctrl+y"), key.WithHelp("Ctrl+Y", "Redo")),
+		SaveTemplate:  key.NewBinding(key.WithKeys("ctrl+s", "f6"), key.WithHelp("Ctrl+S", "Save query as template")),
+		HistoryPrev:   key.NewBinding(key.WithKeys("ctrl+up"), key.WithHelp("Ctrl+Up", "Previous query from history")),
+		HistoryNext:   key.NewBinding(key.WithKeys("ctrl+down"), key.WithHelp("Ctrl+Down", "Next query from history")),
+
+		SuggestionPrev:   key.NewBinding(key.WithKeys("up", "ctrl+p"), key.WithHelp("Up", "Previous suggestion")),
+		SuggestionNext:   key.NewBinding(key.WithKeys("down", "ctrl+n"), key.WithHelp("Down", "Next suggestion")),
+		AcceptSuggestion: key.NewBinding(key.WithKeys("tab", "enter"), key.WithHelp("Tab", "Accept suggestion")),
+
+		Up:       key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("k", "Move up")),
+		Down:     key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("j", "Move down")),
+		Left:     key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("h", "Scroll left")),
+		Right:    key.NewBinding(key.WithKeys("right", "l"), key.WithHelp("l", "Scroll right")),
+		PageUp:   key.NewBinding(key.WithKeys("pgup"), key.WithHelp("PgUp", "Page up")),
+		PageDown: key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("PgDown", "Page down")),
+		Top:      key.NewBinding(key.WithKeys("home", "g"), key.WithHelp("g", "Jump to start")),
+		Bottom:   key.NewBinding(key.WithKeys("end", "G"), key.WithHelp("G", "Jump to end")),
+		Select:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("Enter", "Select")),
+		Close:    key.NewBinding(key.WithKeys("enter", "q"), key.WithHelp("q", "Close")),
+
+		FreezeColumn:   key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "Freeze/unfreeze column")),
+		WidenColumns:   key.NewBinding(key.WithKeys("+"), key.WithHelp("+", "Widen max column width")),
+		NarrowColumns:  key.NewBinding(key.WithKeys("-"), key.WithHelp("-", "Narrow max column width")),
+		WidenColumn:    key.NewBinding(key.WithKeys(">"), key.WithHelp(">", "Widen the current column")),
+		NarrowColumn:   key.NewBinding(key.WithKeys("<"), key.WithHelp("<", "Narrow the current column")),
+		AutoFitColumns: key.NewBinding(key.WithKeys("="), key.WithHelp("=", "Toggle auto-fit column widths")),
+		HideColumn:     key.NewBinding(key.WithKeys("H"), key.WithHelp("H", "Hide the leftmost visible column")),
+		ShowColumns:    key.NewBinding(key.WithKeys("U"), key.WithHelp("U", "Show all hidden columns")),
+		ProjectColumns: key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "Add project clause of the shown columns to query")),
+		CopyProject:    key.NewBinding(key.WithKeys("P"), key.WithHelp("P", "Copy project clause of the shown columns")),
+		ProjectAway:    key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "Add project-away of the hidden columns to query, before its limit")),
+		CopyColumn:     key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "Copy the leftmost visible column's values, one per line")),
+		CopyCell:       key.NewBinding(key.WithKeys("Y"), key.WithHelp("Y", "Copy the highlighted cell's full value")),
+		CopyMarkdown:   key.NewBinding(key.WithKeys("M"), key.WithHelp("M", "Copy the results as a Markdown table")),
+		CopyText:       key.NewBinding(key.WithKeys("T"), key.WithHelp("T", "Copy the results as aligned text")),
+		SaveVariable:   key.NewBinding(key.WithKeys("V"), key.WithHelp("V", "Save the column's distinct values as a {{variable}} for later queries")),
+		InsertIn:       key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "Add where ... in () clause of the column's distinct values to query")),
+		CopyIn:         key.NewBinding(key.WithKeys("I"), key.WithHelp("I", "Copy where ... in () clause of the column's distinct values")),
+		SaveSnapshot:   key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "Save results as a snapshot to reload later")),
+		SaveReport:     key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "Save the query and results as an HTML report")),
+		ToggleChart:    key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "Show/hide chart")),
+		ChartX:         key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "Chart the next x axis column")),
+		ChartY:         key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "Chart the next numeric column")),
+		Baseline:       key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "Set/clear diff baseline (rows keyed on the frozen column)")),
+
+		ToggleEmpty:  key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "Show/hide empty fields")),
+		ToggleField:  key.NewBinding(key.WithKeys(" "), key.WithHelp("Space", "Select field for where clause")),
+		InsertWhere:  key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "Add where clause to query")),
+		CopyWhere:    key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "Copy where clause")),
+		CopyPanel:    key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "Copy all the text shown as plain text")),
+		FetchFullRow: key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "Fetch the full record, with every column")),
+
+		Delete:      key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "Delete")),
+		NewTemplate: key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "New template from query")),
+
+		NewBookmark: key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "Bookmark the current query")),
+
+		ClearHistory: key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "Clear all history")),
+		CopyHistory:  key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "Copy query to clipboard")),
+		AppendQuery:  key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "Append query to the editor")),
+		Confirm:      key.NewBinding(key.WithKeys("y", "Y"), key.WithHelp("y", "Confirm")),
+	}
+}
+
+// actions maps configuration action names to their bindings
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":             &k.Quit,
+		"help":             &k.Help,
+		"history":          &k.History,
+		"workspace":        &k.Workspace,
+		"templates":        &k.Templates,
+		"timeRange":        &k.TimeRange,
+		"bookmarks":        &k.Bookmarks,
+		"catalog":          &k.Catalog,
+		"schemaExplorer":   &k.SchemaExplorer,
+		"bookmark":         &k.Bookmark,
+		"errorDetail":      &k.ErrorDetail,
+		"openPortal":       &k.OpenPortal,
+		"back":             &k.Back,
+		"execute":          &k.Execute,
+		"forceExecute":     &k.ForceExecute,
+		"switchPane":       &k.SwitchPane,
+		"aiSuggest":        &k.AISuggest,
+		"showReference":    &k.ShowReference,
+		"recentTables":     &k.RecentTables,
+		"clearEditor":      &k.ClearEditor,
+		"formatQuery":      &k.FormatQuery,
+		"toggleComment":    &k.ToggleComment,
+		"duplicateLine":    &k.DuplicateLine,
+		"moveLineUp":       &k.MoveLineUp,
+		"moveLineDown":     &k.MoveLineDown,
+		"deleteLine":       &k.DeleteLine,
+		"pasteQuery":       &k.PasteQuery,
+		"pasteAndRun":      &k.PasteAndRun,
+		"undo":             &k.Undo,
+		"redo":             &k.Redo,
+		"saveTemplate":     &k.SaveTemplate,
+		"historyPrev":      &k.HistoryPrev,
+		"historyNext":      &k.HistoryNext,
+		"suggestionPrev":   &k.SuggestionPrev,
+		"suggestionNext":   &k.SuggestionNext,
+		"acceptSuggestion": &k.AcceptSuggestion,
+		"prevRow":          &k.Up,
+		"nextRow":          &k.Down,
+		"scrollLeft":       &k.Left,
+		"scrollRight":      &k.Right,
+		"pageUp":           &k.PageUp,
+		"pageDown":         &k.PageDown,
+		"top":              &k.Top,
+		"bottom":           &k.Bottom,
+		"select":           &k.Select,
+		"close":            &k.Close,
+		"freezeColumn":     &k.FreezeColumn,
+		"widenColumns":     &k.WidenColumns,
+		"narrowColumns":    &k.NarrowColumns,
+		"widenColumn":      &k.WidenColumn,
+		"narrowColumn":     &k.NarrowColumn,
+		"autoFitColumns":   &k.AutoFitColumns,
+		"hideColumn":       &k.HideColumn,
+		"showColumns":      &k.ShowColumns,
+		"projectColumns":   &k.ProjectColumns,
+		"copyProject":      &k.CopyProject,
+		"projectAway":      &k.ProjectAway,
+		"copyColumn":       &k.CopyColumn,
+		"copyCell":         &k.CopyCell,
+		"copyMarkdown":     &k.CopyMarkdown,
+		"copyText":         &k.CopyText,
+		"saveVariable":     &k.SaveVariable,
+		"insertIn":         &k.InsertIn,
+		"copyIn":           &k.CopyIn,
+		"saveSnapshot":     &k.SaveSnapshot,
+		"saveReport":       &k.SaveReport,
+		"baseline":         &k.Baseline,
+		"toggleChart":      &k.ToggleChart,
+		"chartX":           &k.ChartX,
+		"chartY":           &k.ChartY,
+		"toggleEmpty":      &k.ToggleEmpty,
+		"toggleField":      &k.ToggleField,
+		"insertWhere":      &k.InsertWhere,
+		"copyWhere":        &k.CopyWhere,
+		"copyPanel":        &k.CopyPanel,
+		"fetchFullRow":     &k.FetchFullRow,
+		"delete":           &k.Delete,
+		"newTemplate":      &k.NewTemplate,
+		"newBookmark":      &k.NewBookmark,
+		"clearHistory":     &k.ClearHistory,
+		"copyHistory":      &k.CopyHistory,
+		"appendQuery":      &k.AppendQuery,
+		"confirm":          &k.Confirm,
+	}
+}
+
+// Apply overrides bindings with keys from the configuration, keyed by
+// action name (e.g. "execute": ["f5", "ctrl+r"]). Unknown action names are
+// reported in the returned error; valid overrides are still applied.
+func (k *KeyMap) Apply(overrides map[string][]string) error {
+	actions := k.actions()
+	var unknown []string
+
+	for name, keys := range overrides {
+		binding, ok := actions[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		binding.SetKeys(keys...)
+		binding.SetHelp(keyLabel(keys[0]), binding.Help().Desc)
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown key binding action(s): %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+)
+
+// kqlDoc is the bundled reference for a KQL operator or function
+type kqlDoc struct {
+	syntax  string // Defaults to the function's signature when empty
+	summary string
+	example string
+}
+
+// kqlReference documents the keywords offered by autocomplete, keyed by
+// name without parentheses
+var kqlReference = map[string]kqlDoc{
+	// Tabular operators
+	"where":        {"T | where Predicate", "Keeps the rows for which the predicate is true.", "SigninLogs | where ResultType != 0"},
+	"project":      {"T | project Col1, Name = Expr, ...", "Keeps, renames or computes columns, dropping all others.", "Heartbeat | project Computer, Age = now() - TimeGenerated"},
+	"project-away": {"T | project-away Col1, Col2*", "Removes the named columns, keeping all others.", "AzureActivity | project-away Authorization, Claims"},
+	"extend":       {"T | extend Name = Expr, ...", "Adds computed columns, keeping the existing ones.", "Perf | extend GB = CounterValue / 1024"},
+	"summarize":    {"T | summarize Agg, ... [by Col, ...]", "Groups rows by the by-columns and aggregates each group.", "AzureActivity | summarize count() by OperationName"},
+	"join":         {"T1 | join [kind=Kind] (T2) on Col", "Merges rows of two tables with matching keys. The default kind, innerunique, deduplicates the left side; use kind=inner for every match.", "SigninLogs | join kind=leftouter (AADNonInteractiveUserSignInLogs) on UserId"},
+	"union":        {"union T1, T2, ...", "Returns the rows of all the tables. withsource=Col records which table each row came from.", "union withsource=Table Syslog, Event | take 10"},
+	"take":         {"T | take N", "Returns up to N rows, in no particular order. Same as limit.", "StormEvents | take 5"},
+	"limit":        {"T | limit N", "Returns up to N rows, in no particular order. Same as take.", "StormEvents | limit 5"},
+	"top":          {"T | top N by Expr [asc | desc]", "Returns the first N rows sorted by the expression.", "Perf | top 10 by CounterValue desc"},
+	"sort":         {"T | sort by Expr [asc | desc], ...", "Sorts rows; descending by default. Same as order.", "Heartbeat | sort by TimeGenerated desc"},
+	"order":        {"T | order by Expr [asc | desc], ...", "Sorts rows; descending by default. Same as sort.", "Heartbeat | order by TimeGenerated asc"},
+	"distinct":     {"T | distinct Col1, Col2", "Returns the distinct combinations of the listed columns.", "SigninLogs | distinct AppDisplayName"},
+	"count":        {"T | count   or   count()", "As an operator, returns the number of rows. As a summarize aggregation, counts the rows in each group.", "Heartbeat | summarize count() by Computer"},
+	"render":       {"T | render Visualization", "Hints how to chart the result, e.g. timechart, barchart or piechart.", "Perf | summarize avg(CounterValue) by bin(TimeGenerated, 5m) | render timechart"},
+	"parse":        {`T | parse Expr with "text" Col1 "text" Col2:type ...`, "Extracts columns from a string by matching it against a pattern.", `Syslog | parse SyslogMessage with "user=" User " ip=" IP`},
+	"evaluate":     {"T | evaluate Plugin(Args)", "Invokes a plugin such as bag_unpack, pivot or autocluster.", "AzureActivity | evaluate bag_unpack(Properties)"},
+	"invoke":       {"T | invoke Function(Args)", "Calls a function that takes the piped table as its first argument.", "SecurityEvent | invoke MyFilter()"},
+	"mv-expand":    {"T | mv-expand Col [to typeof(Type)]", "Expands a dynamic array or bag into one row per element.", "AzureActivity | mv-expand Tag = Properties.tags"},
+	"mv-apply":     {"T | mv-apply Col on (SubQuery)", "Runs a subquery over the elements of a dynamic array of each row, e.g. to filter or aggregate within the array, returning the subquery's rows.", "T | mv-apply Item = Items on (top 1 by toint(Item.price))"},
+	"make-series":  {"T | make-series Agg default=0 on TimeCol from Start to End step Step [by Col]", "Creates time series arrays with every step filled, unlike summarize which skips empty bins.", "Perf | make-series avg(CounterValue) on TimeGenerated step 1h by Computer"},
+	"serialize":    {"T | serialize [Name = Expr, ...]", "Freezes the row order so window functions like prev() and row_number() can be used.", "Heartbeat | order by TimeGenerated | serialize Prev = prev(TimeGenerated)"},
+	"range":        {"range Col from Start to Stop step Step", "Generates a single-column table of values.", "range Day from ago(7d) to now() step 1d"},
+
+	// Aggregations
+	"sum":        {"", "Sums the expression over the group.", "Usage | summarize sum(Quantity) by DataType"},
+	"avg":        {"", "Averages the expression over the group.", "Perf | summarize avg(CounterValue) by Computer"},
+	"min":        {"", "Smallest value of the expression in the group.", "Heartbeat | summarize min(TimeGenerated) by Computer"},
+	"max":        {"", "Largest value of the expression in the group.", "Heartbeat | summarize max(TimeGenerated) by Computer"},
+	"dcount":     {"", "Estimated number of distinct values (HyperLogLog). Accuracy ranges from 0 (fastest) to 4 (most accurate).", "SigninLogs | summarize dcount(UserId) by AppDisplayName"},
+	"percentile": {"", "Estimated percentile of the expression in the group.", "requests | summarize percentile(duration, 95) by name"},
+	"stdev":      {"", "Standard deviation of the expression in the group.", "Perf | summarize stdev(CounterValue) by Computer"},
+	"variance":   {"", "Variance of the expression in the group.", "Perf | summarize variance(CounterValue) by Computer"},
+	"countif":    {"", "Counts the rows in the group for which the predicate is true.", "SigninLogs | summarize countif(ResultType != 0) by UserPrincipalName"},
+	"sumif":      {"", "Sums the expression over rows for which the predicate is true.", "Usage | summarize sumif(Quantity, IsBillable) by DataType"},
+	"avgif":      {"", "Averages the expression over rows for which the predicate is true.", "Perf | summarize avgif(CounterValue, CounterValue > 0) by Computer"},
+	"minif":      {"", "Smallest value of the expression among rows matching the predicate.", "Perf | summarize minif(CounterValue, CounterValue > 0) by Computer"},
+	"maxif":      {"", "Largest value of the expression among rows matching the predicate.", "Perf | summarize maxif(CounterValue, ObjectName == \"Memory\") by Computer"},
+	"make_list":  {"", "Collects the values in the group into a dynamic array, duplicates included.", "SigninLogs | summarize make_list(IPAddress) by UserId"},
+	"make_set":   {"", "Collects the distinct values in the group into a dynamic array.", "SigninLogs | summarize make_set(AppDisplayName) by UserId"},
+	"arg_max":    {"", "Returns the row with the largest value of the first expression; * returns all its columns.", "Heartbeat | summarize arg_max(TimeGenerated, *) by Computer"},
+	"arg_min":    {"", "Returns the row with the smallest value of the first expression; * returns all its columns.", "Heartbeat | summarize arg_min(TimeGenerated, *) by Computer"},
+
+	// String and set comparisons
+	"contains":      {"Expr contains \"text\"", "True if the text appears anywhere, case-insensitive. Slower than has; use contains_cs for case-sensitive.", "Syslog | where SyslogMessage contains \"fail\""},
+	"has":           {"Expr has \"term\"", "True if a whole term appears, case-insensitive. Uses the term index, so it is much faster than contains.", "Syslog | where SyslogMessage has \"error\""},
+	"startswith":    {"Expr startswith \"text\"", "True if the value starts with the text, case-insensitive.", "SigninLogs | where UserPrincipalName startswith \"admin\""},
+	"endswith":      {"Expr endswith \"text\"", "True if the value ends with the text, case-insensitive.", "SigninLogs | where UserPrincipalName endswith \"@contoso.com\""},
+	"matches regex": {"Expr matches regex \"pattern\"", "True if the value matches the RE2 regular expression.", `Syslog | where SyslogMessage matches regex @"\d+\.\d+\.\d+\.\d+"`},
+	"in":            {"Expr in (Value1, Value2, ...)", "True if the value equals one of the listed values, case-sensitive. Use in~ to ignore case.", "Event | where EventLevelName in (\"Error\", \"Warning\")"},
+	"!in":           {"Expr !in (Value1, Value2, ...)", "True if the value equals none of the listed values.", "Event | where EventLevelName !in (\"Information\")"},
+	"between":       {"Expr between (Low .. High)", "True if the value is in the inclusive range.", "Perf | where TimeGenerated between (ago(2h) .. ago(1h))"},
+	"and":           {"Pred1 and Pred2", "True if both predicates are true.", "Event | where EventLevel == 1 and Computer has \"web\""},
+	"or":            {"Pred1 or Pred2", "True if either predicate is true.", "Event | where EventLevel == 1 or EventLevel == 2"},
+	"not":           {"not(Pred)", "Negates a predicate.", "Syslog | where not(SyslogMessage has \"debug\")"},
+
+	// Date and time
+	"ago":             {"", "The current UTC time minus the timespan.", "Heartbeat | where TimeGenerated > ago(1h)"},
+	"now":             {"", "The current UTC time, plus an optional offset.", "print now(-1d)"},
+	"datetime":        {"", "A datetime literal, in UTC unless the value has an offset.", "T | where TimeGenerated > datetime(2024-01-01)"},
+	"timespan":        {"", "A timespan literal; also written as 1d, 2h, 30m, 10s or 100ms.", "print timespan(1.12:00:00)"},
+	"startofday":      {"", "Start of the day containing the date, shifted by Offset days.", "T | summarize count() by startofday(TimeGenerated)"},
+	"startofweek":     {"", "Start of the week (Sunday) containing the date.", "T | summarize count() by startofweek(TimeGenerated)"},
+	"startofmonth":    {"", "Start of the month containing the date.", "T | summarize count() by startofmonth(TimeGenerated)"},
+	"endofday":        {"", "Last moment of the day containing the date.", "print endofday(now())"},
+	"endofweek":       {"", "Last moment of the week containing the date.", "print endofweek(now())"},
+	"endofmonth":      {"", "Last moment of the month containing the date.", "print endofmonth(now())"},
+	"bin":             {"", "Rounds down to a multiple of RoundTo, typically to bucket times for summarize.", "Heartbeat | summarize count() by bin(TimeGenerated, 1h)"},
+	"format_datetime": {"", "Formats a datetime with a pattern such as yyyy-MM-dd HH:mm:ss.", "print format_datetime(now(), \"yyyy-MM-dd\")"},
+}
+
+// isKeywordChar reports whether r can be part of a KQL keyword
+func isKeywordChar(r byte) bool {
+	return unicode.IsLetter(rune(r)) || unicode.IsDigit(rune(r)) || r == '_' || r == '-'
+}
+
+// referenceAt looks up the keyword at or just before pos in the text,
+// returning its name and documentation
+func referenceAt(text string, pos int) (string, kqlDoc, bool) {
+	pos = min(max(pos, 0), len(text))
+	start, end := pos, pos
+	for start > 0 && isKeywordChar(text[start-1]) {
+		start--
+	}
+	for end < len(text) && isKeywordChar(text[end]) {
+		end++
+	}
+	word := strings.ToLower(text[start:end])
+	if word == "" {
+		return "", kqlDoc{}, false
+	}
+
+	candidates := []string{word}
+	if start > 0 && text[start-1] == '!' {
+		candidates = []string{"!" + word, word}
+	}
+	if word == "matches" || word == "regex" {
+		candidates = []string{"matches regex"}
+	}
+	for _, name := range candidates {
+		if doc, ok := kqlReference[name]; ok {
+			if doc.syntax == "" {
+				doc.syntax = kqlSignatures[name]
+			}
+			return name, doc, true
+		}
+	}
+	return "", kqlDoc{}, false
+}
+
+// showReference shows the reference for the keyword under the editor cursor
+func (m *Model) showReference() {
+	name, doc, ok := referenceAt(m.editor.Value(), m.editor.CursorPosition())
+	if !ok {
+		m.notice = "No reference for the word under the cursor"
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(m.styles.Bold.Render(name) + "  " + m.styles.Muted.Render(doc.syntax) + "\n")
+	b.WriteString(doc.summary + "\n")
+	b.WriteString(m.styles.Muted.Render("Example: ") + doc.example)
+	m.reference = b.String()
+}
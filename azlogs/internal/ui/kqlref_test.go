@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKQLReference_CoversKeywords(t *testing.T) {
+	var keywords []string
+	keywords = append(keywords, kqlOperators...)
+	keywords = append(keywords, kqlFunctions...)
+	keywords = append(keywords, kqlComparisons...)
+	keywords = append(keywords, kqlTimeFunctions...)
+
+	for _, kw := range keywords {
+		name := strings.TrimSuffix(strings.TrimSuffix(kw, "()"), "(")
+		if strings.Trim(name, "=!<>") == "" {
+			continue // Symbols need no reference
+		}
+		if _, ok := kqlReference[name]; !ok {
+			t.Errorf("Expected a reference for %q", name)
+		}
+	}
+}
+
+func TestReferenceAt(t *testing.T) {
+	text := "T | mv-expand Tags | where Name !in (\"a\") and Name matches regex \"x\" | summarize dcount(Id)"
+	tests := []struct {
+		at       string // Cursor placed at the start of this text
+		offset   int
+		expected string
+	}{
+		{"mv-expand", 3, "mv-expand"},
+		{"where", 5, "where"},
+		{"in (", 1, "!in"},
+		{"regex", 0, "matches regex"},
+		{"dcount", 6, "dcount"},
+		{"Tags", 0, ""},
+		{"| where", 0, ""},
+	}
+
+	for _, tt := range tests {
+		pos := strings.Index(text, tt.at) + tt.offset
+		name, doc, ok := referenceAt(text, pos)
+		if name != tt.expected || ok != (tt.expected != "") {
+			t.Errorf("At %q+%d: expected %q, got %q", tt.at, tt.offset, tt.expected, name)
+		}
+		if ok && doc.syntax == "" {
+			t.Errorf("Expected %q to have a syntax", name)
+		}
+	}
+}
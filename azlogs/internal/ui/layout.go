@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Smallest terminal the UI is laid out for. Smaller terminals show a notice
+// instead of a garbled layout.
+const (
+	minTerminalWidth  = 40
+	minTerminalHeight = 12
+)
+
+// resize lays the components out for a new terminal size
+func (m *Model) resize(width, height int) {
+	m.width = width
+	m.height = height
+	m.editor.SetSize(width-4, 8)
+	m.layoutPopup()
+	m.layoutTable()
+	m.helpView.SetSize(width-8, height-14)
+	m.errorView.SetSize(width-8, height-16)
+}
+
+// Popup width bounds. The maximum is configurable, as popup_max_width
+const (
+	minPopupWidth     = 20
+	defaultPopupWidth = 60
+)
+
+// popupWidth returns the suggestion popup width for a terminal width: the
+// configured maximum, narrowed to fit the terminal
+func popupWidth(termWidth, maxWidth int) int {
+	if maxWidth <= 0 {
+		maxWidth = defaultPopupWidth
+	}
+	return max(minPopupWidth, min(maxWidth, termWidth-4))
+}
+
+// layoutPopup sizes the suggestion popup to the room between the editor
+// and the footer. The editor sits right under the header, so the popup
+// always opens below it and lists fewer suggestions when space is short
+func (m *Model) layoutPopup() {
+	m.suggestionPopup.SetWidth(popupWidth(m.width, m.config.PopupMaxWidth))
+	above := 3  // Header, status bar and blank line
+	footer := 3 // Error line, blank line and key hints
+	m.suggestionPopup.SetMaxHeight(max(3, m.height-above-lipgloss.Height(m.editor.View())-footer))
+}
+
+// layoutTable sizes the results table to the space left by the chart panel
+func (m *Model) layoutTable() {
+	height := m.height - 20
+	if m.chartShown() {
+		height -= chartHeight
+	}
+	m.table.SetSize(m.width-4, height)
+}
+
+// tooSmall reports whether the terminal is too small to lay the UI out. The
+// size is unknown, and assumed to fit, until the first WindowSizeMsg.
+func (m Model) tooSmall() bool {
+	return m.width > 0 && (m.width < minTerminalWidth || m.height < minTerminalHeight)
+}
+
+// renderTooSmall renders the notice shown in place of the UI when the
+// terminal is too small
+func (m Model) renderTooSmall() string {
+	return m.styles.Warning.Render(fmt.Sprintf("Terminal too small (%dx%d).\nResize to at least %dx%d.",
+		m.width, m.height, minTerminalWidth, minTerminalHeight))
+}
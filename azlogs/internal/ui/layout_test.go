@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestModel_SmallTerminals(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	result := &azure.QueryResult{
+		RowCount: 30,
+		Tables: []azure.Table{{
+			Columns: []azure.Column{{Name: "TimeGenerated", Type: "datetime"}, {Name: "Count", Type: "long"}},
+		}},
+	}
+	for i := 0; i < 30; i++ {
+		result.Tables[0].Rows = append(result.Tables[0].Rows, []interface{}{
+			fmt.Sprintf("2024-01-01T%02d:00:00Z", i%24), float64(i),
+		})
+	}
+
+	views := []View{ViewQuery, ViewResults, ViewHistory, ViewHelp, ViewWorkspace,
+		ViewRowDetail, ViewTemplates, ViewTimeRange, ViewBookmarks, ViewSchema, ViewError, ViewCatalog}
+	sizes := [][2]int{{20, 10}, {39, 30}, {40, 12}, {60, 15}, {80, 24}}
+
+	for _, size := range sizes {
+		t.Run(fmt.Sprintf("%dx%d", size[0], size[1]), func(t *testing.T) {
+			model, _ := NewModel("", azure.AuthDefault, azure.NewConfig()).Update(tea.WindowSizeMsg{Width: size[0], Height: size[1]})
+			m := model.(Model)
+			m.processResults(result)
+
+			for _, key := range []tea.KeyType{tea.KeyDown, tea.KeyPgDown, tea.KeyEnd, tea.KeyPgUp} {
+				m.table, _ = m.table.Update(tea.KeyMsg{Type: key})
+			}
+
+			small := size[0] < minTerminalWidth || size[1] < minTerminalHeight
+			for _, view := range views {
+				m.currentView = view
+				out := m.View()
+				if got := strings.Contains(out, "Terminal too small"); got != small {
+					t.Errorf("View %d: expected too small notice %v, got %v", view, small, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package ui
+
+// Line editing helpers for the query editor. Each takes the editor's lines
+// and the cursor row and returns the new lines and the row the cursor
+// should end up on.
+
+// duplicateLine copies the current line below itself
+func duplicateLine(lines []string, row int) ([]string, int) {
+	if row < 0 || row >= len(lines) {
+		return lines, row
+	}
+	out := make([]string, 0, len(lines)+1)
+	out = append(out, lines[:row+1]...)
+	out = append(out, lines[row])
+	out = append(out, lines[row+1:]...)
+	return out, row + 1
+}
+
+// moveLine swaps the current line with the one delta lines away (-1 for up,
+// 1 for down), leaving the lines unchanged at the edges
+func moveLine(lines []string, row, delta int) ([]string, int) {
+	target := row + delta
+	if row < 0 || row >= len(lines) || target < 0 || target >= len(lines) {
+		return lines, row
+	}
+	out := append([]string(nil), lines...)
+	out[row], out[target] = out[target], out[row]
+	return out, target
+}
+
+// deleteLine removes the current line, keeping the cursor on the line that
+// takes its place
+func deleteLine(lines []string, row int) ([]string, int) {
+	if row < 0 || row >= len(lines) {
+		return lines, row
+	}
+	if len(lines) == 1 {
+		return []string{""}, 0
+	}
+	out := make([]string, 0, len(lines)-1)
+	out = append(out, lines[:row]...)
+	out = append(out, lines[row+1:]...)
+	if row >= len(out) {
+		row = len(out) - 1
+	}
+	return out, row
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDuplicateLine(t *testing.T) {
+	lines, row := duplicateLine([]string{"T", "| take 10"}, 0)
+	if want := []string{"T", "T", "| take 10"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected %q, got %q", want, lines)
+	}
+	if row != 1 {
+		t.Errorf("Expected cursor on row 1, got %d", row)
+	}
+
+	lines, row = duplicateLine([]string{"T", "| take 10"}, 1)
+	if want := []string{"T", "| take 10", "| take 10"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected %q, got %q", want, lines)
+	}
+	if row != 2 {
+		t.Errorf("Expected cursor on row 2, got %d", row)
+	}
+}
+
+func TestMoveLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		row      int
+		delta    int
+		expected []string
+		wantRow  int
+	}{
+		{"move down", 0, 1, []string{"b", "a", "c"}, 1},
+		{"move up", 2, -1, []string{"a", "c", "b"}, 1},
+		{"top line can't move up", 0, -1, []string{"a", "b", "c"}, 0},
+		{"bottom line can't move down", 2, 1, []string{"a", "b", "c"}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := []string{"a", "b", "c"}
+			lines, row := moveLine(original, tt.row, tt.delta)
+			if !reflect.DeepEqual(lines, tt.expected) || row != tt.wantRow {
+				t.Errorf("Expected %q (row %d), got %q (row %d)", tt.expected, tt.wantRow, lines, row)
+			}
+			if !reflect.DeepEqual(original, []string{"a", "b", "c"}) {
+				t.Errorf("Expected input to be left unchanged, got %q", original)
+			}
+		})
+	}
+}
+
+func TestDeleteLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		row      int
+		expected []string
+		wantRow  int
+	}{
+		{"middle line", []string{"a", "b", "c"}, 1, []string{"a", "c"}, 1},
+		{"last line", []string{"a", "b", "c"}, 2, []string{"a", "b"}, 1},
+		{"only line", []string{"a"}, 0, []string{""}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, row := deleteLine(tt.lines, tt.row)
+			if !reflect.DeepEqual(lines, tt.expected) || row != tt.wantRow {
+				t.Errorf("Expected %q (row %d), got %q (row %d)", tt.expected, tt.wantRow, lines, row)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// markdownEscaper keeps a value on one line of a Markdown table cell
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>", "\t", " ")
+
+// markdownCell formats a value for a Markdown table cell, truncated to
+// maxWidth display columns when maxWidth is positive. It reports whether the
+// value was truncated
+func markdownCell(v interface{}, colType string, maxWidth int) (string, bool) {
+	s := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return -1
+		}
+		return r
+	}, StripANSI(formatCell(v, colType)))
+	truncated := false
+	if maxWidth > 0 && cellWidth(s) > maxWidth {
+		s = truncateString(cellReplacer.Replace(s), maxWidth)
+		truncated = true
+	}
+	return markdownEscaper.Replace(s), truncated
+}
+
+// MarkdownTable renders a result as a GitHub-flavored Markdown table with
+// padded columns, numbers right aligned. Markdown tables don't scroll, so
+// cells wider than maxCellWidth (0 for no limit) are truncated, with a note
+// below the table
+func MarkdownTable(columns []azure.Column, rows [][]interface{}, maxCellWidth int) string {
+	if len(columns) == 0 {
+		return ""
+	}
+
+	cells := make([][]string, len(rows)+1)
+	cells[0] = make([]string, len(columns))
+	for i, col := range columns {
+		cells[0][i] = markdownEscaper.Replace(col.Name)
+	}
+	truncated := 0
+	for r, row := range rows {
+		cells[r+1] = make([]string, len(columns))
+		for i, col := range columns {
+			var v interface{}
+			if i < len(row) {
+				v = row[i]
+			}
+			cell, cut := markdownCell(v, col.Type, maxCellWidth)
+			cells[r+1][i] = cell
+			if cut {
+				truncated++
+			}
+		}
+	}
+
+	// Separator dashes need at least 3 characters
+	widths := make([]int, len(columns))
+	for i := range widths {
+		widths[i] = 3
+	}
+	for _, row := range cells {
+		for i, cell := range row {
+			widths[i] = max(widths[i], cellWidth(cell))
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		b.WriteString("|")
+		for i, cell := range row {
+			b.WriteString(" ")
+			if isNumericType(columns[i].Type) {
+				b.WriteString(strings.Repeat(" ", widths[i]-cellWidth(cell)) + cell)
+			} else {
+				b.WriteString(padRight(cell, widths[i]))
+			}
+			b.WriteString(" |")
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(cells[0])
+	b.WriteString("|")
+	for i, col := range columns {
+		if isNumericType(col.Type) {
+			b.WriteString(" " + strings.Repeat("-", widths[i]-1) + ": |")
+		} else {
+			b.WriteString(" " + strings.Repeat("-", widths[i]) + " |")
+		}
+	}
+	b.WriteString("\n")
+	for _, row := range cells[1:] {
+		writeRow(row)
+	}
+
+	if truncated > 0 {
+		noun := "cells"
+		if truncated == 1 {
+			noun = "cell"
+		}
+		fmt.Fprintf(&b, "\n_%d %s truncated to %d characters._\n", truncated, noun, maxCellWidth)
+	}
+	return b.String()
+}
+
+// copyMarkdown copies the displayed result, without hidden columns, as a
+// Markdown table
+func (m Model) copyMarkdown() tea.Cmd {
+	columns, rows := m.shownResult()
+	return copyToClipboard(MarkdownTable(columns, rows, m.table.MaxColumnWidth()), "Markdown table")
+}
+
+// shownResult returns the columns and rows of the displayed result without
+// hidden columns
+func (m Model) shownResult() ([]azure.Column, [][]interface{}) {
+	var columns []azure.Column
+	var indexes []int
+	for i, col := range m.resultColumns {
+		if !m.table.isHidden(i) {
+			columns = append(columns, col)
+			indexes = append(indexes, i)
+		}
+	}
+	rows := make([][]interface{}, 0, len(m.currentRows()))
+	for _, row := range m.currentRows() {
+		shown := make([]interface{}, len(indexes))
+		for j, i := range indexes {
+			if i < len(row) {
+				shown[j] = row[i]
+			}
+		}
+		rows = append(rows, shown)
+	}
+	return columns, rows
+}
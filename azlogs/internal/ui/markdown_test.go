@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestMarkdownTable(t *testing.T) {
+	columns := []azure.Column{{Name: "Name", Type: "string"}, {Name: "Count", Type: "long"}}
+	rows := [][]interface{}{
+		{"a|b", 3.0},
+		{"line 1\nline 2", nil},
+		{"\x1b[31mred", 12.0},
+	}
+
+	expected := "" +
+		"| Name             | Count |\n" +
+		"| ---------------- | ----: |\n" +
+		"| a\\|b             |     3 |\n" +
+		"| line 1<br>line 2 |       |\n" +
+		"| red              |    12 |\n"
+	if got := MarkdownTable(columns, rows, 0); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	expected = "" +
+		"| Name   | Count |\n" +
+		"| ------ | ----: |\n" +
+		"| a\\|b   |     3 |\n" +
+		"| lin... |       |\n" +
+		"| red    |    12 |\n" +
+		"\n_1 cell truncated to 6 characters._\n"
+	if got := MarkdownTable(columns, rows, 6); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	if got := MarkdownTable(nil, nil, 0); got != "" {
+		t.Errorf("Expected no table without columns, got %q", got)
+	}
+}
@@ -0,0 +1,376 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// identifierPattern matches column names usable in KQL without quoting
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// kqlStringEscaper escapes text for a double-quoted KQL string literal
+var kqlStringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// kqlIdentifier returns a column name as a KQL identifier, bracket-quoting
+// names that aren't plain identifiers
+func kqlIdentifier(name string) string {
+	if identifierPattern.MatchString(name) {
+		return name
+	}
+	return "['" + strings.ReplaceAll(name, "'", `\'`) + "']"
+}
+
+// kqlLiteral returns a raw result value as a KQL literal of the column's
+// type, reporting false for values that can't be compared with ==
+func kqlLiteral(v interface{}, colType string) (string, bool) {
+	switch val := v.(type) {
+	case bool:
+		return strconv.FormatBool(val), true
+	case float64:
+		if colType == "int" || colType == "long" {
+			return strconv.FormatInt(int64(val), 10), true
+		}
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case int64:
+		return strconv.FormatInt(val, 10), true
+	case string:
+		switch colType {
+		case "dynamic":
+			return "", false
+		case "datetime":
+			return "datetime(" + val + ")", true
+		case "timespan":
+			return "timespan(" + val + ")", true
+		}
+		return `"` + kqlStringEscaper.Replace(val) + `"`, true
+	}
+	return "", false
+}
+
+// whereClause builds a where clause matching the row on the given columns.
+// Null values match with isnull(), or isempty() for strings, which are never
+// null in KQL; columns whose values can't be compared (e.g. dynamic) are
+// skipped.
+func whereClause(columns []azure.Column, row []interface{}, selected []int) string {
+	var conditions []string
+	for _, i := range selected {
+		if i >= len(columns) || i >= len(row) {
+			continue
+		}
+		name := kqlIdentifier(columns[i].Name)
+		if row[i] == nil && columns[i].Type == "string" {
+			conditions = append(conditions, fmt.Sprintf("isempty(%s)", name))
+			continue
+		}
+		if row[i] == nil {
+			conditions = append(conditions, fmt.Sprintf("isnull(%s)", name))
+			continue
+		}
+		if lit, ok := kqlLiteral(row[i], columns[i].Type); ok {
+			conditions = append(conditions, fmt.Sprintf("%s == %s", name, lit))
+		}
+	}
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "where " + strings.Join(conditions, " and ")
+}
+
+// detailField is a field shown in the row detail view
+type detailField struct {
+	index int // Column index
+	name  string
+	value string
+}
+
+// detailFields returns the selected row's fields as listed in the row detail
+// view, leaving out empty ones when they are hidden
+func (m Model) detailFields() []detailField {
+	columns, _, row := m.detailRow()
+	var fields []detailField
+	for i, col := range columns {
+		if i >= len(row) {
+			break
+		}
+		if m.hideEmptyFields && row[i] == "" {
+			continue
+		}
+		fields = append(fields, detailField{index: i, name: col, value: row[i]})
+	}
+	return fields
+}
+
+// detailText renders row detail fields as plain text, one "name: value"
+// line per field. Dynamic values are pretty-printed on the lines below their
+// name.
+func detailText(fields []detailField, columnTypes []string) string {
+	var b strings.Builder
+	for _, f := range fields {
+		value := f.value
+		if f.index < len(columnTypes) && columnTypes[f.index] == "dynamic" {
+			value = PrettyDynamic(value)
+		}
+		if strings.Contains(value, "\n") {
+			b.WriteString(f.name + ":\n  " + strings.ReplaceAll(value, "\n", "\n  ") + "\n")
+		} else {
+			b.WriteString(f.name + ": " + value + "\n")
+		}
+	}
+	return b.String()
+}
+
+// toggleDetailField selects or deselects the field under the cursor in the
+// row detail view for the where clause
+func (m *Model) toggleDetailField() {
+	fields := m.detailFields()
+	if m.detailScrollPos < 0 || m.detailScrollPos >= len(fields) {
+		return
+	}
+	if m.detailSelected == nil {
+		m.detailSelected = make(map[int]bool)
+	}
+	i := fields[m.detailScrollPos].index
+	if m.detailSelected[i] {
+		delete(m.detailSelected, i)
+	} else {
+		m.detailSelected[i] = true
+	}
+}
+
+// selectedRowWhere builds the where clause for the selected fields of the
+// selected row, or the field under the cursor if none are selected
+func (m Model) selectedRowWhere() string {
+	columns, row := m.resultColumns, []interface{}(nil)
+	if m.fullRecord != nil {
+		columns, row = m.fullRecord.columns, m.fullRecord.row
+	} else if idx := m.table.GetSelectedRowIndex(); idx >= 0 && idx < len(m.resultRows) {
+		row = m.resultRows[idx]
+	} else {
+		return ""
+	}
+
+	var selected []int
+	for i := range m.detailSelected {
+		selected = append(selected, i)
+	}
+	sort.Ints(selected)
+	if len(selected) == 0 {
+		if fields := m.detailFields(); m.detailScrollPos < len(fields) {
+			selected = []int{fields[m.detailScrollPos].index}
+		}
+	}
+	return whereClause(columns, row, selected)
+}
+
+// projectClause builds a project clause keeping the named columns
+func projectClause(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	idents := make([]string, len(names))
+	for i, name := range names {
+		idents[i] = kqlIdentifier(name)
+	}
+	return "| project " + strings.Join(idents, ", ")
+}
+
+// shownProjectClause builds a project clause keeping the result columns not
+// hidden in the table
+func (m Model) shownProjectClause() string {
+	return projectClause(m.table.ShownColumns())
+}
+
+// projectAwayClause builds a project-away clause dropping the named columns
+func projectAwayClause(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	idents := make([]string, len(names))
+	for i, name := range names {
+		idents[i] = kqlIdentifier(name)
+	}
+	return "| project-away " + strings.Join(idents, ", ")
+}
+
+// trailingLimitPattern matches a last pipe stage that only limits the rows.
+// top is left out since it sorts by a column the new clause may drop.
+var trailingLimitPattern = regexp.MustCompile(`(?i)^\|\s*(take|limit|sample)\b`)
+
+// lastPipe returns the index of the query's last "|" outside string
+// literals and comments, or -1 if it has none
+func lastPipe(query string) int {
+	last, offset := -1, 0
+	for _, line := range strings.SplitAfter(query, "\n") {
+		start := offset
+		offset += len(line)
+		line = StripKQLComments(line)
+		var quote byte
+		for i := 0; i < len(line); i++ {
+			switch c := line[i]; {
+			case quote != 0:
+				if c == '\\' {
+					i++ // Skip escaped char
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '\'':
+				quote = c
+			case c == '|':
+				last = start + i
+			}
+		}
+	}
+	return last
+}
+
+// insertBeforeLimit adds a clause on its own line before the query's
+// trailing take, limit or sample stage, or at the end if it has none
+func insertBeforeLimit(query, clause string) string {
+	query = strings.TrimRight(query, " \n")
+	if i := lastPipe(query); i >= 0 && trailingLimitPattern.MatchString(query[i:]) {
+		before := strings.TrimRight(query[:i], " \n")
+		return before + "\n" + clause + "\n" + query[i:]
+	}
+	if query == "" {
+		return clause
+	}
+	return query + "\n" + clause
+}
+
+// applyHiddenColumns adds a project-away of the hidden columns to the query,
+// so later runs don't fetch them
+func (m *Model) applyHiddenColumns() {
+	clause := projectAwayClause(m.table.HiddenColumns())
+	if clause == "" {
+		m.notice = "No hidden columns (hide columns with " + m.keys.HideColumn.Help().Key + ")"
+		return
+	}
+	m.editor.SetValue(insertBeforeLimit(m.editor.Value(), clause))
+	m.currentView = ViewQuery
+	m.table.Blur()
+	m.editor.Focus()
+}
+
+// columnValues returns the non-empty values of a column as displayed, one
+// per row
+func columnValues(rows [][]interface{}, col int, colType string) []string {
+	var values []string
+	for _, row := range rows {
+		if col >= len(row) || row[col] == nil {
+			continue
+		}
+		if value := formatCell(row[col], colType); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// copyCurrentColumn copies the values of the current column in the displayed
+// rows, one per line, e.g. to paste into an in() list
+func (m *Model) copyCurrentColumn() tea.Cmd {
+	col := m.table.CurrentColumn()
+	if col < 0 || col >= len(m.resultColumns) {
+		m.lastError = "No column to copy"
+		return nil
+	}
+	values := columnValues(m.currentRows(), col, m.resultColumns[col].Type)
+	if len(values) == 0 {
+		m.lastError = fmt.Sprintf("Column %s has no values to copy", m.resultColumns[col].Name)
+		return nil
+	}
+	return copyToClipboard(strings.Join(values, "\n"), fmt.Sprintf("%d values of %s", len(values), m.resultColumns[col].Name))
+}
+
+// currentCell returns the column and full value of the highlighted cell, the
+// selected row's value in the current column
+func (m Model) currentCell() (string, string, bool) {
+	idx, col := m.table.GetSelectedRowIndex(), m.table.CurrentColumn()
+	if idx < 0 || idx >= len(m.resultRows) || col < 0 || col >= len(m.resultColumns) {
+		return "", "", false
+	}
+	var value string
+	if row := m.resultRows[idx]; col < len(row) {
+		value = formatCell(row[col], m.resultColumns[col].Type)
+	}
+	return m.resultColumns[col].Name, value, true
+}
+
+// copyCurrentCell copies the highlighted cell's value, untruncated
+func (m *Model) copyCurrentCell() tea.Cmd {
+	column, value, ok := m.currentCell()
+	if !ok {
+		m.lastError = "No cell to copy"
+		return nil
+	}
+	if value == "" {
+		m.lastError = fmt.Sprintf("%s is empty in this row", column)
+		return nil
+	}
+	return copyToClipboard(value, column)
+}
+
+// maxInValues caps the number of values the in() clause builder lists
+const maxInValues = 500
+
+// distinctLiterals returns the distinct values of a column as KQL literals,
+// in the order first seen. Nulls and values that can't be compared are
+// skipped.
+func distinctLiterals(rows [][]interface{}, col int, colType string) []string {
+	seen := make(map[string]bool)
+	var literals []string
+	for _, row := range rows {
+		if col >= len(row) || row[col] == nil {
+			continue
+		}
+		lit, ok := kqlLiteral(row[col], colType)
+		if !ok || seen[lit] {
+			continue
+		}
+		seen[lit] = true
+		literals = append(literals, lit)
+	}
+	return literals
+}
+
+// inClause builds a where clause matching any of the distinct values of a
+// column, listing at most limit of them. It also returns the number of
+// distinct values found, which exceeds limit when the list was cut.
+func inClause(column azure.Column, rows [][]interface{}, col, limit int) (string, int) {
+	literals := distinctLiterals(rows, col, column.Type)
+	distinct := len(literals)
+	if distinct == 0 {
+		return "", 0
+	}
+	if distinct > limit {
+		literals = literals[:limit]
+	}
+	return fmt.Sprintf("where %s in (%s)", kqlIdentifier(column.Name), strings.Join(literals, ", ")), distinct
+}
+
+// currentColumnIn builds the in() clause for the current column of the
+// displayed rows. The second value describes the cut when the list was
+// capped, and is empty otherwise.
+func (m *Model) currentColumnIn() (string, string) {
+	col := m.table.CurrentColumn()
+	if col < 0 || col >= len(m.resultColumns) {
+		m.lastError = "No column to build an in() clause from"
+		return "", ""
+	}
+	column := m.resultColumns[col]
+	clause, distinct := inClause(column, m.currentRows(), col, maxInValues)
+	if clause == "" {
+		m.lastError = fmt.Sprintf("Column %s has no comparable values", column.Name)
+		return "", ""
+	}
+	if distinct > maxInValues {
+		return clause, fmt.Sprintf("first %d of %d distinct values", maxInValues, distinct)
+	}
+	return clause, ""
+}
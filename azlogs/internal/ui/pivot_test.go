@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestWhereClause(t *testing.T) {
+	columns := []azure.Column{
+		{Name: "OperationName", Type: "string"},
+		{Name: "Count", Type: "long"},
+		{Name: "TimeGenerated", Type: "datetime"},
+		{Name: "Props", Type: "dynamic"},
+		{Name: "Is Error", Type: "bool"},
+		{Name: "Caller", Type: "string"},
+		{Name: "DurationMs", Type: "long"},
+	}
+	row := []interface{}{`Write "blob"\n`, 42.0, "2024-01-01T00:00:00Z", map[string]interface{}{"a": 1.0}, true, nil, nil}
+
+	tests := []struct {
+		name     string
+		selected []int
+		expected string
+	}{
+		{"string escaped", []int{0}, `where OperationName == "Write \"blob\"\\n"`},
+		{"typed values", []int{1, 2, 4}, `where Count == 42 and TimeGenerated == datetime(2024-01-01T00:00:00Z) and ['Is Error'] == true`},
+		{"null string uses isempty", []int{5}, `where isempty(Caller)`},
+		{"null number uses isnull", []int{6}, `where isnull(DurationMs)`},
+		{"dynamic skipped", []int{3, 1}, `where Count == 42`},
+		{"nothing comparable", []int{3}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := whereClause(columns, row, tt.selected); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestProjectClause(t *testing.T) {
+	if got := projectClause([]string{"TimeGenerated", "Is Error"}); got != "| project TimeGenerated, ['Is Error']" {
+		t.Errorf("Expected quoted project clause, got %q", got)
+	}
+	if got := projectClause(nil); got != "" {
+		t.Errorf("Expected no clause without columns, got %q", got)
+	}
+}
+
+func TestProjectAwayClause(t *testing.T) {
+	if got := projectAwayClause([]string{"Properties", "Is Error"}); got != "| project-away Properties, ['Is Error']" {
+		t.Errorf("Expected quoted project-away clause, got %q", got)
+	}
+	if got := projectAwayClause(nil); got != "" {
+		t.Errorf("Expected no clause without columns, got %q", got)
+	}
+}
+
+func TestInsertBeforeLimit(t *testing.T) {
+	clause := "| project-away Properties"
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"AzureActivity", "AzureActivity\n| project-away Properties"},
+		{"AzureActivity\n| take 100\n", "AzureActivity\n| project-away Properties\n| take 100"},
+		{"AzureActivity | where Level == 'Error' | sample 10", "AzureActivity | where Level == 'Error'\n| project-away Properties\n| sample 10"},
+		{"AzureActivity | top 10 by TimeGenerated", "AzureActivity | top 10 by TimeGenerated\n| project-away Properties"},
+		{"AzureActivity\n| take 10\n// | where Level == 'Error'", "AzureActivity\n| project-away Properties\n| take 10\n// | where Level == 'Error'"},
+		{"AzureActivity\n| where Message has \"a | take 5\"", "AzureActivity\n| where Message has \"a | take 5\"\n| project-away Properties"},
+		{"AzureActivity\n| take 10\n| where Level == 'Error'", "AzureActivity\n| take 10\n| where Level == 'Error'\n| project-away Properties"},
+		{"", "| project-away Properties"},
+	}
+
+	for _, tt := range tests {
+		if got := insertBeforeLimit(tt.query, clause); got != tt.expected {
+			t.Errorf("insertBeforeLimit(%q): expected %q, got %q", tt.query, tt.expected, got)
+		}
+	}
+}
+
+func TestDetailText(t *testing.T) {
+	fields := []detailField{
+		{index: 0, name: "Computer", value: "web-01"},
+		{index: 2, name: "Props", value: `{"a":1}`},
+	}
+	expected := "Computer: web-01\nProps:\n  {\n    \"a\": 1\n  }\n"
+	if got := detailText(fields, []string{"string", "long", "dynamic"}); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestColumnValues(t *testing.T) {
+	rows := [][]interface{}{{"10.0.0.1", 1.0}, {nil, 2.0}, {"", 3.0}, {"10.0.0.2"}}
+
+	got := columnValues(rows, 0, "string")
+	if len(got) != 2 || got[0] != "10.0.0.1" || got[1] != "10.0.0.2" {
+		t.Errorf("Expected the non-empty values, got %q", got)
+	}
+	if got := columnValues(rows, 1, "long"); len(got) != 3 {
+		t.Errorf("Expected rows missing the column skipped, got %q", got)
+	}
+}
+
+func TestInClause(t *testing.T) {
+	rows := [][]interface{}{{"10.0.0.1"}, {`say "hi"`}, {nil}, {"10.0.0.1"}, {"10.0.0.3"}}
+
+	clause, distinct := inClause(azure.Column{Name: "Client IP", Type: "string"}, rows, 0, 10)
+	expected := `where ['Client IP'] in ("10.0.0.1", "say \"hi\"", "10.0.0.3")`
+	if clause != expected || distinct != 3 {
+		t.Errorf("Expected %q with 3 values, got %q with %d", expected, clause, distinct)
+	}
+
+	clause, distinct = inClause(azure.Column{Name: "IP", Type: "string"}, rows, 0, 2)
+	if clause != `where IP in ("10.0.0.1", "say \"hi\"")` || distinct != 3 {
+		t.Errorf("Expected the list capped at 2 of 3 values, got %q with %d", clause, distinct)
+	}
+
+	if clause, _ := inClause(azure.Column{Name: "Props", Type: "dynamic"}, [][]interface{}{{map[string]interface{}{}}}, 0, 10); clause != "" {
+		t.Errorf("Expected no clause for incomparable values, got %q", clause)
+	}
+}
+
+func TestModel_CurrentCell(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	url := "https://portal.azure.com/#blade/" + strings.Repeat("x", 200)
+	m := NewModel("", azure.AuthDefault, azure.NewConfig())
+	m.processResults(&azure.QueryResult{Tables: []azure.Table{{
+		Columns: []azure.Column{{Name: "CorrelationId", Type: "string"}, {Name: "Url", Type: "string"}},
+		Rows:    [][]interface{}{{"a1", nil}, {"b2", url}},
+	}}})
+
+	if column, value, _ := m.currentCell(); column != "CorrelationId" || value != "a1" {
+		t.Errorf("Expected CorrelationId a1, got %s %q", column, value)
+	}
+
+	m.table.cursor = 1
+	m.table.scrollColumns(1)
+	if column, value, _ := m.currentCell(); column != "Url" || value != url {
+		t.Errorf("Expected the full Url value, got %s %q", column, value)
+	}
+}
@@ -0,0 +1,189 @@
+package ui
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+	"github.com/mattn/go-runewidth"
+)
+
+// portalMsg reports the result of opening a query in the portal
+type portalMsg struct {
+	url    string
+	opened bool // False when the URL could only be shown
+	err    error
+}
+
+// encodeQuery compresses and encodes a query the way portal share links
+// expect it
+func encodeQuery(query string) (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(query)); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// isoDuration formats a duration as an ISO 8601 duration such as "PT1H" or "P7D"
+func isoDuration(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("P%dD", d/(24*time.Hour))
+	}
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("PT%dH", d/time.Hour)
+	}
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("PT%dM", d/time.Minute)
+	}
+	return fmt.Sprintf("PT%dS", int64(d.Seconds()))
+}
+
+// portalLink returns the Log Analytics link opening query in the workspace
+// with the given resource ID, over timeRange if it isn't empty
+func portalLink(cloud azure.Cloud, resourceID, query, timeRange string) (string, error) {
+	encoded, err := encodeQuery(query)
+	if err != nil {
+		return "", err
+	}
+	link := fmt.Sprintf("%s/#blade/Microsoft_Azure_Monitoring_Logs/LogsBlade/resourceId/%s/source/LogsBlade.AnalyticsShareLinkToQuery/q/%s",
+		strings.TrimRight(cloud.PortalURL, "/"), url.PathEscape(resourceID), url.PathEscape(encoded))
+	if d, err := parseTimeRange(timeRange); err == nil && d > 0 {
+		link += "/timespan/" + isoDuration(d)
+	}
+	return link, nil
+}
+
+// dataExplorerLink returns the Data Explorer web UI link opening query in a
+// cluster's database
+func dataExplorerLink(cloud azure.Cloud, cluster, database, query string) (string, error) {
+	clusterURL, err := azure.ClusterURL(cluster)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := encodeQuery(query)
+	if err != nil {
+		return "", err
+	}
+	host := strings.TrimPrefix(clusterURL, "https://")
+	return fmt.Sprintf("%s/clusters/%s/databases/%s?query=%s",
+		strings.TrimRight(cloud.DataExplorerURL, "/"), url.PathEscape(host), url.PathEscape(database), url.QueryEscape(encoded)), nil
+}
+
+// queryLink returns the link opening the editor's query, with its variables
+// substituted, in the portal, or in the Data Explorer web UI when a cluster
+// is queried
+func (m Model) queryLink() (string, error) {
+	query := strings.TrimSpace(m.editor.Value())
+	if query == "" {
+		return "", errors.New("nothing to open: the query is empty")
+	}
+	query, err := expandVariables(query, m.variables)
+	if err != nil {
+		return "", err
+	}
+	if m.config.Cluster != "" {
+		return dataExplorerLink(m.config.AzureCloud(), m.config.Cluster, m.config.Database, query)
+	}
+	if m.workspaceID == "" {
+		return "", errors.New("no workspace: press F3 to set one")
+	}
+	resourceID := m.config.WorkspaceResourceID(m.workspaceID)
+	if resourceID == "" {
+		return "", fmt.Errorf("no resource ID for workspace %s: add its resource_id to saved_workspaces in config.json", m.workspaceID)
+	}
+	return portalLink(m.config.AzureCloud(), resourceID, query, m.config.TimeRange)
+}
+
+// headless reports whether no browser can be opened, e.g. over SSH or on a
+// Linux machine without a display
+func headless(getenv func(string) string) bool {
+	if getenv("SSH_CONNECTION") != "" || getenv("SSH_TTY") != "" {
+		return true
+	}
+	return runtime.GOOS == "linux" && getenv("DISPLAY") == "" && getenv("WAYLAND_DISPLAY") == ""
+}
+
+// browserCommand returns the command that opens a URL in the default browser
+func browserCommand(link string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", link)
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	default:
+		return exec.Command("xdg-open", link)
+	}
+}
+
+// openInPortal opens the query's portal link in a browser, or only returns
+// it to be shown when running headless or no browser could be started
+func (m Model) openInPortal() tea.Cmd {
+	link, err := m.queryLink()
+	if err != nil {
+		return func() tea.Msg { return portalMsg{err: err} }
+	}
+	return func() tea.Msg {
+		if headless(os.Getenv) {
+			return portalMsg{url: link}
+		}
+		if err := browserCommand(link).Start(); err != nil {
+			return portalMsg{url: link}
+		}
+		return portalMsg{url: link, opened: true}
+	}
+}
+
+// updatePortal reports the outcome of opening a query in the portal
+func (m Model) updatePortal(msg portalMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.err != nil:
+		m.lastError = fmt.Sprintf("Can't open in portal: %v", msg.err)
+	case msg.opened:
+		m.notice = "Opened the query in the browser"
+	default:
+		m.notice = "No browser available: open the link below"
+		m.portalURL = msg.url
+	}
+	return m, nil
+}
+
+// renderPortalLink renders the portal link shown when no browser could be
+// opened, broken across lines since it's usually wider than the terminal
+func (m Model) renderPortalLink() string {
+	width := m.width - 2
+	if width <= 0 {
+		return m.portalURL
+	}
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+	for _, r := range m.portalURL {
+		w := runewidth.RuneWidth(r)
+		if lineWidth+w > width && lineWidth > 0 {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth = 0
+		}
+		line.WriteRune(r)
+		lineWidth += w
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return strings.Join(lines, "\n")
+}
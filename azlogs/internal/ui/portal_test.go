@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"net/url"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+	"github.com/mattn/go-runewidth"
+)
+
+// decodeQuery reverses encodeQuery
+func decodeQuery(t *testing.T, encoded string) string {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("Failed to decode base64: %v", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to open gzip: %v", err)
+	}
+	query, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Failed to decompress: %v", err)
+	}
+	return string(query)
+}
+
+func TestIsoDuration(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{15 * time.Minute, "PT15M"},
+		{4 * time.Hour, "PT4H"},
+		{24 * time.Hour, "P1D"},
+		{7 * 24 * time.Hour, "P7D"},
+		{90 * time.Second, "PT90S"},
+	}
+
+	for _, tt := range tests {
+		if got := isoDuration(tt.d); got != tt.expected {
+			t.Errorf("Expected %s for %v, got %s", tt.expected, tt.d, got)
+		}
+	}
+}
+
+func TestPortalLink(t *testing.T) {
+	resourceID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.OperationalInsights/workspaces/ws"
+	query := "AzureActivity\n| where Level == \"Error\" // 50% of rows"
+
+	link, err := portalLink(azure.CloudUSGov, resourceID, query, "24h")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	prefix := "https://portal.azure.us/#blade/Microsoft_Azure_Monitoring_Logs/LogsBlade/resourceId/" +
+		url.PathEscape(resourceID) + "/source/LogsBlade.AnalyticsShareLinkToQuery/q/"
+	if !strings.HasPrefix(link, prefix) {
+		t.Fatalf("Expected link to start with %s, got %s", prefix, link)
+	}
+	rest, ok := strings.CutSuffix(strings.TrimPrefix(link, prefix), "/timespan/P1D")
+	if !ok {
+		t.Fatalf("Expected link to end with the time span, got %s", link)
+	}
+	if strings.Contains(rest, "/") {
+		t.Errorf("Expected the encoded query to be path escaped, got %s", rest)
+	}
+	encoded, err := url.PathUnescape(rest)
+	if err != nil {
+		t.Fatalf("Failed to unescape query: %v", err)
+	}
+	if got := decodeQuery(t, encoded); got != query {
+		t.Errorf("Expected query %q, got %q", query, got)
+	}
+
+	link, err = portalLink(azure.CloudPublic, resourceID, query, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(link, "timespan") {
+		t.Errorf("Expected no time span without a time range, got %s", link)
+	}
+}
+
+func TestDataExplorerLink(t *testing.T) {
+	link, err := dataExplorerLink(azure.CloudPublic, "help", "Samples", "StormEvents | take 10")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("Invalid link %s: %v", link, err)
+	}
+	if u.Path != "/clusters/help.kusto.windows.net/databases/Samples" {
+		t.Errorf("Expected cluster and database in the path, got %s", u.Path)
+	}
+	if got := decodeQuery(t, u.Query().Get("query")); got != "StormEvents | take 10" {
+		t.Errorf("Expected the query in the link, got %q", got)
+	}
+
+	link, err = dataExplorerLink(azure.CloudUSGov, "https://gov.kusto.usgovcloudapi.net", "Samples", "StormEvents")
+	if err != nil || !strings.HasPrefix(link, "https://dataexplorer.azure.us/clusters/") {
+		t.Errorf("Expected the US Government Data Explorer, got %s (%v)", link, err)
+	}
+}
+
+func TestHeadless(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(name string) string { return vars[name] }
+	}
+
+	if !headless(env(map[string]string{"SSH_CONNECTION": "10.0.0.1 22 10.0.0.2 22", "DISPLAY": ":0"})) {
+		t.Error("Expected SSH sessions to be headless")
+	}
+	if headless(env(map[string]string{"DISPLAY": ":0"})) {
+		t.Error("Expected a local session with a display not to be headless")
+	}
+	if got := headless(env(nil)); got != (runtime.GOOS == "linux") {
+		t.Errorf("Expected no display to be headless only on Linux, got %v", got)
+	}
+}
+
+func TestModel_OpenPortalLink(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	config := azure.NewConfig()
+	config.AddWorkspace(azure.SavedWorkspace{Name: "prod", WorkspaceID: "ws-1"})
+	updated, _ := NewModel("ws-1", azure.AuthDefault, config).Update(tea.WindowSizeMsg{Width: 40, Height: 30})
+	m := updated.(Model)
+	m.editor.SetValue("AzureActivity | take 10")
+
+	if _, err := m.queryLink(); err == nil || !strings.Contains(err.Error(), "resource_id") {
+		t.Errorf("Expected an error pointing to resource_id, got %v", err)
+	}
+
+	config.AddWorkspace(azure.SavedWorkspace{Name: "prod", WorkspaceID: "ws-1", ResourceID: "/subscriptions/sub/workspaces/ws"})
+	m.editor.SetValue("AzureActivity | where Caller == {{user}}")
+	if _, err := m.queryLink(); err == nil || !strings.Contains(err.Error(), "user") {
+		t.Errorf("Expected an error for the undefined variable, got %v", err)
+	}
+	m.editor.SetValue("AzureActivity | take 10")
+	link, err := m.queryLink()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A link that couldn't be opened is shown in full, wrapped to the width
+	updated, _ = m.Update(portalMsg{url: link})
+	m = updated.(Model)
+	if m.portalURL != link {
+		t.Fatalf("Expected the link to be shown, got %q", m.portalURL)
+	}
+	rendered := m.renderPortalLink()
+	if strings.ReplaceAll(rendered, "\n", "") != link {
+		t.Errorf("Expected the rendered link to contain the whole link")
+	}
+	for _, line := range strings.Split(rendered, "\n") {
+		if len(line) > 38 {
+			t.Errorf("Expected lines to fit the terminal, got %d characters", len(line))
+		}
+	}
+
+	// Wide characters are split by their display width
+	m.portalURL = strings.Repeat("界", 30)
+	for _, line := range strings.Split(m.renderPortalLink(), "\n") {
+		if w := runewidth.StringWidth(line); w > 38 {
+			t.Errorf("Expected lines to fit the terminal, got width %d", w)
+		}
+	}
+
+	// The next key dismisses it
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if updated.(Model).portalURL != "" {
+		t.Error("Expected the link to be dismissed by the next key")
+	}
+}
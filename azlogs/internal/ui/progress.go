@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+)
+
+// formatBytes formats a byte count with a binary unit, e.g. 1.5 MB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// describeProgress describes a running query: how long it has run and, once
+// the response starts downloading, how much of it has arrived
+func describeProgress(elapsed time.Duration, received int64) string {
+	s := elapsed.Truncate(100 * time.Millisecond).String()
+	if received > 0 {
+		s += ", " + formatBytes(received) + " received"
+	}
+	return s
+}
+
+// renderQueryProgress renders the loading indicator of a running query
+func (m Model) renderQueryProgress() string {
+	s := m.spinner.View() + " Querying..."
+	if m.queryProgress != nil {
+		s += " " + m.styles.Muted.Render(describeProgress(time.Since(m.queryStarted), m.queryProgress.Bytes()))
+	}
+	return s
+}
@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDescribeProgress(t *testing.T) {
+	tests := []struct {
+		elapsed  time.Duration
+		received int64
+		expected string
+	}{
+		{1234 * time.Millisecond, 0, "1.2s"},
+		{3 * time.Second, 512, "3s, 512 B received"},
+		{12 * time.Second, 1536 * 1024, "12s, 1.5 MB received"},
+	}
+
+	for _, tt := range tests {
+		if got := describeProgress(tt.elapsed, tt.received); got != tt.expected {
+			t.Errorf("describeProgress(%v, %d): expected %q, got %q", tt.elapsed, tt.received, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package ui
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// Report formats
+const (
+	ReportHTML     = "html"
+	ReportMarkdown = "markdown"
+)
+
+// ReportFormat returns the report format for a file: Markdown for .md and
+// .markdown files, HTML otherwise
+func ReportFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return ReportMarkdown
+	}
+	return ReportHTML
+}
+
+// Report is a query together with the result it returned, as a
+// self-contained document for incident write-ups
+type Report struct {
+	Query     string
+	Workspace string
+	TimeRange string // Relative range the query ran over, empty if none
+	Result    *azure.QueryResult
+}
+
+// timeRangeLabel describes the range the query ran over
+func (r Report) timeRangeLabel() string {
+	if r.TimeRange == "" {
+		return "None (filters in the query)"
+	}
+	return "Last " + r.TimeRange
+}
+
+// executedAt returns when the result was received, as shown in the report
+func (r Report) executedAt() string {
+	if r.Result.AsOf.IsZero() {
+		return "unknown"
+	}
+	return r.Result.AsOf.UTC().Format(time.RFC3339)
+}
+
+// warnings describes why the result may be incomplete
+func (r Report) warnings() []string {
+	var warnings []string
+	if r.Result.Truncated {
+		warnings = append(warnings, fmt.Sprintf("Results truncated at %d rows.", r.Result.RowCount))
+	}
+	if r.Result.IsPartial() {
+		warnings = append(warnings, "Partial results, the data is incomplete: "+r.Result.PartialError)
+	}
+	return warnings
+}
+
+// table returns the result's first table, empty if it has none
+func (r Report) table() azure.Table {
+	if len(r.Result.Tables) == 0 {
+		return azure.Table{}
+	}
+	return r.Result.Tables[0]
+}
+
+// Render returns the report in the given format
+func (r Report) Render(format string) (string, error) {
+	if format == ReportMarkdown {
+		return r.Markdown(), nil
+	}
+	return r.HTML()
+}
+
+// Markdown returns the report as Markdown, with the results as a table
+func (r Report) Markdown() string {
+	var b strings.Builder
+	b.WriteString("# Query report\n\n")
+	if r.Workspace != "" {
+		fmt.Fprintf(&b, "- **Workspace:** %s\n", r.Workspace)
+	}
+	fmt.Fprintf(&b, "- **Executed:** %s\n", r.executedAt())
+	fmt.Fprintf(&b, "- **Time range:** %s\n", r.timeRangeLabel())
+	fmt.Fprintf(&b, "- **Rows:** %d in %s\n", r.Result.RowCount, r.Result.Duration.Round(time.Millisecond))
+	for _, w := range r.warnings() {
+		fmt.Fprintf(&b, "\n> **Warning:** %s\n", w)
+	}
+
+	// A fence longer than any backtick run in the query keeps it intact
+	fence := "```"
+	for strings.Contains(r.Query, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(&b, "\n## Query\n\n%skql\n%s\n%s\n\n## Results\n\n", fence, strings.TrimSpace(r.Query), fence)
+
+	table := r.table()
+	if len(table.Rows) == 0 {
+		b.WriteString("_The query returned no rows._\n")
+	} else {
+		b.WriteString(MarkdownTable(table.Columns, table.Rows, 0))
+	}
+	return b.String()
+}
+
+// reportTemplate is the HTML report, styled inline so the file stands alone
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Query report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 0.25em 1em; }
+dt { font-weight: 600; }
+dd { margin: 0; }
+pre { background: #f6f8fa; padding: 1em; border-radius: 6px; overflow-x: auto; }
+.warning { background: #fff8c5; border: 1px solid #d4a72c; padding: 0.5em 1em; border-radius: 6px; }
+table { border-collapse: collapse; font-size: 0.9em; }
+th, td { border: 1px solid #d0d7de; padding: 0.3em 0.6em; text-align: left; vertical-align: top; white-space: pre-wrap; }
+th { background: #f6f8fa; }
+tr:nth-child(even) td { background: #fafbfc; }
+td.number { text-align: right; font-variant-numeric: tabular-nums; }
+</style>
+</head>
+<body>
+<h1>Query report</h1>
+<dl>
+{{- if .Workspace}}
+<dt>Workspace</dt><dd>{{.Workspace}}</dd>
+{{- end}}
+<dt>Executed</dt><dd>{{.ExecutedAt}}</dd>
+<dt>Time range</dt><dd>{{.TimeRange}}</dd>
+<dt>Rows</dt><dd>{{.RowCount}} in {{.Duration}}</dd>
+</dl>
+{{- range .Warnings}}
+<p class="warning">{{.}}</p>
+{{- end}}
+<h2>Query</h2>
+<pre><code>{{.Query}}</code></pre>
+<h2>Results</h2>
+{{- if .Rows}}
+<table>
+<thead><tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr>{{range .}}<td{{if .Number}} class="number"{{end}}>{{.Text}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+{{- else}}
+<p><em>The query returned no rows.</em></p>
+{{- end}}
+</body>
+</html>
+`))
+
+// reportCell is a formatted cell of the HTML report
+type reportCell struct {
+	Text   string
+	Number bool
+}
+
+// HTML returns the report as a standalone HTML page with a styled table
+func (r Report) HTML() (string, error) {
+	table := r.table()
+	data := struct {
+		Workspace, ExecutedAt, TimeRange, Duration, Query string
+		RowCount                                          int
+		Warnings, Columns                                 []string
+		Rows                                              [][]reportCell
+	}{
+		Workspace:  r.Workspace,
+		ExecutedAt: r.executedAt(),
+		TimeRange:  r.timeRangeLabel(),
+		Duration:   r.Result.Duration.Round(time.Millisecond).String(),
+		Query:      strings.TrimSpace(r.Query),
+		RowCount:   r.Result.RowCount,
+		Warnings:   r.warnings(),
+	}
+	for _, col := range table.Columns {
+		data.Columns = append(data.Columns, col.Name)
+	}
+	for _, row := range table.Rows {
+		cells := make([]reportCell, len(table.Columns))
+		for i, col := range table.Columns {
+			if i < len(row) {
+				cells[i] = reportCell{Text: StripANSI(formatCell(row[i], col.Type)), Number: isNumericType(col.Type)}
+			}
+		}
+		data.Rows = append(data.Rows, cells)
+	}
+
+	var b strings.Builder
+	if err := reportTemplate.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// saveReport writes the displayed query and result as an HTML report in the
+// reports directory
+func (m *Model) saveReport() {
+	if m.result == nil {
+		m.lastError = "No results to report"
+		return
+	}
+
+	r := Report{Query: m.lastQuery, Workspace: m.workspaceID, TimeRange: m.activeTimeRange(), Result: m.exportResult()}
+	if m.snapshot != nil {
+		r.Workspace = m.snapshot.Workspace
+		r.TimeRange = ""
+	}
+	content, err := r.HTML()
+	if err != nil {
+		m.lastError = fmt.Sprintf("Failed to build report: %v", err)
+		return
+	}
+	path := filepath.Join(azure.ConfigDir(), "reports", "report-"+time.Now().Format("20060102-150405")+".html")
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err == nil {
+		err = os.WriteFile(path, []byte(content), 0644)
+	}
+	if err != nil {
+		m.lastError = fmt.Sprintf("Failed to save report: %v", err)
+		return
+	}
+	m.notice = "Report saved to " + path
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestReportFormat(t *testing.T) {
+	tests := map[string]string{
+		"incident.md":       ReportMarkdown,
+		"incident.Markdown": ReportMarkdown,
+		"incident.html":     ReportHTML,
+		"incident":          ReportHTML,
+	}
+	for path, expected := range tests {
+		if got := ReportFormat(path); got != expected {
+			t.Errorf("ReportFormat(%q): expected %q, got %q", path, expected, got)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	r := Report{
+		Query:     "AzureActivity\n| where Caller == \"<admin>\"",
+		Workspace: "ws-1",
+		TimeRange: "24h",
+		Result: &azure.QueryResult{
+			RowCount:  1,
+			Duration:  1500 * time.Millisecond,
+			AsOf:      time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			Truncated: true,
+			Tables: []azure.Table{{
+				Columns: []azure.Column{{Name: "Caller", Type: "string"}, {Name: "Count", Type: "long"}},
+				Rows:    [][]interface{}{{"<admin>", 3.0}},
+			}},
+		},
+	}
+
+	md := r.Markdown()
+	for _, want := range []string{
+		"- **Workspace:** ws-1",
+		"- **Executed:** 2024-05-01T10:00:00Z",
+		"- **Time range:** Last 24h",
+		"- **Rows:** 1 in 1.5s",
+		"> **Warning:** Results truncated at 1 rows.",
+		"```kql\nAzureActivity\n| where Caller == \"<admin>\"\n```",
+		"| <admin> |     3 |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected the Markdown report to contain %q, got:\n%s", want, md)
+		}
+	}
+
+	html, err := r.HTML()
+	if err != nil {
+		t.Fatalf("HTML report failed: %v", err)
+	}
+	for _, want := range []string{
+		"<dt>Time range</dt><dd>Last 24h</dd>",
+		"<pre><code>AzureActivity\n| where Caller == &#34;&lt;admin&gt;&#34;</code></pre>",
+		"<td>&lt;admin&gt;</td><td class=\"number\">3</td>",
+		"<p class=\"warning\">Results truncated at 1 rows.</p>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected the HTML report to contain %q, got:\n%s", want, html)
+		}
+	}
+}
+
+func TestReport_NoRows(t *testing.T) {
+	r := Report{Query: "T | take 0", Result: &azure.QueryResult{}}
+	if md := r.Markdown(); !strings.Contains(md, "_The query returned no rows._") || !strings.Contains(md, "None (filters in the query)") {
+		t.Errorf("Expected the empty result noted, got:\n%s", md)
+	}
+	if html, _ := r.HTML(); !strings.Contains(html, "The query returned no rows.") || strings.Contains(html, "<table>") {
+		t.Errorf("Expected no table for an empty result, got:\n%s", html)
+	}
+}
@@ -0,0 +1,229 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// fetchRetryAfter is how long a failed schema or table list fetch is
+// remembered before it is tried again, so a failing table isn't refetched on
+// every keystroke
+const fetchRetryAfter = time.Minute
+
+// fetchFailure is a failed background fetch and when it failed
+type fetchFailure struct {
+	err error
+	at  time.Time
+}
+
+// recent reports whether the fetch failed too recently to retry
+func (f fetchFailure) recent() bool {
+	return f.err != nil && time.Since(f.at) < fetchRetryAfter
+}
+
+// openSchemaView shows the schema explorer with an empty table filter,
+// retrying the table list if it failed a while ago
+func (m *Model) openSchemaView() tea.Cmd {
+	m.schemaFilter.SetValue("")
+	m.schemaFilter.Focus()
+	m.filterSchemaTables()
+	m.currentView = ViewSchema
+	if len(m.availableTables) == 0 && m.tablesFailure.err != nil && !m.tablesFailure.recent() && m.client != nil {
+		m.tablesFailure = fetchFailure{}
+		return m.loadAvailableTables()
+	}
+	return m.fetchSelectedSchema()
+}
+
+// filterSchemaTables lists the available tables whose name contains the filter
+func (m *Model) filterSchemaTables() {
+	filter := strings.ToLower(strings.TrimSpace(m.schemaFilter.Value()))
+	m.schemaTables = nil
+	for _, table := range m.availableTables {
+		if strings.Contains(strings.ToLower(table), filter) {
+			m.schemaTables = append(m.schemaTables, table)
+		}
+	}
+	m.schemaIndex = 0
+}
+
+// selectedSchemaTable returns the highlighted table, or "" if there is none
+func (m Model) selectedSchemaTable() string {
+	if m.schemaIndex < 0 || m.schemaIndex >= len(m.schemaTables) {
+		return ""
+	}
+	return m.schemaTables[m.schemaIndex]
+}
+
+// fetchSelectedSchema loads the highlighted table's schema if it isn't cached
+func (m *Model) fetchSelectedSchema() tea.Cmd {
+	return m.fetchSchema(m.selectedSchemaTable())
+}
+
+// fetchSchema fetches a table's schema in the background unless it is
+// cached, already being fetched or recently failed
+func (m *Model) fetchSchema(table string) tea.Cmd {
+	if table == "" || m.client == nil || m.schemaFetching[table] || m.schemaFailures[table].recent() {
+		return nil
+	}
+	if _, ok := m.schemaCache[table]; ok {
+		return nil
+	}
+
+	m.schemaFetching[table] = true
+	client, workspace := m.client, m.workspaceID
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		columns, err := client.GetTableSchema(ctx, table)
+		return schemaMsg{workspace: workspace, tableName: table, columns: columns, err: err}
+	}
+}
+
+// resetSchemas forgets the tables, schemas and ingestion rates of the
+// previous workspace so autocomplete and the cost hint don't use them for
+// the next one. The old client is dropped too, so nothing is fetched with it
+// until the new one connects
+func (m *Model) resetSchemas() {
+	m.client = nil
+	m.availableTables = nil
+	m.ingestionRates = nil
+	m.schemaCache = make(map[string][]azure.Column)
+	m.schemaFetching = make(map[string]bool)
+	m.schemaFailures = make(map[string]fetchFailure)
+	m.tablesFailure = fetchFailure{}
+	m.autocompleteEngine.SetTables(nil)
+	m.autocompleteEngine.SetSchemas(m.schemaCache)
+}
+
+func (m Model) updateSchemaView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Select):
+		if table := m.selectedSchemaTable(); table != "" {
+			m.editor.InsertText(table)
+			m.currentView = ViewQuery
+			m.editor.Focus()
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.SuggestionPrev):
+		if m.schemaIndex > 0 {
+			m.schemaIndex--
+		}
+		return m, m.fetchSelectedSchema()
+
+	case key.Matches(msg, m.keys.SuggestionNext):
+		if m.schemaIndex < len(m.schemaTables)-1 {
+			m.schemaIndex++
+		}
+		return m, m.fetchSelectedSchema()
+	}
+
+	// Everything else edits the table filter
+	previous := m.schemaFilter.Value()
+	var cmd tea.Cmd
+	m.schemaFilter, cmd = m.schemaFilter.Update(msg)
+	if m.schemaFilter.Value() != previous {
+		m.filterSchemaTables()
+		return m, tea.Batch(cmd, m.fetchSelectedSchema())
+	}
+	return m, cmd
+}
+
+func (m Model) renderSchemaView() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.Header.Render("Schema Explorer"))
+	b.WriteString("\n\n")
+
+	if len(m.availableTables) == 0 {
+		if !m.connected {
+			b.WriteString(m.styles.Muted.Render("Connect to a workspace to explore its tables."))
+		} else if m.tablesFailure.err != nil {
+			b.WriteString(m.styles.Error.Render(fmt.Sprintf("Failed to load tables: %v", m.tablesFailure.err)))
+		} else {
+			b.WriteString(m.styles.Muted.Render("Loading tables..."))
+		}
+		return b.String()
+	}
+
+	b.WriteString("Filter: ")
+	b.WriteString(m.schemaFilter.View())
+	b.WriteString("\n\n")
+
+	height := max(m.height-14, 5)
+	tablesPane := m.renderSchemaTables(height)
+	columnsPane := m.renderSchemaColumns(height)
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, tablesPane, "   ", columnsPane))
+
+	return b.String()
+}
+
+// renderSchemaTables renders the filtered table list, scrolled to keep the
+// selection visible
+func (m Model) renderSchemaTables(height int) string {
+	var lines []string
+	lines = append(lines, m.styles.Bold.Render(fmt.Sprintf("Tables (%d)", len(m.schemaTables))))
+
+	if len(m.schemaTables) == 0 {
+		lines = append(lines, m.styles.Muted.Render("  No matching tables"))
+	}
+
+	start := max(m.schemaIndex-height+1, 0)
+	end := min(start+height, len(m.schemaTables))
+	for i := start; i < end; i++ {
+		prefix := "  "
+		style := m.styles.Muted
+		if i == m.schemaIndex {
+			prefix = "▶ "
+			style = m.styles.Bold
+		}
+		lines = append(lines, style.Render(prefix+truncateString(m.schemaTables[i], 40)))
+	}
+
+	return lipgloss.NewStyle().Width(44).Render(strings.Join(lines, "\n"))
+}
+
+// renderSchemaColumns renders the columns and types of the selected table
+func (m Model) renderSchemaColumns(height int) string {
+	table := m.selectedSchemaTable()
+	if table == "" {
+		return ""
+	}
+
+	var lines []string
+	lines = append(lines, m.styles.Bold.Render(table))
+
+	columns, ok := m.schemaCache[table]
+	switch {
+	case !ok && m.schemaFetching[table]:
+		lines = append(lines, m.styles.Muted.Render("Loading schema..."))
+	case !ok && m.schemaFailures[table].err != nil:
+		lines = append(lines, m.styles.Error.Render(truncateString(fmt.Sprintf("Failed to load schema: %v", m.schemaFailures[table].err), 60)))
+	case !ok:
+		lines = append(lines, m.styles.Muted.Render("Schema not loaded"))
+	case len(columns) == 0:
+		lines = append(lines, m.styles.Muted.Render("No columns"))
+	}
+
+	nameWidth := 0
+	for _, col := range columns {
+		nameWidth = max(nameWidth, len(col.Name))
+	}
+	for i, col := range columns {
+		if i >= height {
+			lines = append(lines, m.styles.Muted.Render(fmt.Sprintf("... and %d more", len(columns)-height)))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%-*s  %s", nameWidth, col.Name, m.styles.Muted.Render(col.Type)))
+	}
+
+	return strings.Join(lines, "\n")
+}
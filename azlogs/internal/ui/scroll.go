@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ScrollView is a scrollable text panel that wraps long lines to its width.
+// Text panels that may not fit the terminal (help, explanations) embed one.
+type ScrollView struct {
+	content string
+	lines   []string
+	offset  int
+	width   int
+	height  int
+	keys    *KeyMap
+}
+
+// NewScrollView creates a new scroll view
+func NewScrollView() ScrollView {
+	return ScrollView{
+		width:  80,
+		height: 20,
+		keys:   DefaultKeyMap(),
+	}
+}
+
+// SetKeyMap sets the keybindings used for scrolling
+func (s *ScrollView) SetKeyMap(keys *KeyMap) {
+	s.keys = keys
+}
+
+// SetContent replaces the text and scrolls back to the top
+func (s *ScrollView) SetContent(content string) {
+	s.content = content
+	s.offset = 0
+	s.wrap()
+}
+
+// SetSize sets the panel dimensions and re-wraps the text
+func (s *ScrollView) SetSize(width, height int) {
+	if width < 10 {
+		width = 10
+	}
+	if height < 1 {
+		height = 1
+	}
+	s.width = width
+	s.height = height
+	s.wrap()
+}
+
+// wrap splits the content into lines no wider than the panel
+func (s *ScrollView) wrap() {
+	s.lines = nil
+	for _, line := range strings.Split(s.content, "\n") {
+		if lipgloss.Width(line) <= s.width {
+			s.lines = append(s.lines, line)
+			continue
+		}
+
+		// Indent continuation lines like the original line
+		text := strings.TrimLeft(line, " ")
+		indent := line[:len(line)-len(text)]
+		if len(indent) > s.width/2 {
+			indent = ""
+		}
+		wrapped := lipgloss.NewStyle().Width(s.width - len(indent)).Render(text)
+		for _, w := range strings.Split(wrapped, "\n") {
+			s.lines = append(s.lines, indent+strings.TrimRight(w, " "))
+		}
+	}
+	s.clamp()
+}
+
+// maxOffset returns the largest offset that still fills the panel
+func (s ScrollView) maxOffset() int {
+	if len(s.lines) <= s.height {
+		return 0
+	}
+	return len(s.lines) - s.height
+}
+
+func (s *ScrollView) clamp() {
+	if s.offset > s.maxOffset() {
+		s.offset = s.maxOffset()
+	}
+	if s.offset < 0 {
+		s.offset = 0
+	}
+}
+
+// Update handles scrolling keys and mouse wheel events
+func (s ScrollView) Update(msg tea.Msg) (ScrollView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, s.keys.Up):
+			s.offset--
+		case key.Matches(msg, s.keys.Down):
+			s.offset++
+		case key.Matches(msg, s.keys.PageUp):
+			s.offset -= s.height
+		case key.Matches(msg, s.keys.PageDown):
+			s.offset += s.height
+		case key.Matches(msg, s.keys.Top):
+			s.offset = 0
+		case key.Matches(msg, s.keys.Bottom):
+			s.offset = s.maxOffset()
+		}
+
+	case tea.MouseMsg:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			s.offset -= 3
+		case tea.MouseButtonWheelDown:
+			s.offset += 3
+		}
+	}
+
+	s.clamp()
+	return s, nil
+}
+
+// View renders the visible lines
+func (s ScrollView) View() string {
+	end := s.offset + s.height
+	if end > len(s.lines) {
+		end = len(s.lines)
+	}
+	return strings.Join(s.lines[s.offset:end], "\n")
+}
+
+// Scrollable reports whether the content is taller than the panel
+func (s ScrollView) Scrollable() bool {
+	return len(s.lines) > s.height
+}
+
+// ScrollInfo describes the visible range, e.g. "Lines 1-20 of 45"
+func (s ScrollView) ScrollInfo() string {
+	end := s.offset + s.height
+	if end > len(s.lines) {
+		end = len(s.lines)
+	}
+	return fmt.Sprintf("Lines %d-%d of %d", s.offset+1, end, len(s.lines))
+}
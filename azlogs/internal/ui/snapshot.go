@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// LoadSnapshot shows a saved result in the results view, with its query in
+// the editor. The results are marked as historical until a query is run.
+func (m *Model) LoadSnapshot(s *azure.Snapshot) {
+	m.editor.SetValue(s.Query)
+	m.lastQuery = s.Query
+	m.processResults(s.Result)
+	m.snapshot = s
+	m.startupQuery = "" // Keep the snapshot on screen
+}
+
+// saveSnapshot saves the displayed result to the snapshots directory
+func (m *Model) saveSnapshot() {
+	if m.result == nil {
+		m.lastError = "No results to save"
+		return
+	}
+
+	workspace := m.workspaceID
+	if m.snapshot != nil {
+		workspace = m.snapshot.Workspace
+	}
+	s := azure.NewSnapshot(m.lastQuery, workspace, m.exportResult())
+	path := azure.DefaultSnapshotPath(s.SavedAt)
+	if err := s.Save(path); err != nil {
+		m.lastError = fmt.Sprintf("Failed to save snapshot: %v", err)
+		return
+	}
+	m.notice = "Snapshot saved to " + path
+}
+
+// renderSnapshotBanner renders the banner shown over results loaded from a
+// snapshot, so they aren't mistaken for live data
+func (m Model) renderSnapshotBanner() string {
+	text := "Snapshot saved " + m.snapshot.SavedAt.Local().Format(time.DateTime)
+	if m.snapshot.Workspace != "" {
+		text += " from " + m.snapshot.Workspace
+	}
+	return m.styles.Warning.Bold(true).Render("⏱ " + text + " - historical results, not live. Run a query to refresh.")
+}
@@ -1,20 +1,217 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+	"sort"
+	"strings"
 
-// Color palette
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme defines the color palette for the UI, the syntax highlighter and the
+// suggestion popup
+type Theme struct {
+	Primary      lipgloss.Color
+	Secondary    lipgloss.Color
+	Success      lipgloss.Color
+	Warning      lipgloss.Color
+	Error        lipgloss.Color
+	Muted        lipgloss.Color
+	Border       lipgloss.Color
+	Bg           lipgloss.Color
+	BgAlt        lipgloss.Color
+	Text         lipgloss.Color
+	TextAlt      lipgloss.Color
+	SelectedText lipgloss.Color
+
+	// Syntax highlighting
+	Keyword  lipgloss.Color
+	Operator lipgloss.Color
+	Pipe     lipgloss.Color
+	String   lipgloss.Color
+	Number   lipgloss.Color
+	Function lipgloss.Color
+
+	// Suggestion popup
+	PopupBorder     lipgloss.Color
+	PopupItem       lipgloss.Color
+	PopupSelectedBg lipgloss.Color
+	PopupSelectedFg lipgloss.Color
+	PopupIcon       lipgloss.Color
+	PopupDesc       lipgloss.Color
+}
+
+// Themes contains the built-in themes by name
+var Themes = map[string]Theme{
+	"dark": {
+		Primary:      "#7C3AED", // Purple
+		Secondary:    "#06B6D4", // Cyan
+		Success:      "#10B981", // Green
+		Warning:      "#F59E0B", // Amber
+		Error:        "#EF4444", // Red
+		Muted:        "#6B7280", // Gray
+		Border:       "#374151", // Dark gray
+		Bg:           "#1F2937", // Background
+		BgAlt:        "#111827", // Alt background
+		Text:         "#FFFFFF",
+		TextAlt:      "#E5E7EB",
+		SelectedText: "#FFFFFF",
+
+		Keyword:  "#5c6bc0",
+		Operator: "#ff9800",
+		Pipe:     "#757575",
+		String:   "#4caf50",
+		Number:   "#e91e63",
+		Function: "#ffc107",
+
+		PopupBorder:     "240",
+		PopupItem:       "252",
+		PopupSelectedBg: "62",
+		PopupSelectedFg: "255",
+		PopupIcon:       "240",
+		PopupDesc:       "245",
+	},
+	"light": {
+		Primary:      "#6D28D9",
+		Secondary:    "#0E7490",
+		Success:      "#047857",
+		Warning:      "#B45309",
+		Error:        "#B91C1C",
+		Muted:        "#4B5563",
+		Border:       "#9CA3AF",
+		Bg:           "#F3F4F6",
+		BgAlt:        "#E5E7EB",
+		Text:         "#111827",
+		TextAlt:      "#374151",
+		SelectedText: "#FFFFFF",
+
+		Keyword:  "#3949AB",
+		Operator: "#E65100",
+		Pipe:     "#616161",
+		String:   "#2E7D32",
+		Number:   "#AD1457",
+		Function: "#8D6E00",
+
+		PopupBorder:     "#9CA3AF",
+		PopupItem:       "#1F2937",
+		PopupSelectedBg: "#6D28D9",
+		PopupSelectedFg: "#FFFFFF",
+		PopupIcon:       "#6B7280",
+		PopupDesc:       "#4B5563",
+	},
+	"high-contrast": {
+		Primary:      "#FFFF00",
+		Secondary:    "#00FFFF",
+		Success:      "#00FF00",
+		Warning:      "#FFA500",
+		Error:        "#FF5555",
+		Muted:        "#C0C0C0",
+		Border:       "#FFFFFF",
+		Bg:           "#000000",
+		BgAlt:        "#000000",
+		Text:         "#FFFFFF",
+		TextAlt:      "#FFFFFF",
+		SelectedText: "#000000",
+
+		Keyword:  "#00FFFF",
+		Operator: "#FFFF00",
+		Pipe:     "#FFFFFF",
+		String:   "#00FF00",
+		Number:   "#FF00FF",
+		Function: "#FFA500",
+
+		PopupBorder:     "#FFFFFF",
+		PopupItem:       "#FFFFFF",
+		PopupSelectedBg: "#FFFF00",
+		PopupSelectedFg: "#000000",
+		PopupIcon:       "#FFFFFF",
+		PopupDesc:       "#C0C0C0",
+	},
+}
+
+// activeTheme is the theme new styles are built from
+var activeTheme = Themes["dark"]
+
+// noColor is set by DisableColor to build monochrome styles
+var noColor bool
+
+// Color palette (from the active theme)
 var (
-	ColorPrimary   = lipgloss.Color("#7C3AED") // Purple
-	ColorSecondary = lipgloss.Color("#06B6D4") // Cyan
-	ColorSuccess   = lipgloss.Color("#10B981") // Green
-	ColorWarning   = lipgloss.Color("#F59E0B") // Amber
-	ColorError     = lipgloss.Color("#EF4444") // Red
-	ColorMuted     = lipgloss.Color("#6B7280") // Gray
-	ColorBorder    = lipgloss.Color("#374151") // Dark gray
-	ColorBg        = lipgloss.Color("#1F2937") // Background
-	ColorBgAlt     = lipgloss.Color("#111827") // Alt background
+	ColorPrimary      = activeTheme.Primary
+	ColorSecondary    = activeTheme.Secondary
+	ColorSuccess      = activeTheme.Success
+	ColorWarning      = activeTheme.Warning
+	ColorError        = activeTheme.Error
+	ColorMuted        = activeTheme.Muted
+	ColorBorder       = activeTheme.Border
+	ColorBg           = activeTheme.Bg
+	ColorBgAlt        = activeTheme.BgAlt
+	ColorText         = activeTheme.Text
+	ColorTextAlt      = activeTheme.TextAlt
+	ColorSelectedText = activeTheme.SelectedText
 )
 
+// ThemeNames returns the names of the built-in themes
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyTheme makes the named theme active for all styles created afterwards.
+// An empty name picks "dark" or "light" based on the terminal background.
+// It must be called before the UI components are created.
+func ApplyTheme(name string) error {
+	if name == "" {
+		name = "light"
+		if lipgloss.HasDarkBackground() {
+			name = "dark"
+		}
+	}
+
+	theme, ok := Themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+
+	setTheme(theme)
+	return nil
+}
+
+// DisableColor switches the UI to monochrome styles and turns off syntax
+// highlighting, following the NO_COLOR convention. It must be called before
+// the UI components are created.
+func DisableColor() {
+	noColor = true
+	setTheme(Theme{})
+}
+
+// ColorDisabled reports whether DisableColor has been called
+func ColorDisabled() bool {
+	return noColor
+}
+
+// setTheme updates the active theme and the derived color palette
+func setTheme(theme Theme) {
+	activeTheme = theme
+	ColorPrimary = theme.Primary
+	ColorSecondary = theme.Secondary
+	ColorSuccess = theme.Success
+	ColorWarning = theme.Warning
+	ColorError = theme.Error
+	ColorMuted = theme.Muted
+	ColorBorder = theme.Border
+	ColorBg = theme.Bg
+	ColorBgAlt = theme.BgAlt
+	ColorText = theme.Text
+	ColorTextAlt = theme.TextAlt
+	ColorSelectedText = theme.SelectedText
+	setHighlightColors(theme)
+}
+
 // Styles contains all UI styles
 type Styles struct {
 	Title        lipgloss.Style
@@ -32,7 +229,14 @@ type Styles struct {
 	TableHeader  lipgloss.Style
 	TableRow     lipgloss.Style
 	TableRowAlt  lipgloss.Style
+	CellDatetime lipgloss.Style
+	CellNumber   lipgloss.Style
+	CellBool     lipgloss.Style
+	DiffAdded    lipgloss.Style
+	DiffRemoved  lipgloss.Style
+	DiffChanged  lipgloss.Style
 	Selected     lipgloss.Style
+	SelectedCell lipgloss.Style
 	Prompt       lipgloss.Style
 	Input        lipgloss.Style
 	Help         lipgloss.Style
@@ -45,7 +249,7 @@ type Styles struct {
 
 // DefaultStyles returns the default style configuration
 func DefaultStyles() *Styles {
-	return &Styles{
+	styles := &Styles{
 		Title: lipgloss.NewStyle().
 			Bold(true).
 			Foreground(ColorPrimary).
@@ -64,7 +268,7 @@ func DefaultStyles() *Styles {
 			Foreground(ColorPrimary),
 
 		StatusBarVal: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF")),
+			Foreground(ColorText),
 
 		Error: lipgloss.NewStyle().
 			Bold(true).
@@ -104,15 +308,42 @@ func DefaultStyles() *Styles {
 			BorderForeground(ColorBorder),
 
 		TableRow: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF")),
+			Foreground(ColorText),
 
 		TableRowAlt: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#E5E7EB")),
+			Foreground(ColorTextAlt),
+
+		// Typed cells use the syntax highlighting palette
+		CellDatetime: lipgloss.NewStyle().
+			Foreground(activeTheme.Keyword),
+
+		CellNumber: lipgloss.NewStyle().
+			Foreground(activeTheme.Number),
+
+		CellBool: lipgloss.NewStyle().
+			Foreground(activeTheme.Operator),
+
+		// Rows compared with a diff baseline
+		DiffAdded: lipgloss.NewStyle().
+			Foreground(ColorSuccess),
+
+		DiffRemoved: lipgloss.NewStyle().
+			Foreground(ColorError).
+			Strikethrough(true),
+
+		DiffChanged: lipgloss.NewStyle().
+			Foreground(ColorWarning),
 
 		Selected: lipgloss.NewStyle().
 			Bold(true).
 			Background(ColorPrimary).
-			Foreground(lipgloss.Color("#FFFFFF")),
+			Foreground(ColorSelectedText),
+
+		SelectedCell: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true).
+			Background(ColorSecondary).
+			Foreground(ColorSelectedText),
 
 		Prompt: lipgloss.NewStyle().
 			Bold(true).
@@ -146,6 +377,14 @@ func DefaultStyles() *Styles {
 		Spinner: lipgloss.NewStyle().
 			Foreground(ColorPrimary),
 	}
+
+	// Without colors the selection needs another way to stand out
+	if noColor {
+		styles.Selected = lipgloss.NewStyle().Bold(true).Reverse(true)
+		styles.SelectedCell = lipgloss.NewStyle().Bold(true).Underline(true)
+	}
+
+	return styles
 }
 
 // Logo returns the ASCII art logo
@@ -15,16 +15,20 @@ type SuggestionPopup struct {
 	maxVisible    int
 	scrollOffset  int
 	width         int
+	maxHeight     int // Lines the popup may take, 0 for no limit
+	hint          string
+	match         string // Typed word the suggestions matched, highlighted in them
 	styles        *PopupStyles
 }
 
 // PopupStyles defines styling for the suggestion popup
 type PopupStyles struct {
-	Box           lipgloss.Style
-	Item          lipgloss.Style
-	SelectedItem  lipgloss.Style
-	TypeIcon      lipgloss.Style
-	Description   lipgloss.Style
+	Box          lipgloss.Style
+	Item         lipgloss.Style
+	SelectedItem lipgloss.Style
+	Match        lipgloss.Style
+	TypeIcon     lipgloss.Style
+	Description  lipgloss.Style
 }
 
 // NewSuggestionPopup creates a new suggestion popup
@@ -38,23 +42,32 @@ func NewSuggestionPopup() *SuggestionPopup {
 
 // DefaultPopupStyles returns default popup styling
 func DefaultPopupStyles() *PopupStyles {
-	return &PopupStyles{
+	styles := &PopupStyles{
 		Box: lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("240")).
+			BorderForeground(activeTheme.PopupBorder).
 			Padding(0, 1),
 		Item: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252")),
+			Foreground(activeTheme.PopupItem),
 		SelectedItem: lipgloss.NewStyle().
-			Background(lipgloss.Color("62")).
-			Foreground(lipgloss.Color("255")).
+			Background(activeTheme.PopupSelectedBg).
+			Foreground(activeTheme.PopupSelectedFg).
 			Bold(true),
+		Match: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true),
 		TypeIcon: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")),
+			Foreground(activeTheme.PopupIcon),
 		Description: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("245")).
+			Foreground(activeTheme.PopupDesc).
 			Italic(true),
 	}
+
+	if noColor {
+		styles.SelectedItem = lipgloss.NewStyle().Bold(true).Reverse(true)
+	}
+
+	return styles
 }
 
 // SetSuggestions updates the suggestions list
@@ -65,6 +78,22 @@ func (p *SuggestionPopup) SetSuggestions(suggestions []Suggestion) {
 	p.visible = len(suggestions) > 0
 }
 
+// SetHint sets a non-selectable line shown above the suggestions, such as a
+// function signature
+func (p *SuggestionPopup) SetHint(hint string) {
+	p.hint = hint
+}
+
+// SetMatch sets the typed word to highlight in the suggestions
+func (p *SuggestionPopup) SetMatch(word string) {
+	p.match = word
+}
+
+// HasHint returns whether a hint is set
+func (p *SuggestionPopup) HasHint() bool {
+	return p.hint != ""
+}
+
 // Show makes the popup visible
 func (p *SuggestionPopup) Show() {
 	p.visible = true
@@ -73,6 +102,8 @@ func (p *SuggestionPopup) Show() {
 // Hide hides the popup
 func (p *SuggestionPopup) Hide() {
 	p.visible = false
+	p.hint = ""
+	p.match = ""
 	p.suggestions = nil
 	p.selectedIndex = 0
 	p.scrollOffset = 0
@@ -94,8 +125,8 @@ func (p *SuggestionPopup) Next() {
 		p.scrollOffset = 0
 	}
 	// Adjust scroll
-	if p.selectedIndex >= p.scrollOffset+p.maxVisible {
-		p.scrollOffset = p.selectedIndex - p.maxVisible + 1
+	if visible := p.visibleItems(); p.selectedIndex >= p.scrollOffset+visible {
+		p.scrollOffset = p.selectedIndex - visible + 1
 	}
 }
 
@@ -107,7 +138,7 @@ func (p *SuggestionPopup) Previous() {
 	p.selectedIndex--
 	if p.selectedIndex < 0 {
 		p.selectedIndex = len(p.suggestions) - 1
-		p.scrollOffset = max(0, len(p.suggestions)-p.maxVisible)
+		p.scrollOffset = max(0, len(p.suggestions)-p.visibleItems())
 	}
 	// Adjust scroll
 	if p.selectedIndex < p.scrollOffset {
@@ -136,6 +167,28 @@ func (p *SuggestionPopup) SetWidth(width int) {
 	p.width = width
 }
 
+// SetMaxHeight limits the lines the popup takes, border included, so it
+// fits the room below the editor. 0 removes the limit
+func (p *SuggestionPopup) SetMaxHeight(height int) {
+	p.maxHeight = height
+}
+
+// visibleItems returns how many suggestions are listed at once, fewer than
+// maxVisible when the height is limited, but always at least one
+func (p *SuggestionPopup) visibleItems() int {
+	if p.maxHeight <= 0 {
+		return p.maxVisible
+	}
+	room := p.maxHeight - 2 // Border
+	if p.hint != "" {
+		room--
+	}
+	if len(p.suggestions) > min(p.maxVisible, room) {
+		room-- // Scroll indicator
+	}
+	return max(1, min(p.maxVisible, room))
+}
+
 // typeIcon returns an icon for the suggestion type
 func typeIcon(t string) string {
 	switch t {
@@ -156,14 +209,22 @@ func typeIcon(t string) string {
 
 // View renders the popup
 func (p *SuggestionPopup) View() string {
-	if !p.visible || len(p.suggestions) == 0 {
+	if p.hint == "" && (!p.visible || len(p.suggestions) == 0) {
 		return ""
 	}
 
 	var lines []string
 
+	// Lines are cut to the box's inner width, measured in display columns,
+	// so wide characters never wrap them
+	contentWidth := p.width - 2 // Account for padding
+
+	if p.hint != "" {
+		lines = append(lines, p.styles.Description.Render(truncateString(p.hint, contentWidth)))
+	}
+
 	// Calculate visible range
-	endIdx := p.scrollOffset + p.maxVisible
+	endIdx := p.scrollOffset + p.visibleItems()
 	if endIdx > len(p.suggestions) {
 		endIdx = len(p.suggestions)
 	}
@@ -177,16 +238,20 @@ func (p *SuggestionPopup) View() string {
 		// Icon
 		icon := p.styles.TypeIcon.Render(typeIcon(s.Type))
 
-		// Text (truncate if needed)
-		text := s.Text
-		if len(text) > maxTextWidth {
-			text = text[:maxTextWidth-3] + "..."
+		base := p.styles.Item
+		if i == p.selectedIndex {
+			base = p.styles.SelectedItem
 		}
 
-		// Description
+		// Text (truncate if needed), with the typed characters highlighted
+		text := truncateString(s.Text, maxTextWidth)
+		width := cellWidth(text)
+		text = highlightMatch(s.Text, text, matchPositions(s.Text, p.match), base, p.styles.Match.Inherit(base))
+
+		// Description, in whatever room the text leaves
 		desc := ""
-		if s.Description != "" && s.Description != s.Type {
-			desc = p.styles.Description.Render(" " + s.Description)
+		if room := contentWidth - 2 - width; s.Description != "" && s.Description != s.Type && room > 1 {
+			desc = p.styles.Description.Render(truncateString(" "+s.Description, room))
 		}
 
 		// Build line
@@ -196,8 +261,8 @@ func (p *SuggestionPopup) View() string {
 		if i == p.selectedIndex {
 			// Pad to width for full highlight
 			padded := line
-			for len(padded) < p.width-4 {
-				padded += " "
+			if pad := contentWidth - lipgloss.Width(line); pad > 0 {
+				padded += strings.Repeat(" ", pad)
 			}
 			line = p.styles.SelectedItem.Render(padded)
 		} else {
@@ -208,7 +273,7 @@ func (p *SuggestionPopup) View() string {
 	}
 
 	// Add scroll indicator if needed
-	if len(p.suggestions) > p.maxVisible {
+	if len(p.suggestions) > p.visibleItems() {
 		scrollInfo := fmt.Sprintf(" %d/%d ", p.selectedIndex+1, len(p.suggestions))
 		lines = append(lines, p.styles.TypeIcon.Render(scrollInfo))
 	}
@@ -217,6 +282,40 @@ func (p *SuggestionPopup) View() string {
 	return p.styles.Box.Width(p.width).Render(content)
 }
 
+// highlightMatch renders shown, the possibly truncated text of a
+// suggestion, with the runes at positions in the full text styled as
+// matched. Every run is styled explicitly so the line's style carries on
+// past a highlight
+func highlightMatch(full, shown string, positions []int, base, matched lipgloss.Style) string {
+	if len(positions) == 0 {
+		return shown
+	}
+	runes := []rune(shown)
+	kept := len(runes)
+	if shown != full {
+		kept -= 3 // Ellipsis
+	}
+	isMatch := make(map[int]bool, len(positions))
+	for _, i := range positions {
+		isMatch[i] = true
+	}
+
+	var b strings.Builder
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && (isMatch[i] && i < kept) == (isMatch[start] && start < kept) {
+			continue
+		}
+		style := base
+		if isMatch[start] && start < kept {
+			style = matched
+		}
+		b.WriteString(style.Render(string(runes[start:i])))
+		start = i
+	}
+	return b.String()
+}
+
 // CompactView renders a single-line preview of the top suggestion
 func (p *SuggestionPopup) CompactView() string {
 	if !p.visible || len(p.suggestions) == 0 {
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestSuggestionPopup_FitsSize(t *testing.T) {
+	var suggestions []Suggestion
+	for _, text := range []string{"SigninLogs", "日本語のテーブル名がとても長いテーブル", "AuditLogs", "Heartbeat", "Perf"} {
+		suggestions = append(suggestions, Suggestion{Text: text, Type: "table", Description: "A table with a long description"})
+	}
+
+	tests := []struct {
+		name      string
+		width     int
+		maxHeight int
+		lines     int
+	}{
+		{"unlimited", 40, 0, 7},
+		{"short", 30, 5, 5},
+		{"too short for more than one", 20, 2, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewSuggestionPopup()
+			p.SetWidth(tt.width)
+			p.SetMaxHeight(tt.maxHeight)
+			p.SetSuggestions(suggestions)
+
+			out := p.View()
+			if got := lipgloss.Height(out); got != tt.lines {
+				t.Errorf("Expected %d lines, got %d:\n%s", tt.lines, got, out)
+			}
+			for _, line := range strings.Split(out, "\n") {
+				if got := lipgloss.Width(line); got != tt.width+2 {
+					t.Errorf("Expected lines %d wide, got %d: %q", tt.width+2, got, line)
+				}
+			}
+		})
+	}
+}
+
+func TestPopupWidth(t *testing.T) {
+	tests := []struct {
+		termWidth, maxWidth, expected int
+	}{
+		{120, 60, 60},
+		{50, 60, 46},
+		{120, 0, defaultPopupWidth},
+		{20, 60, minPopupWidth},
+	}
+
+	for _, tt := range tests {
+		if got := popupWidth(tt.termWidth, tt.maxWidth); got != tt.expected {
+			t.Errorf("popupWidth(%d, %d): expected %d, got %d", tt.termWidth, tt.maxWidth, tt.expected, got)
+		}
+	}
+}
+
+func TestHighlightMatch(t *testing.T) {
+	// Tests render without colors, so padding marks the matched runs
+	base := lipgloss.NewStyle()
+	matched := lipgloss.NewStyle().Padding(0, 1)
+
+	tests := []struct {
+		full, shown string
+		positions   []int
+		expected    string
+	}{
+		{"SigninLogs", "SigninLogs", []int{0, 1, 2}, " Sig ninLogs"},
+		{"SigninLogs", "SigninLogs", []int{0, 6, 7, 8, 9}, " S ignin Logs "},
+		{"SigninLogs", "Signi...", []int{0, 6, 7}, " S igni..."},
+		{"SigninLogs", "SigninLogs", nil, "SigninLogs"},
+	}
+
+	for _, tt := range tests {
+		if got := highlightMatch(tt.full, tt.shown, tt.positions, base, matched); got != tt.expected {
+			t.Errorf("highlightMatch(%q, %v): expected %q, got %q", tt.shown, tt.positions, tt.expected, got)
+		}
+	}
+}
@@ -2,10 +2,13 @@ package ui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
 // ResultsTable displays query results in a table format
@@ -18,11 +21,25 @@ type ResultsTable struct {
 	height      int
 	width       int
 	styles      *Styles
+	keys        *KeyMap
 	focused     bool
 	scrollX     int
 	maxColWidth int
+	autoFit     bool            // Size columns to content, capped at the table width
+	frozenCol   int             // Column pinned to the left edge, -1 if none
+	marks       []rowMark       // How each row differs from the diff baseline, nil if not diffed
+	hidden      map[string]bool // Names of columns hidden with the column picker
+	contentW    []int           // Widest cell of each column, measured once in SetData
+	colWidths   map[string]int  // Widths set for single columns by name, over the max width
 }
 
+// Column width limits for runtime adjustment
+const (
+	minColWidth    = 5
+	maxColWidthCap = 200
+	colWidthStep   = 5
+)
+
 // NewResultsTable creates a new results table
 func NewResultsTable() ResultsTable {
 	return ResultsTable{
@@ -34,20 +51,48 @@ func NewResultsTable() ResultsTable {
 		height:      20,
 		width:       120,
 		styles:      DefaultStyles(),
+		keys:        DefaultKeyMap(),
 		focused:     false,
 		scrollX:     0,
 		maxColWidth: 40,
+		frozenCol:   -1,
 	}
 }
 
 // SetData sets the table data
 func (t *ResultsTable) SetData(columns []string, columnTypes []string, rows [][]string) {
+	// Keep the frozen column only while the same column is still at its index
+	if t.frozenCol >= 0 && (t.frozenCol >= len(columns) || columns[t.frozenCol] != t.columns[t.frozenCol]) {
+		t.frozenCol = -1
+	}
 	t.columns = columns
 	t.columnTypes = columnTypes
 	t.rows = rows
+	t.contentW = measureColumns(columns, rows)
+	t.marks = nil
 	t.cursor = 0
 	t.offset = 0
 	t.scrollX = 0
+	// Keep hidden columns hidden across reruns, forgetting ones that are gone
+	for name := range t.hidden {
+		if !slices.Contains(columns, name) {
+			delete(t.hidden, name)
+		}
+	}
+	if len(t.ShownColumns()) == 0 {
+		t.hidden = nil
+	}
+	if t.isHidden(t.frozenCol) {
+		t.frozenCol = -1
+	}
+	if t.isHidden(t.scrollX) {
+		t.scrollColumns(1)
+	}
+}
+
+// SetRowMarks highlights rows that differ from the diff baseline
+func (t *ResultsTable) SetRowMarks(marks []rowMark) {
+	t.marks = marks
 }
 
 // Clear clears the table data
@@ -55,15 +100,25 @@ func (t *ResultsTable) Clear() {
 	t.columns = []string{}
 	t.columnTypes = []string{}
 	t.rows = [][]string{}
+	t.contentW = nil
+	t.marks = nil
 	t.cursor = 0
 	t.offset = 0
 	t.scrollX = 0
+	t.frozenCol = -1
+	t.hidden = nil
 }
 
-// SetSize sets the table dimensions
+// Smallest table size, leaving room for one row and a narrow column
+const (
+	minTableWidth  = minColWidth + 7
+	minTableHeight = 5
+)
+
+// SetSize sets the table dimensions, clamped to the smallest usable size
 func (t *ResultsTable) SetSize(width, height int) {
-	t.width = width
-	t.height = height
+	t.width = max(width, minTableWidth)
+	t.height = max(height, minTableHeight)
 }
 
 // Focus focuses the table
@@ -81,6 +136,170 @@ func (t ResultsTable) IsFocused() bool {
 	return t.focused
 }
 
+// SetKeyMap sets the keybindings used for table navigation
+func (t *ResultsTable) SetKeyMap(keys *KeyMap) {
+	t.keys = keys
+}
+
+// SetMaxColumnWidth sets the maximum width of a column before it is truncated
+func (t *ResultsTable) SetMaxColumnWidth(width int) {
+	if width < minColWidth {
+		width = minColWidth
+	}
+	if width > maxColWidthCap {
+		width = maxColWidthCap
+	}
+	t.maxColWidth = width
+}
+
+// SetColumnWidths sets the widths of single columns by name, which take
+// the place of the max column width for those columns
+func (t *ResultsTable) SetColumnWidths(widths map[string]int) {
+	t.colWidths = make(map[string]int, len(widths))
+	for name, width := range widths {
+		t.colWidths[name] = width
+	}
+}
+
+// ColumnWidths returns the widths set for single columns by name
+func (t ResultsTable) ColumnWidths() map[string]int {
+	return t.colWidths
+}
+
+// resizeColumn widens or narrows the current column alone, starting from
+// the width it is shown at
+func (t *ResultsTable) resizeColumn(delta int) {
+	col := t.scrollX
+	widths := t.calculateColumnWidths()
+	if col < 0 || col >= len(widths) {
+		return
+	}
+	if t.colWidths == nil {
+		t.colWidths = make(map[string]int)
+	}
+	t.colWidths[t.columns[col]] = max(minColWidth, min(widths[col]+delta, maxColWidthCap))
+}
+
+// MaxColumnWidth returns the current maximum column width
+func (t ResultsTable) MaxColumnWidth() int {
+	return t.maxColWidth
+}
+
+// ToggleAutoFit switches between the fixed max column width and sizing
+// columns to their content (capped at the table width)
+func (t *ResultsTable) ToggleAutoFit() {
+	t.autoFit = !t.autoFit
+}
+
+// ToggleFreeze pins the leftmost visible column so it stays in view while
+// scrolling horizontally, or unpins it if a column is already frozen
+func (t *ResultsTable) ToggleFreeze() {
+	if t.frozenCol >= 0 {
+		t.frozenCol = -1
+		return
+	}
+	if t.scrollX < len(t.columns) {
+		t.frozenCol = t.scrollX
+	}
+}
+
+// CurrentColumn returns the index of the leftmost scrolled column, which
+// column actions apply to
+func (t ResultsTable) CurrentColumn() int {
+	return t.scrollX
+}
+
+// FrozenColumn returns the index of the frozen column, or -1 if none
+func (t ResultsTable) FrozenColumn() int {
+	return t.frozenCol
+}
+
+// HideColumn hides the leftmost visible column, moving on to the next shown
+// one. Hiding the frozen column unfreezes it. The last shown column can't be
+// hidden.
+func (t *ResultsTable) HideColumn() {
+	if t.scrollX >= len(t.columns) || len(t.ShownColumns()) <= 1 {
+		return
+	}
+	if t.hidden == nil {
+		t.hidden = make(map[string]bool)
+	}
+	if t.scrollX == t.frozenCol {
+		t.frozenCol = -1
+	}
+	t.hidden[t.columns[t.scrollX]] = true
+	if !t.scrollColumns(1) {
+		t.scrollColumns(-1)
+	}
+}
+
+// ShowAllColumns shows every hidden column again
+func (t *ResultsTable) ShowAllColumns() {
+	t.hidden = nil
+}
+
+// HiddenCount returns the number of hidden columns
+func (t ResultsTable) HiddenCount() int {
+	count := 0
+	for _, name := range t.columns {
+		if t.hidden[name] {
+			count++
+		}
+	}
+	return count
+}
+
+// ShownColumns returns the names of the columns not hidden with the column
+// picker, in result order
+func (t ResultsTable) ShownColumns() []string {
+	shown := make([]string, 0, len(t.columns))
+	for _, name := range t.columns {
+		if !t.hidden[name] {
+			shown = append(shown, name)
+		}
+	}
+	return shown
+}
+
+// HiddenColumns returns the names of the columns hidden with the column
+// picker, in result order
+func (t ResultsTable) HiddenColumns() []string {
+	var hidden []string
+	for _, name := range t.columns {
+		if t.hidden[name] {
+			hidden = append(hidden, name)
+		}
+	}
+	return hidden
+}
+
+// isHidden reports whether the column at index col is hidden
+func (t ResultsTable) isHidden(col int) bool {
+	return col >= 0 && col < len(t.columns) && t.hidden[t.columns[col]]
+}
+
+// scrollColumns scrolls horizontally by delta shown columns, skipping hidden
+// ones, and reports whether it moved
+func (t *ResultsTable) scrollColumns(delta int) bool {
+	step := 1
+	if delta < 0 {
+		step, delta = -1, -delta
+	}
+	moved := false
+	for ; delta > 0; delta-- {
+		next := t.scrollX + step
+		for t.isHidden(next) {
+			next += step
+		}
+		if next < 0 || next >= len(t.columns) {
+			break
+		}
+		t.scrollX = next
+		moved = true
+	}
+	return moved
+}
+
 // RowCount returns the number of rows
 func (t ResultsTable) RowCount() int {
 	return len(t.rows)
@@ -94,36 +313,32 @@ func (t ResultsTable) Update(msg tea.Msg) (ResultsTable, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "up", "k":
+		switch {
+		case key.Matches(msg, t.keys.Up):
 			if t.cursor > 0 {
 				t.cursor--
 				if t.cursor < t.offset {
 					t.offset = t.cursor
 				}
 			}
-		case "down", "j":
+		case key.Matches(msg, t.keys.Down):
 			if t.cursor < len(t.rows)-1 {
 				t.cursor++
 				if t.cursor >= t.offset+t.visibleRows() {
 					t.offset = t.cursor - t.visibleRows() + 1
 				}
 			}
-		case "left", "h":
-			if t.scrollX > 0 {
-				t.scrollX--
-			}
-		case "right", "l":
-			if t.scrollX < len(t.columns)-1 {
-				t.scrollX++
-			}
-		case "pgup":
+		case key.Matches(msg, t.keys.Left):
+			t.scrollColumns(-1)
+		case key.Matches(msg, t.keys.Right):
+			t.scrollColumns(1)
+		case key.Matches(msg, t.keys.PageUp):
 			t.cursor -= t.visibleRows()
 			if t.cursor < 0 {
 				t.cursor = 0
 			}
 			t.offset = t.cursor
-		case "pgdown":
+		case key.Matches(msg, t.keys.PageDown):
 			t.cursor += t.visibleRows()
 			if t.cursor >= len(t.rows) {
 				t.cursor = len(t.rows) - 1
@@ -131,20 +346,90 @@ func (t ResultsTable) Update(msg tea.Msg) (ResultsTable, tea.Cmd) {
 			if t.cursor >= t.offset+t.visibleRows() {
 				t.offset = t.cursor - t.visibleRows() + 1
 			}
-		case "home", "g":
+		case key.Matches(msg, t.keys.Top):
 			t.cursor = 0
 			t.offset = 0
-		case "end", "G":
+		case key.Matches(msg, t.keys.Bottom):
 			t.cursor = len(t.rows) - 1
 			if t.cursor >= t.visibleRows() {
 				t.offset = t.cursor - t.visibleRows() + 1
 			}
+		case key.Matches(msg, t.keys.FreezeColumn):
+			t.ToggleFreeze()
+		case key.Matches(msg, t.keys.WidenColumns):
+			t.autoFit = false
+			t.SetMaxColumnWidth(t.maxColWidth + colWidthStep)
+		case key.Matches(msg, t.keys.NarrowColumns):
+			t.autoFit = false
+			t.SetMaxColumnWidth(t.maxColWidth - colWidthStep)
+		case key.Matches(msg, t.keys.WidenColumn):
+			t.resizeColumn(colWidthStep)
+		case key.Matches(msg, t.keys.NarrowColumn):
+			t.resizeColumn(-colWidthStep)
+		case key.Matches(msg, t.keys.AutoFitColumns):
+			t.ToggleAutoFit()
+		case key.Matches(msg, t.keys.HideColumn):
+			t.HideColumn()
+		case key.Matches(msg, t.keys.ShowColumns):
+			t.ShowAllColumns()
+		}
+
+	case tea.MouseMsg:
+		// Coordinates are expected relative to the top of the table
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			t.moveCursor(-1)
+		case tea.MouseButtonWheelDown:
+			t.moveCursor(1)
+		case tea.MouseButtonWheelLeft:
+			t.scrollColumns(-1)
+		case tea.MouseButtonWheelRight:
+			t.scrollColumns(1)
+		case tea.MouseButtonLeft:
+			if msg.Action == tea.MouseActionPress {
+				if row := t.RowAt(msg.Y); row >= 0 {
+					t.cursor = row
+				}
+			}
 		}
 	}
 
 	return t, nil
 }
 
+// moveCursor moves the cursor by delta rows, keeping it in bounds and in view
+func (t *ResultsTable) moveCursor(delta int) {
+	t.cursor += delta
+	if t.cursor >= len(t.rows) {
+		t.cursor = len(t.rows) - 1
+	}
+	if t.cursor < 0 {
+		t.cursor = 0
+	}
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+t.visibleRows() {
+		t.offset = t.cursor - t.visibleRows() + 1
+	}
+}
+
+// RowAt returns the index of the row rendered at line y (relative to the top
+// of the table), or -1 if that line isn't a data row
+func (t ResultsTable) RowAt(y int) int {
+	if y < tableHeaderLines {
+		return -1
+	}
+	idx := t.offset + y - tableHeaderLines
+	if idx >= len(t.rows) || idx >= t.offset+t.visibleRows() {
+		return -1
+	}
+	return idx
+}
+
+// tableHeaderLines is the number of lines rendered above the first data row
+const tableHeaderLines = 3
+
 func (t ResultsTable) visibleRows() int {
 	return t.height - 4 // Account for header and borders
 }
@@ -159,26 +444,28 @@ func (t ResultsTable) View() string {
 
 	// Calculate column widths
 	colWidths := t.calculateColumnWidths()
+	visibleCols := t.visibleColumns(colWidths)
 
 	// Header
-	headerCells := make([]string, 0)
-	for i := t.scrollX; i < len(t.columns) && i < t.scrollX+t.visibleColumns(colWidths); i++ {
-		cell := truncateString(t.columns[i], colWidths[i])
-		cell = padRight(cell, colWidths[i])
+	headerCells := make([]string, 0, len(visibleCols))
+	for _, i := range visibleCols {
+		cell := fitCell(t.columns[i], colWidths[i])
 		headerCells = append(headerCells, t.styles.Bold.Foreground(ColorSecondary).Render(cell))
 	}
-	header := strings.Join(headerCells, " | ")
+	header := t.joinCells(headerCells, visibleCols)
 
 	borderStyle := lipgloss.NewStyle().Foreground(ColorBorder)
 	if t.focused {
 		borderStyle = borderStyle.Foreground(ColorPrimary)
 	}
 
-	b.WriteString(borderStyle.Render("┌" + strings.Repeat("─", len(stripAnsi(header))+2) + "┐"))
+	// Border width comes from the column layout, never from styled output
+	rule := strings.Repeat("─", rowWidth(colWidths, visibleCols)+2)
+	b.WriteString(borderStyle.Render("┌" + rule + "┐"))
 	b.WriteString("\n")
 	b.WriteString(borderStyle.Render("│ ") + header + borderStyle.Render(" │"))
 	b.WriteString("\n")
-	b.WriteString(borderStyle.Render("├" + strings.Repeat("─", len(stripAnsi(header))+2) + "┤"))
+	b.WriteString(borderStyle.Render("├" + rule + "┤"))
 	b.WriteString("\n")
 
 	// Rows
@@ -189,15 +476,27 @@ func (t ResultsTable) View() string {
 
 	for i := t.offset; i < visibleEnd; i++ {
 		row := t.rows[i]
-		rowCells := make([]string, 0)
+		rowCells := make([]string, 0, len(visibleCols))
 
-		for j := t.scrollX; j < len(row) && j < t.scrollX+t.visibleColumns(colWidths); j++ {
-			cell := truncateString(row[j], colWidths[j])
-			cell = padRight(cell, colWidths[j])
-
-			// Style based on type and selection
-			if i == t.cursor && t.focused {
+		for _, j := range visibleCols {
+			value := ""
+			if j < len(row) {
+				value = row[j]
+			}
+			// Fit to the column's display width before styling so the
+			// escape codes added by the styles never affect the layout
+			cell := fitCell(value, colWidths[j])
+
+			// Style based on type and selection. The cell under the cursor
+			// in the current column stands out from the rest of the row
+			if i == t.cursor && t.focused && j == t.scrollX {
+				cell = t.styles.SelectedCell.Render(cell)
+			} else if i == t.cursor && t.focused {
 				cell = t.styles.Selected.Render(cell)
+			} else if style, ok := t.markStyle(i); ok {
+				cell = style.Render(cell)
+			} else if style, ok := t.typeStyle(j); ok && value != "" {
+				cell = style.Render(cell)
 			} else if i%2 == 0 {
 				cell = t.styles.TableRow.Render(cell)
 			} else {
@@ -206,72 +505,176 @@ func (t ResultsTable) View() string {
 			rowCells = append(rowCells, cell)
 		}
 
-		rowStr := strings.Join(rowCells, " | ")
+		rowStr := t.joinCells(rowCells, visibleCols)
 		b.WriteString(borderStyle.Render("│ ") + rowStr + borderStyle.Render(" │"))
 		b.WriteString("\n")
 	}
 
-	b.WriteString(borderStyle.Render("└" + strings.Repeat("─", len(stripAnsi(header))+2) + "┘"))
+	b.WriteString(borderStyle.Render("└" + rule + "┘"))
 	b.WriteString("\n")
 
 	// Footer with info
 	info := fmt.Sprintf("Row %d/%d | Column %d/%d",
 		t.cursor+1, len(t.rows),
 		t.scrollX+1, len(t.columns))
+	if t.frozenCol >= 0 && t.frozenCol < len(t.columns) {
+		info += fmt.Sprintf(" | Frozen: %s", t.columns[t.frozenCol])
+	}
+	if hidden := t.HiddenCount(); hidden > 0 {
+		info += fmt.Sprintf(" | Hidden: %d", hidden)
+	}
+	if t.autoFit {
+		info += " | Width: auto"
+	} else {
+		info += fmt.Sprintf(" | Width: %d", t.maxColWidth)
+	}
+	if width, ok := t.colWidths[t.columns[t.scrollX]]; ok {
+		info += fmt.Sprintf(" (%s: %d)", t.columns[t.scrollX], width)
+	}
 	b.WriteString(t.styles.Muted.Render(info))
 
 	return b.String()
 }
 
-func (t ResultsTable) calculateColumnWidths() []int {
-	if len(t.columns) == 0 {
-		return nil
+// typeStyle returns the style for cells of a column whose type is colored,
+// reporting false for strings and other untyped columns
+func (t ResultsTable) typeStyle(col int) (lipgloss.Style, bool) {
+	if col >= len(t.columnTypes) {
+		return lipgloss.Style{}, false
 	}
+	switch t.columnTypes[col] {
+	case "datetime", "timespan":
+		return t.styles.CellDatetime, true
+	case "int", "long", "real", "decimal":
+		return t.styles.CellNumber, true
+	case "bool":
+		return t.styles.CellBool, true
+	}
+	return lipgloss.Style{}, false
+}
 
-	widths := make([]int, len(t.columns))
-
-	// Start with column header widths
-	for i, col := range t.columns {
-		widths[i] = len(col)
+// markStyle returns the style for a row that differs from the diff baseline
+func (t ResultsTable) markStyle(row int) (lipgloss.Style, bool) {
+	if row >= len(t.marks) {
+		return lipgloss.Style{}, false
 	}
+	switch t.marks[row] {
+	case rowAdded:
+		return t.styles.DiffAdded, true
+	case rowRemoved:
+		return t.styles.DiffRemoved, true
+	case rowChanged:
+		return t.styles.DiffChanged, true
+	}
+	return lipgloss.Style{}, false
+}
 
-	// Check row widths
-	for _, row := range t.rows {
+// measureColumns returns the display width of the widest cell (or header)
+// of each column. It walks every row, so it runs once per result rather than
+// on every render.
+func measureColumns(columns []string, rows [][]string) []int {
+	widths := make([]int, len(columns))
+	for i, col := range columns {
+		widths[i] = cellWidth(col)
+	}
+	for _, row := range rows {
 		for i, cell := range row {
-			if i < len(widths) && len(cell) > widths[i] {
-				widths[i] = len(cell)
+			if i < len(widths) {
+				if w := cellWidth(cell); w > widths[i] {
+					widths[i] = w
+				}
 			}
 		}
 	}
+	return widths
+}
 
-	// Cap at max width
-	for i := range widths {
-		if widths[i] > t.maxColWidth {
-			widths[i] = t.maxColWidth
+// calculateColumnWidths caps the measured column widths at the max column
+// width, or the table width when auto-fitting
+func (t ResultsTable) calculateColumnWidths() []int {
+	if len(t.columns) == 0 {
+		return nil
+	}
+
+	limit := t.maxColWidth
+	if t.autoFit {
+		limit = t.width - 7 // Borders and separator
+		if limit < minColWidth {
+			limit = minColWidth
 		}
 	}
 
+	widths := make([]int, len(t.columns))
+	for i := range widths {
+		if i >= len(t.contentW) {
+			continue
+		}
+		widths[i] = min(t.contentW[i], limit)
+		// A column's own width may go past the limit, but not the table
+		if width, ok := t.colWidths[t.columns[i]]; ok {
+			widths[i] = min(t.contentW[i], width, max(t.width-7, minColWidth))
+		}
+	}
 	return widths
 }
 
-func (t ResultsTable) visibleColumns(colWidths []int) int {
+// visibleColumns returns the indices of the columns to render, starting with
+// the frozen column (if any) followed by the horizontally scrolled window
+func (t ResultsTable) visibleColumns(colWidths []int) []int {
 	available := t.width - 4 // Borders
-	count := 0
+	var cols []int
 	used := 0
 
+	if t.frozenCol >= 0 && t.frozenCol < len(colWidths) {
+		cols = append(cols, t.frozenCol)
+		used += colWidths[t.frozenCol] + 3
+	}
+
+	scrolled := 0
 	for i := t.scrollX; i < len(colWidths); i++ {
+		if i == t.frozenCol || t.isHidden(i) {
+			continue
+		}
 		needed := colWidths[i] + 3 // Column + separator
-		if used+needed > available {
+		// Always show at least one scrolled column
+		if used+needed > available && scrolled > 0 {
 			break
 		}
 		used += needed
-		count++
+		cols = append(cols, i)
+		scrolled++
 	}
 
-	if count == 0 {
-		count = 1
+	return cols
+}
+
+// rowWidth returns the display width of a rendered row for the given columns,
+// including the separators between them
+func rowWidth(colWidths []int, cols []int) int {
+	width := 0
+	for i, c := range cols {
+		if i > 0 {
+			width += 3 // Separator
+		}
+		width += colWidths[c]
 	}
-	return count
+	return width
+}
+
+// joinCells joins rendered cells, using a heavier separator after the frozen column
+func (t ResultsTable) joinCells(cells []string, cols []int) string {
+	var b strings.Builder
+	for i, cell := range cells {
+		if i > 0 {
+			if cols[i-1] == t.frozenCol {
+				b.WriteString(" ║ ")
+			} else {
+				b.WriteString(" | ")
+			}
+		}
+		b.WriteString(cell)
+	}
+	return b.String()
 }
 
 // GetSelectedRow returns the currently selected row
@@ -287,6 +690,11 @@ func (t ResultsTable) GetColumns() []string {
 	return t.columns
 }
 
+// GetColumnTypes returns the column types
+func (t ResultsTable) GetColumnTypes() []string {
+	return t.columnTypes
+}
+
 // GetSelectedRowIndex returns the current cursor position
 func (t ResultsTable) GetSelectedRowIndex() int {
 	return t.cursor
@@ -294,41 +702,29 @@ func (t ResultsTable) GetSelectedRowIndex() int {
 
 // Helper functions
 
+// cellReplacer flattens characters that would break a single-line cell
+var cellReplacer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+// cellWidth returns the display width of a value once rendered in a cell
+func cellWidth(s string) int {
+	return runewidth.StringWidth(cellReplacer.Replace(s))
+}
+
+// fitCell flattens, truncates and pads a value to exactly width display columns
+func fitCell(s string, width int) string {
+	return padRight(truncateString(cellReplacer.Replace(s), width), width)
+}
+
 func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if runewidth.StringWidth(s) <= maxLen {
 		return s
 	}
 	if maxLen <= 3 {
-		return s[:maxLen]
+		return runewidth.Truncate(s, maxLen, "")
 	}
-	return s[:maxLen-3] + "..."
+	return runewidth.Truncate(s, maxLen, "...")
 }
 
 func padRight(s string, length int) string {
-	if len(s) >= length {
-		return s
-	}
-	return s + strings.Repeat(" ", length-len(s))
-}
-
-// stripAnsi removes ANSI escape codes from a string
-func stripAnsi(s string) string {
-	var result strings.Builder
-	inEscape := false
-
-	for i := 0; i < len(s); i++ {
-		if s[i] == '\x1b' {
-			inEscape = true
-			continue
-		}
-		if inEscape {
-			if (s[i] >= 'a' && s[i] <= 'z') || (s[i] >= 'A' && s[i] <= 'Z') {
-				inEscape = false
-			}
-			continue
-		}
-		result.WriteByte(s[i])
-	}
-
-	return result.String()
+	return runewidth.FillRight(s, length)
 }
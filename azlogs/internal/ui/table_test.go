@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func newTestTable() ResultsTable {
+	table := NewResultsTable()
+	table.SetSize(120, 20)
+	table.SetData(
+		[]string{"TimeGenerated", "Message", "Count"},
+		[]string{"datetime", "string", "long"},
+		[][]string{
+			{"2024-01-01 00:00:00", "plain message", "1"},
+			{"2024-01-01 00:00:01", "tab\tand\nnewline", "22"},
+			{"2024-01-01 00:00:02", "héllo wörld 日本語", "333"},
+		},
+	)
+	return table
+}
+
+func TestResultsTable_SelectedRowBorderAlignment(t *testing.T) {
+	table := newTestTable()
+	table.Focus()
+
+	for cursor := 0; cursor < table.RowCount(); cursor++ {
+		table.cursor = cursor
+		lines := strings.Split(table.View(), "\n")
+
+		// Every line but the trailing info line belongs to the bordered table
+		tableLines := lines[:len(lines)-1]
+		want := lipgloss.Width(tableLines[0])
+		for i, line := range tableLines {
+			if got := lipgloss.Width(line); got != want {
+				t.Errorf("Cursor %d: line %d has width %d, expected %d: %q", cursor, i, got, want, line)
+			}
+		}
+	}
+}
+
+func TestResultsTable_FrozenColumnAlignment(t *testing.T) {
+	table := newTestTable()
+	table.Focus()
+	table.ToggleFreeze()
+	table.scrollX = 1
+
+	lines := strings.Split(table.View(), "\n")
+	tableLines := lines[:len(lines)-1]
+	want := lipgloss.Width(tableLines[0])
+	for i, line := range tableLines {
+		if got := lipgloss.Width(line); got != want {
+			t.Errorf("Line %d has width %d, expected %d: %q", i, got, want, line)
+		}
+	}
+}
+
+func TestResultsTable_FrozenColumnAcrossSetData(t *testing.T) {
+	tests := []struct {
+		name     string
+		columns  []string
+		expected int
+	}{
+		{"same columns", []string{"TimeGenerated", "Message", "Count"}, 1},
+		{"fewer columns", []string{"TimeGenerated"}, -1},
+		{"different column at index", []string{"TimeGenerated", "Level", "Count"}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTestTable()
+			table.scrollX = 1
+			table.ToggleFreeze()
+
+			table.SetData(tt.columns, make([]string, len(tt.columns)), nil)
+			if got := table.FrozenColumn(); got != tt.expected {
+				t.Errorf("Expected frozen column %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestResultsTable_HideColumn(t *testing.T) {
+	table := newTestTable()
+	table.scrollX = 1
+	table.HideColumn()
+
+	if got := strings.Join(table.ShownColumns(), ","); got != "TimeGenerated,Count" {
+		t.Errorf("Expected Message hidden, got shown %q", got)
+	}
+	if table.scrollX != 2 {
+		t.Errorf("Expected scroll to move to the next shown column, got %d", table.scrollX)
+	}
+	table.scrollColumns(-1)
+	if table.scrollX != 0 {
+		t.Errorf("Expected scrolling left to skip the hidden column, got %d", table.scrollX)
+	}
+
+	table.HideColumn()
+	table.HideColumn()
+	if got := table.ShownColumns(); len(got) != 1 {
+		t.Errorf("Expected the last shown column to stay visible, got %q", got)
+	}
+
+	// Hidden columns survive a rerun with the same columns
+	table.SetData(table.columns, table.columnTypes, table.rows)
+	if table.HiddenCount() != 2 || table.isHidden(table.scrollX) {
+		t.Errorf("Expected 2 hidden columns and a shown column in view, got %d hidden at column %d", table.HiddenCount(), table.scrollX)
+	}
+
+	table.ShowAllColumns()
+	if table.HiddenCount() != 0 {
+		t.Errorf("Expected all columns shown, got %d hidden", table.HiddenCount())
+	}
+}
+
+func TestResultsTable_HideFrozenColumn(t *testing.T) {
+	table := newTestTable()
+	table.ToggleFreeze()
+	table.HideColumn()
+
+	if table.FrozenColumn() != -1 {
+		t.Errorf("Expected hiding the frozen column to unfreeze it, got frozen column %d", table.FrozenColumn())
+	}
+	if !table.isHidden(0) || table.isHidden(table.scrollX) {
+		t.Errorf("Expected TimeGenerated hidden and a shown column in view, got column %d", table.scrollX)
+	}
+	if strings.Contains(table.View(), "TimeGenerated") {
+		t.Error("Expected the hidden column not to be rendered")
+	}
+
+	// A column frozen further left stays frozen when another one is hidden
+	table.ShowAllColumns()
+	table.scrollX = 0
+	table.ToggleFreeze()
+	table.scrollX = 1
+	table.HideColumn()
+	if table.FrozenColumn() != 0 {
+		t.Errorf("Expected TimeGenerated to stay frozen, got frozen column %d", table.FrozenColumn())
+	}
+}
+
+func TestResultsTable_TypeStyle(t *testing.T) {
+	table := newTestTable()
+
+	for col, expected := range []bool{true, false, true} {
+		if _, ok := table.typeStyle(col); ok != expected {
+			t.Errorf("Column %d (%s): expected colored %v, got %v", col, table.columnTypes[col], expected, ok)
+		}
+	}
+	if _, ok := table.typeStyle(10); ok {
+		t.Error("Expected no style for a column without a type")
+	}
+}
+
+func TestFitCell(t *testing.T) {
+	tests := []struct {
+		input string
+		width int
+	}{
+		{"short", 10},
+		{"exactly ten", 11},
+		{"this value is far too long", 10},
+		{"日本語テキスト", 9},
+		{"multi\nline", 12},
+		{"", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := fitCell(tt.input, tt.width)
+			if w := lipgloss.Width(got); w != tt.width {
+				t.Errorf("fitCell(%q, %d) width = %d, want %d", tt.input, tt.width, w, tt.width)
+			}
+			if strings.ContainsAny(got, "\n\t") {
+				t.Errorf("fitCell(%q, %d) = %q, contains layout-breaking characters", tt.input, tt.width, got)
+			}
+		})
+	}
+}
+
+// BenchmarkResultsTable_View renders a page of a 50k-row result, as on every
+// keypress while scrolling
+func BenchmarkResultsTable_View(b *testing.B) {
+	columns := []string{"TimeGenerated", "Computer", "Message", "Count", "Level"}
+	types := []string{"datetime", "string", "string", "long", "string"}
+	rows := make([][]string, 50000)
+	for i := range rows {
+		rows[i] = []string{
+			"2024-01-01 00:00:00",
+			fmt.Sprintf("host-%d", i%200),
+			strings.Repeat("message text ", i%10+1),
+			fmt.Sprint(i),
+			"Information",
+		}
+	}
+
+	table := NewResultsTable()
+	table.SetSize(160, 40)
+	table.SetData(columns, types, rows)
+	table.Focus()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		table.moveCursor(1)
+		_ = table.View()
+	}
+}
+
+func TestResultsTable_ResizeColumn(t *testing.T) {
+	table := newTestTable()
+	table.Focus()
+	table.SetMaxColumnWidth(5)
+	table.scrollX = 1
+
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(">")})
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(">")})
+	widths := table.calculateColumnWidths()
+	if widths[1] != 15 || widths[0] != 5 {
+		t.Errorf("Expected only Message widened to 15, got %v", widths)
+	}
+
+	// A column is never wider than its content
+	table.SetColumnWidths(map[string]int{"Count": 100})
+	if got := table.calculateColumnWidths()[2]; got != 5 {
+		t.Errorf("Expected Count at its content width 5, got %d", got)
+	}
+}
@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// timeRangePresets are the relative time ranges offered by the selector.
+// The empty range leaves time filtering to the query itself.
+var timeRangePresets = []string{"", "15m", "1h", "4h", "24h", "7d", "30d"}
+
+// parseTimeRange parses a relative time range such as "15m", "24h" or "7d"
+func parseTimeRange(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	var d time.Duration
+	var err error
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		var days float64
+		days, err = strconv.ParseFloat(n, 64)
+		d = time.Duration(days * float64(24*time.Hour))
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid time range %q (use e.g. 30m, 12h or 3d)", s)
+	}
+	return d, nil
+}
+
+// timeSpanFor returns the server-side timespan for a relative range ending
+// at now, or nil when the range is empty
+func timeSpanFor(timeRange string, now time.Time) *azure.TimeSpan {
+	d, err := parseTimeRange(timeRange)
+	if err != nil || d == 0 {
+		return nil
+	}
+	return &azure.TimeSpan{Start: now.Add(-d), End: now}
+}
+
+// describeTimeRange returns a label for a relative range
+func describeTimeRange(timeRange string) string {
+	if timeRange == "" {
+		return "No time range (use query filters)"
+	}
+	return "Last " + timeRange
+}
+
+// activeTimeRange returns the time range applied to queries. Data Explorer
+// has no query-wide time range, so none applies against a cluster.
+func (m Model) activeTimeRange() string {
+	if m.config.Cluster != "" {
+		return ""
+	}
+	return m.config.TimeRange
+}
+
+// openTimeRangeView shows the time range selector with the active range selected
+func (m *Model) openTimeRangeView() {
+	if m.config.Cluster != "" {
+		m.lastError = "Time ranges don't apply to Data Explorer queries: filter on a datetime column in the query instead"
+		return
+	}
+	m.timeRangeIndex = len(timeRangePresets) // Custom
+	for i, preset := range timeRangePresets {
+		if preset == m.config.TimeRange {
+			m.timeRangeIndex = i
+		}
+	}
+	m.editingTimeRange = false
+	m.currentView = ViewTimeRange
+}
+
+// setTimeRange makes the range active and remembers it in the config
+func (m *Model) setTimeRange(timeRange string) {
+	m.config.TimeRange = timeRange
+	m.config.Save()
+	m.currentView = ViewQuery
+	m.editor.Focus()
+	m.table.Blur()
+}
+
+func (m Model) updateTimeRangeView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Handle custom range input
+	if m.editingTimeRange {
+		if key.Matches(msg, m.keys.Select) {
+			value := strings.TrimSpace(m.timeRangeInput.Value())
+			if _, err := parseTimeRange(value); err != nil {
+				m.lastError = err.Error()
+				return m, nil
+			}
+			m.lastError = ""
+			m.editingTimeRange = false
+			m.setTimeRange(value)
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.timeRangeInput, cmd = m.timeRangeInput.Update(msg)
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Select):
+		if m.timeRangeIndex < len(timeRangePresets) {
+			m.setTimeRange(timeRangePresets[m.timeRangeIndex])
+			return m, nil
+		}
+		m.editingTimeRange = true
+		m.timeRangeInput.SetValue(m.config.TimeRange)
+		m.timeRangeInput.Focus()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Up):
+		if m.timeRangeIndex > 0 {
+			m.timeRangeIndex--
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		if m.timeRangeIndex < len(timeRangePresets) {
+			m.timeRangeIndex++
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m Model) renderTimeRangeView() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.Header.Render("Time Range"))
+	b.WriteString("\n\n")
+
+	if m.editingTimeRange {
+		b.WriteString("Custom range (e.g. 30m, 12h, 3d): ")
+		b.WriteString(m.timeRangeInput.View())
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Muted.Render(m.promptHint("apply")))
+		return b.String()
+	}
+
+	labels := make([]string, 0, len(timeRangePresets)+1)
+	for _, preset := range timeRangePresets {
+		labels = append(labels, describeTimeRange(preset))
+	}
+	labels = append(labels, "Custom...")
+
+	for i, label := range labels {
+		prefix := "  "
+		style := m.styles.Muted
+		if i == m.timeRangeIndex {
+			prefix = "▶ "
+			style = m.styles.Bold
+		}
+		b.WriteString(style.Render(prefix + label))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.Muted.Render("The range is applied server-side in addition to any time filters in the query."))
+
+	return b.String()
+}
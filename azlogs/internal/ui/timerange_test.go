@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"", 0, false},
+		{"  ", 0, false},
+		{"30m", 30 * time.Minute, false},
+		{"12h", 12 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"3d", 72 * time.Hour, false},
+		{"1.5d", 36 * time.Hour, false},
+		{" 7d ", 7 * 24 * time.Hour, false},
+		{"0h", 0, true},
+		{"-1h", 0, true},
+		{"-2d", 0, true},
+		{"d", 0, true},
+		{"3w", 0, true},
+		{"abc", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTimeRange(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTimeRange(%q): expected error %v, got %v", tt.input, tt.wantErr, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("parseTimeRange(%q): expected %v, got %v", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestTimeSpanFor(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		timeRange string
+		start     time.Time
+		wantNil   bool
+	}{
+		{"", time.Time{}, true},
+		{"invalid", time.Time{}, true},
+		{"1h", now.Add(-time.Hour), false},
+		{"7d", now.Add(-7 * 24 * time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		got := timeSpanFor(tt.timeRange, now)
+		if tt.wantNil {
+			if got != nil {
+				t.Errorf("timeSpanFor(%q): expected nil, got %+v", tt.timeRange, got)
+			}
+			continue
+		}
+		if got == nil {
+			t.Errorf("timeSpanFor(%q): expected a timespan, got nil", tt.timeRange)
+			continue
+		}
+		if !got.Start.Equal(tt.start) || !got.End.Equal(now) {
+			t.Errorf("timeSpanFor(%q): expected %v to %v, got %v to %v", tt.timeRange, tt.start, now, got.Start, got.End)
+		}
+	}
+}
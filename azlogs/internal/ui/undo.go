@@ -0,0 +1,51 @@
+package ui
+
+// maxUndoSteps caps how many edits the query editor can undo
+const maxUndoSteps = 100
+
+// editSnapshot is the editor text and cursor position before an edit
+type editSnapshot struct {
+	value    string
+	row, col int
+}
+
+// undoHistory holds the undo and redo stacks of the query editor
+type undoHistory struct {
+	undo []editSnapshot
+	redo []editSnapshot
+}
+
+// record saves the state before an edit, dropping the oldest step once the
+// stack is full. A new edit makes the undone steps unreachable.
+func (h *undoHistory) record(s editSnapshot) {
+	if n := len(h.undo); n > 0 && h.undo[n-1].value == s.value {
+		return
+	}
+	h.undo = append(h.undo, s)
+	if len(h.undo) > maxUndoSteps {
+		h.undo = h.undo[len(h.undo)-maxUndoSteps:]
+	}
+	h.redo = nil
+}
+
+// undoTo returns the state to restore when undoing from current
+func (h *undoHistory) undoTo(current editSnapshot) (editSnapshot, bool) {
+	if len(h.undo) == 0 {
+		return editSnapshot{}, false
+	}
+	s := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, current)
+	return s, true
+}
+
+// redoTo returns the state to restore when redoing from current
+func (h *undoHistory) redoTo(current editSnapshot) (editSnapshot, bool) {
+	if len(h.redo) == 0 {
+		return editSnapshot{}, false
+	}
+	s := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, current)
+	return s, true
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestUndoHistory_UndoRedo(t *testing.T) {
+	h := &undoHistory{}
+	h.record(editSnapshot{value: "a"})
+	h.record(editSnapshot{value: "ab"})
+
+	s, ok := h.undoTo(editSnapshot{value: "abc"})
+	if !ok || s.value != "ab" {
+		t.Errorf("Expected undo to \"ab\", got %q (%v)", s.value, ok)
+	}
+	s, ok = h.undoTo(s)
+	if !ok || s.value != "a" {
+		t.Errorf("Expected undo to \"a\", got %q (%v)", s.value, ok)
+	}
+	if _, ok := h.undoTo(s); ok {
+		t.Error("Expected nothing left to undo")
+	}
+
+	s, ok = h.redoTo(s)
+	if !ok || s.value != "ab" {
+		t.Errorf("Expected redo to \"ab\", got %q (%v)", s.value, ok)
+	}
+	s, ok = h.redoTo(s)
+	if !ok || s.value != "abc" {
+		t.Errorf("Expected redo to \"abc\", got %q (%v)", s.value, ok)
+	}
+}
+
+func TestUndoHistory_RecordClearsRedo(t *testing.T) {
+	h := &undoHistory{}
+	h.record(editSnapshot{value: "a"})
+	h.undoTo(editSnapshot{value: "b"})
+	h.record(editSnapshot{value: "a"})
+
+	if _, ok := h.redoTo(editSnapshot{value: "c"}); ok {
+		t.Error("Expected a new edit to clear the redo stack")
+	}
+}
+
+func TestUndoHistory_Cap(t *testing.T) {
+	h := &undoHistory{}
+	for i := 0; i < maxUndoSteps+10; i++ {
+		h.record(editSnapshot{value: fmt.Sprint(i)})
+	}
+	if len(h.undo) != maxUndoSteps {
+		t.Errorf("Expected %d undo steps, got %d", maxUndoSteps, len(h.undo))
+	}
+	if h.undo[0].value != "10" {
+		t.Errorf("Expected the oldest steps dropped, got %q first", h.undo[0].value)
+	}
+}
+
+func TestQueryEditor_Undo(t *testing.T) {
+	e := NewQueryEditor()
+	e.Focus()
+	e.SetValue("T")
+	for _, r := range "ab" {
+		e, _ = e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if e.Value() != "Tab" {
+		t.Fatalf("Expected %q, got %q", "Tab", e.Value())
+	}
+
+	// Typed characters are undone together
+	e, _ = e.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	if e.Value() != "T" {
+		t.Errorf("Expected %q after undo, got %q", "T", e.Value())
+	}
+	e, _ = e.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	if e.Value() != "" {
+		t.Errorf("Expected %q after second undo, got %q", "", e.Value())
+	}
+	e, _ = e.Update(tea.KeyMsg{Type: tea.KeyCtrlY})
+	if e.Value() != "T" {
+		t.Errorf("Expected %q after redo, got %q", "T", e.Value())
+	}
+}
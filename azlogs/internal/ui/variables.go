@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// variablePattern matches a {{name}} reference to a query variable
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// expandVariables replaces each {{name}} in a query with the variable's
+// values, as a comma-separated list of KQL literals. A variable holding one
+// value can be compared with ==, and any variable can be used in an in()
+// list. Referencing an undefined variable is an error.
+func expandVariables(query string, variables map[string]string) (string, error) {
+	var missing []string
+	expanded := variablePattern.ReplaceAllStringFunc(query, func(ref string) string {
+		name := variablePattern.FindStringSubmatch(ref)[1]
+		value, ok := variables[name]
+		if !ok {
+			missing = append(missing, ref)
+			return ref
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("unknown variable %s", missing[0])
+	}
+	return expanded, nil
+}
+
+// defaultVariableName suggests a variable name for a column
+func defaultVariableName(column string) string {
+	if identifierPattern.MatchString(column) {
+		return column
+	}
+	return "values"
+}
+
+// startVariable prompts for the name of a variable to hold the current
+// column's distinct values
+func (m *Model) startVariable() {
+	col := m.table.CurrentColumn()
+	if col < 0 || col >= len(m.resultColumns) {
+		m.lastError = "No column to save as a variable"
+		return
+	}
+	m.namingVariable = true
+	m.variableInput.SetValue(defaultVariableName(m.resultColumns[col].Name))
+	m.variableInput.CursorEnd()
+	m.variableInput.Focus()
+}
+
+// updateVariablePrompt handles keys while the variable name is being typed
+func (m Model) updateVariablePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !key.Matches(msg, m.keys.Select) {
+		var cmd tea.Cmd
+		m.variableInput, cmd = m.variableInput.Update(msg)
+		return m, cmd
+	}
+
+	name := strings.TrimSpace(m.variableInput.Value())
+	if !identifierPattern.MatchString(name) {
+		m.lastError = fmt.Sprintf("Invalid variable name %q: use letters, digits and underscores", name)
+		return m, nil
+	}
+	m.namingVariable = false
+	m.variableInput.Blur()
+
+	col := m.table.CurrentColumn()
+	if col < 0 || col >= len(m.resultColumns) {
+		return m, nil
+	}
+	column := m.resultColumns[col]
+	literals := distinctLiterals(m.currentRows(), col, column.Type)
+	if len(literals) == 0 {
+		m.lastError = fmt.Sprintf("Column %s has no comparable values", column.Name)
+		return m, nil
+	}
+	m.notice = fmt.Sprintf("Saved %d values of %s as {{%s}}", len(literals), column.Name, name)
+	if len(literals) > maxInValues {
+		m.notice = fmt.Sprintf("Saved the first %d of %d values of %s as {{%s}}", maxInValues, len(literals), column.Name, name)
+		literals = literals[:maxInValues]
+	}
+
+	if m.variables == nil {
+		m.variables = make(map[string]string)
+	}
+	m.variables[name] = strings.Join(literals, ", ")
+	m.lastError = ""
+	return m, nil
+}
+
+// renderVariablePrompt renders the variable name prompt shown over results
+func (m Model) renderVariablePrompt() string {
+	return "Save column as variable {{" + m.variableInput.View() + "}} " +
+		m.styles.Muted.Render(m.promptHint("save"))
+}
+
+// variableNames returns the names of the defined variables, sorted
+func (m Model) variableNames() []string {
+	names := make([]string, 0, len(m.variables))
+	for name := range m.variables {
+		names = append(names, "{{"+name+"}}")
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestExpandVariables(t *testing.T) {
+	variables := map[string]string{"ids": `"a", "b"`, "user": `"alice"`}
+
+	tests := []struct {
+		query    string
+		expected string
+		wantErr  bool
+	}{
+		{"T | where Id in ({{ids}})", `T | where Id in ("a", "b")`, false},
+		{"T | where User == {{ user }} and Id in ({{ids}})", `T | where User == "alice" and Id in ("a", "b")`, false},
+		{"T | take 10", "T | take 10", false},
+		{"T | where Id in ({{missing}})", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := expandVariables(tt.query, variables)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("expandVariables(%q): expected %q (error %v), got %q (%v)", tt.query, tt.expected, tt.wantErr, got, err)
+		}
+	}
+}
+
+func TestModel_SaveVariable(t *testing.T) {
+	azure.SetConfigDir(t.TempDir())
+	defer azure.SetConfigDir("")
+
+	m := NewModel("", azure.AuthDefault, azure.NewConfig())
+	m.processResults(&azure.QueryResult{Tables: []azure.Table{{
+		Columns: []azure.Column{{Name: "Resource Id", Type: "string"}},
+		Rows:    [][]interface{}{{"/sub/a"}, {"/sub/b"}, {"/sub/a"}, {nil}},
+	}}})
+
+	model, _ := m.updateResultsView(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("V")})
+	m = model.(Model)
+	if !m.namingVariable || m.variableInput.Value() != "values" {
+		t.Fatalf("Expected a name prompt defaulting to 'values', got %q", m.variableInput.Value())
+	}
+
+	m.variableInput.SetValue("ids")
+	model, _ = m.updateResultsView(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(Model)
+	if m.namingVariable || m.variables["ids"] != `"/sub/a", "/sub/b"` {
+		t.Errorf("Expected the distinct values saved as ids, got %q", m.variables["ids"])
+	}
+}
@@ -1,38 +1,116 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/codyseavey/tools/azlogs/internal/azure"
+	"github.com/codyseavey/tools/azlogs/internal/buildinfo"
 	"github.com/codyseavey/tools/azlogs/internal/ui"
 )
 
-const version = "1.0.0"
+// version is the release reported in the UI and logs
+var version = buildinfo.Get().Version
+
+// paramFlags collects repeated --param flags
+type paramFlags []string
+
+func (p *paramFlags) String() string {
+	return strings.Join(*p, ", ")
+}
+
+func (p *paramFlags) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
 
 func main() {
+	os.Exit(run())
+}
+
+// run parses the flags and runs the selected mode, returning the exit code.
+// Exiting only once run returns lets its deferred calls, such as closing the
+// --verbose log, run first
+func run() int {
 	// Command line flags
 	workspaceID := flag.String("workspace", "", "Azure Log Analytics Workspace ID")
 	workspaceShort := flag.String("w", "", "Azure Log Analytics Workspace ID (shorthand)")
-	authMethod := flag.String("auth", "default", "Authentication method: default, cli, browser, managed-identity")
+	cluster := flag.String("cluster", "", "Query an Azure Data Explorer cluster (URL or name like mycluster.westeurope) instead of a workspace")
+	database := flag.String("database", "", "Azure Data Explorer database to query with --cluster")
+	authMethod := flag.String("auth", "default", "Authentication method: default, cli, browser, managed-identity, env")
+	cloudName := flag.String("cloud", "public", "Azure cloud: public, usgov or china")
 	query := flag.String("query", "", "Execute a query and exit (non-interactive mode)")
 	queryShort := flag.String("q", "", "Execute a query and exit (shorthand)")
+	format := flag.String("format", formatTSV, "Output format for -q and --batch results: tsv, json, jsonl or markdown")
+	markdownWidth := flag.Int("markdown-width", 0, "Truncate cells in --format markdown output to this many characters (0 for no limit)")
+	outputTmpl := flag.String("template", "", "Write -q and --batch results with a Go text/template run once per row, e.g. '{{.TimeGenerated}} {{.Message}}'")
+	templateWhole := flag.Bool("template-result", false, "Run --template once with the whole result (.Columns and .Rows) instead of once per row")
+	batch := flag.String("batch", "", "Run the queries in a file (separated by ;; lines or blank lines) and exit")
+	batchOut := flag.String("batch-out", "", "Write each --batch result to its own file in this directory")
+	failFast := flag.Bool("fail-fast", false, "Stop --batch at the first failing query")
+	saveResult := flag.String("save-result", "", "With -q, also save the result as a snapshot file to reload later with --load-result")
+	report := flag.String("report", "", "With -q, also write the query and result as a report: Markdown for .md files, HTML otherwise")
+	loadResult := flag.String("load-result", "", "Open a result snapshot saved with --save-result or the S key, without re-running its query")
+	repl := flag.Bool("repl", false, "Read queries from stdin line by line and print results as text, without the full-screen UI")
+	waitForResults := flag.Duration("wait-for-results", 0, "With -q, retry while the query returns no rows for up to this long (e.g. 5m)")
+	waitInterval := flag.Duration("wait-interval", 15*time.Second, "Time between --wait-for-results retries")
+	failOnEmpty := flag.Bool("fail-on-empty", false, "With -q, exit with status 2 when the query returns no rows")
+	checkSetup := flag.Bool("check", false, "Check sign-in, workspace access and Azure OpenAI, then exit (also: azlogs doctor)")
+	var params paramFlags
+	flag.Var(&params, "param", "Query parameter as name=value or name:type=value (repeatable)")
+	theme := flag.String("theme", "", "Color theme: dark, light, high-contrast (default: detect from terminal)")
+	profile := flag.String("profile", "", "Use separate config, history and templates stored under profiles/<name>")
+	configDir := flag.String("config-dir", "", "Directory for config, history and templates (default: $XDG_CONFIG_HOME/azlogs or ~/.config/azlogs)")
+	exportHistory := flag.String("export-history", "", "Write query history as JSON to a file (- for stdout) and exit")
+	clearHistory := flag.Bool("clear-history", false, "Clear query history (asks for confirmation) and exit")
+	maxRows := flag.Int("max-rows", -1, "Maximum rows kept per result, 0 for no limit (default: max_result_rows from config)")
+	noCache := flag.Bool("no-cache", false, "Always run queries instead of serving recent results from cache")
+	noStartupQuery := flag.Bool("no-startup-query", false, "Don't run the startup_query from config when the interactive UI connects")
+	noTableScan := flag.Bool("no-table-scan", false, "List tables for autocomplete only from the Usage table, never by searching table data")
+	timezone := flag.String("timezone", "", "Show datetimes in this zone: UTC, local or an IANA name like Europe/Berlin (default: timezone from config, or UTC)")
+	verbose := flag.Bool("verbose", false, "Log diagnostics (auth, endpoints, queries, timings, retries) to stderr, or to --log-file")
+	verboseShort := flag.Bool("v", false, "Log diagnostics (shorthand)")
+	logFile := flag.String("log-file", "", "File for --verbose output (default: stderr, or azlogs.log in the config directory for the interactive UI)")
+	noColor := flag.Bool("no-color", false, "Disable colors (also enabled by the NO_COLOR environment variable)")
 	showVersion := flag.Bool("version", false, "Show version information")
 	showHelp := flag.Bool("help", false, "Show help information")
 
 	flag.Parse()
 
 	if *showVersion {
-		fmt.Printf("azlogs version %s\n", version)
-		os.Exit(0)
+		fmt.Printf("azlogs version %s\n", buildinfo.Get())
+		return 0
 	}
 
 	if *showHelp {
 		printHelp()
-		os.Exit(0)
+		return 0
+	}
+
+	if *configDir != "" {
+		azure.SetConfigDir(*configDir)
+	}
+	if err := azure.SetProfile(*profile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// History maintenance
+	if *exportHistory != "" || *clearHistory {
+		if err := manageHistory(*exportHistory, *clearHistory); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
 	}
 
 	// Resolve workspace ID
@@ -50,21 +128,199 @@ func main() {
 		q = *queryShort
 	}
 
+	// Verbose diagnostics go to a file in the interactive UI, where the
+	// full-screen display owns stderr
+	if *verbose || *verboseShort {
+		path := *logFile
+		checking := *checkSetup || flag.Arg(0) == "doctor"
+		interactive := *batch == "" && q == "" && !*repl && !checking
+		if path == "" && interactive {
+			path = defaultLogFile()
+		}
+		closeLog, err := setupVerboseLog(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer closeLog()
+		if interactive {
+			fmt.Fprintf(os.Stderr, "Logging diagnostics to %s\n", path)
+		}
+	}
+
 	// Resolve auth method
 	auth := parseAuthMethod(*authMethod)
 
+	// Load config and apply session overrides
+	config := azure.NewConfig()
+	if err := config.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	config.NoCache = *noCache
+	config.NoTableScan = *noTableScan
+	config.NoStartupQuery = *noStartupQuery
+	if ws == "" {
+		ws = config.DefaultWorkspace
+	}
+	if _, err := azure.ParseCloud(*cloudName); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	config.Cloud = *cloudName
+	if *maxRows >= 0 {
+		config.MaxRows = maxRows
+	}
+
+	// A Data Explorer database takes the place of the workspace, and is
+	// identified as cluster/database in history and the status bar
+	if *cluster != "" {
+		if *database == "" {
+			fmt.Fprintln(os.Stderr, "Error: --database is required with --cluster")
+			return 1
+		}
+		config.Cluster = *cluster
+		config.Database = *database
+		ws = *cluster + "/" + *database
+	}
+
+	// Resolve the display timezone (the flag overrides the config without being saved)
+	tzName := *timezone
+	if tzName == "" {
+		tzName = config.Timezone
+	}
+	loc, err := ui.LoadLocation(tzName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	ui.SetDatetimeFormat(loc, config.DatetimeFormat)
+	ui.SetNumberFormat(config.DecimalPrecision, config.ThousandsSep)
+
+	outputFormat, err := parseOutputFormat(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	output := outputOptions{format: outputFormat, markdownWidth: *markdownWidth, templateWhole: *templateWhole}
+	if *outputTmpl != "" {
+		if output.template, err = parseOutputTemplate(*outputTmpl); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		output.format = formatTemplate
+	} else if *templateWhole {
+		fmt.Fprintln(os.Stderr, "Error: --template-result requires --template")
+		return 1
+	}
+
+	// Connectivity self-test
+	if *checkSetup || flag.Arg(0) == "doctor" {
+		return runCheck(ws, auth, config, os.Stdout)
+	}
+
+	// Batch mode
+	if *batch != "" {
+		if *failOnEmpty {
+			fmt.Fprintln(os.Stderr, "Error: --fail-on-empty requires -q and can't be used with --batch")
+			return 1
+		}
+		if ws == "" {
+			fmt.Fprintln(os.Stderr, "Error: workspace ID is required. Use -w flag, set AZURE_LOG_ANALYTICS_WORKSPACE_ID, or use --cluster and --database")
+			return 1
+		}
+		queryParams, err := parseParams(params)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if err := runBatch(ws, *batch, *batchOut, output, *failFast, queryParams, auth, config); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
 	// Non-interactive mode
 	if q != "" {
 		if ws == "" {
-			fmt.Fprintln(os.Stderr, "Error: workspace ID is required. Use -w flag or set AZURE_LOG_ANALYTICS_WORKSPACE_ID")
-			os.Exit(1)
+			fmt.Fprintln(os.Stderr, "Error: workspace ID is required. Use -w flag, set AZURE_LOG_ANALYTICS_WORKSPACE_ID, or use --cluster and --database")
+			return 1
+		}
+		queryParams, err := parseParams(params)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if *waitInterval <= 0 {
+			fmt.Fprintln(os.Stderr, "Error: --wait-interval must be positive")
+			return 1
+		}
+		return runNonInteractive(ws, q, output, queryParams, *waitForResults, *waitInterval, *saveResult, *report, *failOnEmpty, auth, config)
+	}
+	if *saveResult != "" || *report != "" {
+		fmt.Fprintln(os.Stderr, "Error: --save-result and --report require -q")
+		return 1
+	}
+	if *failOnEmpty {
+		fmt.Fprintln(os.Stderr, "Error: --fail-on-empty requires -q")
+		return 1
+	}
+
+	// Line-by-line mode for terminals where the full-screen UI isn't usable
+	if *repl {
+		if ws == "" {
+			fmt.Fprintln(os.Stderr, "Error: workspace ID is required. Use -w flag, set AZURE_LOG_ANALYTICS_WORKSPACE_ID, or use --cluster and --database")
+			return 1
+		}
+		queryParams, err := parseParams(params)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return runREPL(ws, queryParams, auth, config)
+	}
+
+	// Resolve theme (the flag overrides the config without being saved)
+	themeName := *theme
+	if themeName == "" {
+		themeName = config.Theme
+	}
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		ui.DisableColor()
+	} else if err := ui.ApplyTheme(themeName); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// A saved result to show in place of a live one
+	var snapshot *azure.Snapshot
+	if *loadResult != "" {
+		snapshot, err = azure.LoadSnapshot(*loadResult)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
 		}
-		runNonInteractive(ws, q, auth)
-		return
 	}
 
 	// Interactive mode
-	runInteractive(ws, auth)
+	return runInteractive(ws, auth, config, snapshot)
+}
+
+// parseParams converts --param flags into query parameters
+func parseParams(flags paramFlags) (map[string]interface{}, error) {
+	if len(flags) == 0 {
+		return nil, nil
+	}
+
+	params := make(map[string]interface{}, len(flags))
+	for _, f := range flags {
+		name, value, err := azure.ParseParameter(f)
+		if err != nil {
+			return nil, err
+		}
+		params[name] = value
+	}
+	return params, nil
 }
 
 func parseAuthMethod(method string) azure.AuthMethod {
@@ -75,18 +331,71 @@ func parseAuthMethod(method string) azure.AuthMethod {
 		return azure.AuthBrowser
 	case "managed-identity", "msi":
 		return azure.AuthManagedIdentity
+	case "env", "environment":
+		return azure.AuthEnvironment
 	default:
 		return azure.AuthDefault
 	}
 }
 
-func runInteractive(workspaceID string, auth azure.AuthMethod) {
+func manageHistory(exportPath string, clear bool) error {
+	history := azure.NewHistory(1000)
+	if err := history.Load(); err != nil {
+		// A corrupt file has been moved aside, leaving an empty history
+		var corrupt *azure.CorruptFileError
+		if !errors.As(err, &corrupt) {
+			return fmt.Errorf("failed to load history: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if exportPath != "" {
+		out := os.Stdout
+		if exportPath != "-" {
+			f, err := os.Create(exportPath)
+			if err != nil {
+				return fmt.Errorf("failed to create export file: %w", err)
+			}
+			defer f.Close()
+			out = f
+		}
+		if err := history.Export(out); err != nil {
+			return fmt.Errorf("failed to export history: %w", err)
+		}
+		if exportPath != "-" {
+			fmt.Fprintf(os.Stderr, "Exported %d history entries to %s\n", len(history.Entries), exportPath)
+		}
+	}
+
+	if clear {
+		fmt.Fprintf(os.Stderr, "Clear all %d history entries? This cannot be undone. [y/N] ", len(history.Entries))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			fmt.Fprintln(os.Stderr, "Aborted.")
+			return nil
+		}
+
+		history.Clear()
+		if err := history.Save(); err != nil {
+			return fmt.Errorf("failed to save history: %w", err)
+		}
+		fmt.Fprintln(os.Stderr, "History cleared.")
+	}
+
+	return nil
+}
+
+func runInteractive(workspaceID string, auth azure.AuthMethod, config *azure.Config, snapshot *azure.Snapshot) int {
 	// Print banner
 	fmt.Print(ui.LogoStyled())
 	fmt.Println()
 
 	// Create the model - Init() will auto-connect if workspace is provided
-	m := ui.NewModel(workspaceID, auth)
+	m := ui.NewModel(workspaceID, auth, config)
+	if snapshot != nil {
+		m.LoadSnapshot(snapshot)
+	}
 
 	// Create and run the program
 	p := tea.NewProgram(m,
@@ -96,66 +405,146 @@ func runInteractive(workspaceID string, auth azure.AuthMethod) {
 
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
+	return 0
 }
 
-func runNonInteractive(workspaceID, query string, authMethod azure.AuthMethod) {
-	// Create authenticator
-	auth, err := azure.NewAuthenticator(authMethod)
+// newQueryClient authenticates and creates a client for the workspace, or
+// for the Data Explorer database set in the config
+func newQueryClient(workspaceID string, authMethod azure.AuthMethod, config *azure.Config) (azure.QueryClient, error) {
+	auth, err := azure.NewCloudAuthenticator(authMethod, config.AzureCloud())
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Authentication failed: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	client, err := azure.NewQueryClient(auth.GetCredential(), workspaceID, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return client, nil
+}
+
+// Exit codes for -q, so scripts can tell a failed query from one that
+// matched nothing
+const (
+	exitOK         = 0
+	exitQueryError = 1
+	exitNoRows     = 2
+)
+
+// resultExitCode returns the exit code for a query that succeeded:
+// exitNoRows if it returned no rows and failOnEmpty is set. Without
+// failOnEmpty, --wait-for-results timing out with err errNoRows is a failure
+func resultExitCode(result *azure.QueryResult, err error, failOnEmpty bool) int {
+	if errors.Is(err, errNoRows) && !failOnEmpty {
+		return exitQueryError
 	}
+	if failOnEmpty && result.RowCount == 0 {
+		return exitNoRows
+	}
+	return exitOK
+}
 
-	// Create client
-	client, err := azure.NewLogAnalyticsClient(auth.GetCredential(), workspaceID)
+func runNonInteractive(workspaceID, query string, output outputOptions, params map[string]interface{}, maxWait, waitInterval time.Duration, savePath, reportPath string, failOnEmpty bool, authMethod azure.AuthMethod, config *azure.Config) int {
+	client, err := newQueryClient(workspaceID, authMethod, config)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create client: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitQueryError
 	}
 
+	// Stop waiting for results on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	// Execute query
 	fmt.Fprintf(os.Stderr, "Executing query...\n")
-	result, err := client.Query(context.Background(), query, nil)
-	if err != nil {
+	result, err := queryUntilRows(ctx, client, query, params, maxWait, waitInterval, os.Stderr)
+	if err != nil && !errors.Is(err, errNoRows) {
 		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
-		os.Exit(1)
+		return exitQueryError
 	}
 
-	// Print results as tab-separated values
 	if len(result.Tables) > 0 {
-		table := result.Tables[0]
+		if err := writeTable(os.Stdout, result.Tables[0], output); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write results: %v\n", err)
+			return exitQueryError
+		}
+	}
 
-		// Print header
-		for i, col := range table.Columns {
-			if i > 0 {
-				fmt.Print("\t")
-			}
-			fmt.Print(col.Name)
+	fmt.Fprintf(os.Stderr, "\n%d rows returned in %s\n", result.RowCount, result.Duration)
+	printResultWarnings(result)
+	if savePath != "" {
+		if err := azure.NewSnapshot(query, workspaceID, result).Save(savePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save result snapshot: %v\n", err)
+			return exitQueryError
+		}
+		fmt.Fprintf(os.Stderr, "Saved result snapshot to %s\n", savePath)
+	}
+	if reportPath != "" {
+		if err := writeReport(reportPath, ui.Report{Query: query, Workspace: workspaceID, Result: result}); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+			return exitQueryError
 		}
-		fmt.Println()
+		fmt.Fprintf(os.Stderr, "Saved report to %s\n", reportPath)
+	}
+	code := resultExitCode(result, err, failOnEmpty)
+	switch {
+	case errors.Is(err, errNoRows):
+		fmt.Fprintf(os.Stderr, "Error: no rows returned within %s\n", maxWait)
+	case code != exitOK:
+		fmt.Fprintln(os.Stderr, "Error: no rows returned")
+	}
+	return code
+}
 
-		// Print rows
-		for _, row := range table.Rows {
-			for i, cell := range row {
-				if i > 0 {
-					fmt.Print("\t")
-				}
-				fmt.Print(formatValue(cell))
-			}
-			fmt.Println()
+// printResultWarnings reports truncated or partial results on stderr
+func printResultWarnings(result *azure.QueryResult) {
+	if result.Truncated {
+		fmt.Fprintf(os.Stderr, "Warning: results truncated at %d rows (use --max-rows to change the limit)\n", result.RowCount)
+	}
+	if result.IsPartial() {
+		fmt.Fprintf(os.Stderr, "Warning: partial results, the data is incomplete: %s\n", result.PartialError)
+	}
+}
+
+// writeTSV writes a result table as tab-separated values with a header row
+func writeTSV(w io.Writer, table azure.Table) {
+	for i, col := range table.Columns {
+		if i > 0 {
+			fmt.Fprint(w, "\t")
 		}
+		fmt.Fprint(w, formatValue(col.Name, ""))
 	}
+	fmt.Fprintln(w)
 
-	fmt.Fprintf(os.Stderr, "\n%d rows returned in %s\n", result.RowCount, result.Duration)
+	for _, row := range table.Rows {
+		for i, cell := range row {
+			if i > 0 {
+				fmt.Fprint(w, "\t")
+			}
+			colType := ""
+			if i < len(table.Columns) {
+				colType = table.Columns[i].Type
+			}
+			fmt.Fprint(w, formatValue(cell, colType))
+		}
+		fmt.Fprintln(w)
+	}
 }
 
-func formatValue(v interface{}) string {
+func formatValue(v interface{}, colType string) string {
 	if v == nil {
 		return ""
 	}
-	return fmt.Sprintf("%v", v)
+	switch colType {
+	case "datetime":
+		return ui.FormatDatetime(v)
+	case "dynamic":
+		return ui.StripANSI(ui.FormatDynamic(v))
+	}
+	// Never pass terminal escape sequences from the data through to the output
+	return ui.StripANSI(fmt.Sprintf("%v", v))
 }
 
 func printHelp() {
@@ -170,18 +559,143 @@ OPTIONS:
     -w, --workspace <ID>    Azure Log Analytics Workspace ID
                             Can also be set via AZURE_LOG_ANALYTICS_WORKSPACE_ID
 
+    --cluster <CLUSTER>     Query an Azure Data Explorer (Kusto) cluster instead
+                            of a workspace, given as a URL or a name such as
+                            mycluster.westeurope
+    --database <NAME>       Data Explorer database to query (with --cluster)
+
     -q, --query <KQL>       Execute a KQL query in non-interactive mode
-                            Results are printed as tab-separated values
+                            Results are printed in the --format or --template
+                            output (tab-separated values by default)
+
+    --repl                  Read queries from stdin one per line (end a line
+                            with \ to continue it) and print results as aligned
+                            text, without the full-screen UI. For tmux panes,
+                            flaky SSH sessions and CI
+
+    --format <FORMAT>       Output format for -q and --batch results:
+                            - tsv   : Tab-separated values (default)
+                            - json  : A JSON array of row objects
+                            - jsonl : One JSON object per line (NDJSON)
+                            - markdown : A GitHub-flavored Markdown table
+    --markdown-width <N>    Truncate Markdown cells wider than N characters,
+                            with a note below the table (default: no limit)
+    --template <TMPL>       Write -q and --batch results with a Go text/template
+                            instead of --format, run once per row with the
+                            columns as fields: '{{.TimeGenerated}} {{.Message}}'
+                            Use {{index . "Column Name"}} for other names.
+                            Fields hold the values themselves, e.g. numbers
+                            for {{if gt .Count 10}}, and null is empty.
+                            Functions: json (encode a value as JSON) and
+                            default (e.g. {{default "-" .Level}} when empty)
+    --template-result       Run --template once with the whole result instead:
+                            .Columns lists the column names and .Rows the rows
+
+    --batch <FILE>          Run each query in FILE in order and exit
+                            Queries are separated by ;; lines, or by blank
+                            lines if the file has none. A leading // comment
+                            labels the query in the output
+    --batch-out <DIR>       Write each batch result to its own file in DIR
+                            instead of one combined report on stdout
+    --fail-fast             Stop the batch at the first failing query
+                            (default: run all and report failures at the end)
+
+    --wait-for-results <DURATION>
+                            With -q, retry while the query returns no rows for
+                            up to DURATION (e.g. 5m), for data that is still
+                            being ingested. Exits with status 1 if no rows
+                            appear, or 2 with --fail-on-empty
+    --wait-interval <DURATION>
+                            Time between retries (default: 15s)
+    --fail-on-empty         With -q, exit with status 2 when the query returns
+                            no rows, e.g. for 'azlogs ... && alert' checks.
+                            Not supported with --batch
+
+    --save-result <FILE>    With -q, also save the result as a JSON snapshot
+    --report <FILE>         With -q, also write the query, when it ran and its
+                            results as a report for postmortems: Markdown for
+                            .md files, a styled HTML page otherwise
+    --load-result <FILE>    Open a saved snapshot in the results view without
+                            re-running its query, e.g. to share evidence or
+                            work offline. Snapshots are marked as historical
+
+    --param <NAME=VALUE>    Declare a query parameter (repeatable, with -q)
+                            Use NAME:TYPE=VALUE for long, real, bool,
+                            datetime (RFC 3339) or timespan (e.g. 5m) values
 
     --auth <METHOD>         Authentication method:
                             - default   : Auto-detect (tries multiple methods)
                             - cli       : Use Azure CLI credentials
                             - browser   : Interactive browser login
                             - managed-identity : Azure Managed Identity
-
-    --version               Show version information
+                            - env       : Service principal or user from
+                                          AZURE_TENANT_ID, AZURE_CLIENT_ID and
+                                          AZURE_CLIENT_SECRET (or a certificate
+                                          or username and password), as in
+                                          containers and CI
+
+    --cloud <CLOUD>         Azure cloud to sign in to and query:
+                            - public : Azure public cloud (default)
+                            - usgov  : Azure US Government
+                            - china  : Azure China (21Vianet)
+                            With --auth cli, also run 'az cloud set' to match
+
+    --theme <NAME>          Color theme: dark, light, high-contrast
+                            Defaults to the theme set in the config file, or
+                            dark/light based on the terminal background
+
+    --config-dir <DIR>      Directory for config, history and templates
+                            Can also be set via AZLOGS_CONFIG_DIR
+                            (default: $XDG_CONFIG_HOME/azlogs or ~/.config/azlogs)
+
+    --profile <NAME>        Keep config, history and templates separate per
+                            profile, under <config dir>/profiles/<NAME>
+
+    --export-history <PATH> Write query history as JSON to PATH (- for stdout)
+    --clear-history         Clear query history (asks for confirmation)
+
+    --timezone <ZONE>       Show datetimes in ZONE: UTC, local or an IANA name
+                            such as Europe/Berlin (default: config, or UTC)
+
+    --max-rows <N>          Maximum rows kept per result, 0 for no limit
+                            (default: max_result_rows from config, 100000)
+
+    --no-cache              Always run queries instead of serving identical
+                            queries from the result cache
+
+    --no-startup-query      Don't run startup_query from config on connecting,
+                            leaving the editor empty
+
+    --no-table-scan         List tables for autocomplete only from the Usage
+                            table. Without it, workspaces with no Usage data
+                            fall back to searching the last day of all tables
+
+    -v, --verbose           Log diagnostics such as the auth method, endpoints,
+                            queries sent, timings and retries. Logs go to
+                            stderr with -q, --batch and --repl, and to
+                            azlogs.log in the config directory for the
+                            interactive UI
+    --log-file <FILE>       Write --verbose output to FILE instead
+
+    --no-color              Disable colors and syntax highlighting
+                            Also enabled when NO_COLOR is set
+
+    --check, doctor         Check each step of connecting without starting the
+                            UI: signing in with --auth, getting a Log Analytics
+                            token, running 'print 1' against the workspace and
+                            reaching Azure OpenAI. Prints PASS or FAIL with a
+                            hint for each. Exits 1 if a step that queries depend
+                            on failed; an Azure OpenAI failure is a warning
+
+    --version               Show the version, git commit, build date and Go version
     --help                  Show this help message
 
+EXIT CODES (with -q):
+    0   The query succeeded
+    1   The query failed, its results couldn't be written, or
+        --wait-for-results timed out without --fail-on-empty
+    2   The query returned no rows, with --fail-on-empty
+
 INTERACTIVE MODE:
     Run without -q to start the interactive TUI where you can:
     - Write and execute KQL queries
@@ -196,6 +710,26 @@ EXAMPLES:
     # Execute a query and exit
     azlogs -w "your-workspace-id" -q "AzureActivity | take 10"
 
+    # Pass values as query parameters instead of concatenating strings
+    azlogs -w "your-workspace-id" --param user=alice --param limit:long=20 \
+        -q "SigninLogs | where UserPrincipalName startswith user | take limit"
+
+    # Run a diagnostic suite, one result file per query
+    azlogs -w "your-workspace-id" --batch diagnostics.kql --batch-out results/
+
+    # Query an Azure Data Explorer database
+    azlogs --cluster help --database Samples -q "StormEvents | take 10"
+
+    # Capture a result during an incident and review it later
+    azlogs -w "your-workspace-id" -q "AzureActivity | take 100" --save-result incident.json
+    azlogs --load-result incident.json
+
+    # Attach the query and what it returned to a postmortem
+    azlogs -w "your-workspace-id" -q "AzureActivity | take 100" --report incident.html
+
+    # Line-by-line mode without the full-screen UI
+    azlogs -w "your-workspace-id" --repl
+
     # Use Azure CLI authentication
     azlogs -w "your-workspace-id" --auth cli
 
@@ -209,6 +743,12 @@ KEYBOARD SHORTCUTS (Interactive Mode):
     F1                Show help
     F2                Show query history
     F3                Change workspace
+    F7                Select time range
+    F8                Explore table schemas
+    F9                Show bookmarks
+    F10, Alt+L        Browse the catalog of common queries
+    Ctrl+O            Bookmark the current query
+    Alt+E             Show the full last error
     Ctrl+Q            Quit
 
 For more information, visit: https://github.com/codyseavey/tools/azlogs
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestResultExitCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		rows        int
+		err         error
+		failOnEmpty bool
+		expected    int
+	}{
+		{"rows", 3, nil, true, exitOK},
+		{"no rows", 0, nil, false, exitOK},
+		{"no rows with --fail-on-empty", 0, nil, true, exitNoRows},
+		{"wait timed out", 0, errNoRows, false, exitQueryError},
+		{"wait timed out with --fail-on-empty", 0, errNoRows, true, exitNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resultExitCode(&azure.QueryResult{RowCount: tt.rows}, tt.err, tt.failOnEmpty); got != tt.expected {
+				t.Errorf("Expected exit code %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+	"github.com/codyseavey/tools/azlogs/internal/ui"
+)
+
+// Output formats for non-interactive results
+const (
+	formatTSV      = "tsv"
+	formatJSON     = "json"
+	formatJSONL    = "jsonl"
+	formatMarkdown = "markdown"
+)
+
+// outputOptions selects how -q and --batch results are written
+type outputOptions struct {
+	format        string
+	markdownWidth int                // Truncate wider cells in Markdown output (0 for no limit)
+	template      *template.Template // --template, with the template format
+	templateWhole bool               // --template-result: run the template once per result
+}
+
+// parseOutputFormat validates a --format value
+func parseOutputFormat(s string) (string, error) {
+	switch f := strings.ToLower(s); f {
+	case formatTSV, formatJSON, formatJSONL, formatMarkdown:
+		return f, nil
+	case "ndjson":
+		return formatJSONL, nil
+	case "md":
+		return formatMarkdown, nil
+	}
+	return "", fmt.Errorf("invalid format %q (use tsv, json, jsonl or markdown)", s)
+}
+
+// writeTable writes a result table in the output format of opts. Duplicate
+// column names are renamed as in the UI, so JSON keys and template fields
+// don't collide
+func writeTable(w io.Writer, table azure.Table, opts outputOptions) error {
+	table.Columns, _ = ui.UniqueColumns(table.Columns)
+	switch opts.format {
+	case formatJSON:
+		return writeJSON(w, table)
+	case formatJSONL:
+		return writeJSONL(w, table)
+	case formatMarkdown:
+		_, err := io.WriteString(w, ui.MarkdownTable(table.Columns, table.Rows, opts.markdownWidth))
+		return err
+	case formatTemplate:
+		return writeTemplate(w, table, opts.template, opts.templateWhole)
+	default:
+		writeTSV(w, table)
+		return nil
+	}
+}
+
+// writeReport writes a report to path in the format its extension selects
+func writeReport(path string, r ui.Report) error {
+	content, err := r.Render(ui.ReportFormat(path))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// writeJSON writes a result table as a JSON array of row objects
+func writeJSON(w io.Writer, table azure.Table) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("[")
+	for i, row := range table.Rows {
+		if i > 0 {
+			bw.WriteString(",")
+		}
+		bw.WriteString("\n  ")
+		if err := writeJSONRow(bw, table.Columns, row); err != nil {
+			return err
+		}
+	}
+	if len(table.Rows) > 0 {
+		bw.WriteString("\n")
+	}
+	bw.WriteString("]\n")
+	return bw.Flush()
+}
+
+// writeJSONL writes a result table as newline-delimited JSON, one object
+// per row, flushing as it goes so consumers see rows as they're written
+func writeJSONL(w io.Writer, table azure.Table) error {
+	bw := bufio.NewWriter(w)
+	for _, row := range table.Rows {
+		if err := writeJSONRow(bw, table.Columns, row); err != nil {
+			return err
+		}
+		bw.WriteString("\n")
+		if bw.Buffered() > 64*1024 {
+			if err := bw.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// writeJSONRow writes one row as a JSON object with keys in column order
+func writeJSONRow(w *bufio.Writer, columns []azure.Column, row []interface{}) error {
+	w.WriteString("{")
+	for i, col := range columns {
+		if i > 0 {
+			w.WriteString(",")
+		}
+		name, err := json.Marshal(col.Name)
+		if err != nil {
+			return err
+		}
+		var cell interface{}
+		if i < len(row) {
+			cell = row[i]
+		}
+		value, err := json.Marshal(jsonValue(cell, col.Type))
+		if err != nil {
+			return fmt.Errorf("column %s: %w", col.Name, err)
+		}
+		w.Write(name)
+		w.WriteString(":")
+		w.Write(value)
+	}
+	w.WriteString("}")
+	return nil
+}
+
+// jsonValue coerces a cell to the value written in JSON output: numbers
+// and booleans keep their type, dynamic values holding JSON are embedded
+// rather than quoted, and everything else is written as returned
+func jsonValue(v interface{}, colType string) interface{} {
+	if s, ok := v.(string); ok && colType == "dynamic" && json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	return v
+}
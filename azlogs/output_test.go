@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestWriteTable_JSON(t *testing.T) {
+	table := azure.Table{
+		Columns: []azure.Column{
+			{Name: "Name", Type: "string"},
+			{Name: "Count", Type: "long"},
+			{Name: "Props", Type: "dynamic"},
+			{Name: "Ok", Type: "bool"},
+		},
+		Rows: [][]interface{}{
+			{"a", 3.0, `{"k":[1,2]}`, true},
+			{"b", nil, "not json", false},
+		},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{formatJSONL, `{"Name":"a","Count":3,"Props":{"k":[1,2]},"Ok":true}` + "\n" +
+			`{"Name":"b","Count":null,"Props":"not json","Ok":false}` + "\n"},
+		{formatJSON, "[\n  " + `{"Name":"a","Count":3,"Props":{"k":[1,2]},"Ok":true}` + ",\n  " +
+			`{"Name":"b","Count":null,"Props":"not json","Ok":false}` + "\n]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeTable(&buf, table, outputOptions{format: tt.format}); err != nil {
+				t.Fatalf("writeTable failed: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	if f, err := parseOutputFormat("NDJSON"); err != nil || f != formatJSONL {
+		t.Errorf("Expected jsonl, got %q (%v)", f, err)
+	}
+	if f, err := parseOutputFormat("md"); err != nil || f != formatMarkdown {
+		t.Errorf("Expected markdown, got %q (%v)", f, err)
+	}
+	if _, err := parseOutputFormat("xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestWriteTable_MarkdownWidth(t *testing.T) {
+	table := azure.Table{
+		Columns: []azure.Column{{Name: "Message", Type: "string"}},
+		Rows:    [][]interface{}{{"a very long message"}},
+	}
+
+	var full, truncated bytes.Buffer
+	if err := writeTable(&full, table, outputOptions{format: formatMarkdown}); err != nil {
+		t.Fatalf("writeTable failed: %v", err)
+	}
+	if err := writeTable(&truncated, table, outputOptions{format: formatMarkdown, markdownWidth: 6}); err != nil {
+		t.Fatalf("writeTable failed: %v", err)
+	}
+	if !bytes.Contains(full.Bytes(), []byte("a very long message")) {
+		t.Errorf("Expected the full cell without a width, got %q", full.String())
+	}
+	if bytes.Contains(truncated.Bytes(), []byte("a very long message")) {
+		t.Errorf("Expected the cell truncated to the width, got %q", truncated.String())
+	}
+}
+
+func TestWriteTable_DuplicateColumns(t *testing.T) {
+	table := azure.Table{
+		Columns: []azure.Column{{Name: "Name", Type: "string"}, {Name: "Name", Type: "string"}},
+		Rows:    [][]interface{}{{"web-01", "alice"}},
+	}
+	tmpl, err := parseOutputTemplate("{{.Name}} {{.Name_1}}")
+	if err != nil {
+		t.Fatalf("parseOutputTemplate failed: %v", err)
+	}
+
+	tests := []struct {
+		output   outputOptions
+		expected string
+	}{
+		{outputOptions{format: formatJSONL}, `{"Name":"web-01","Name_1":"alice"}` + "\n"},
+		{outputOptions{format: formatTemplate, template: tmpl}, "web-01 alice\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := writeTable(&buf, table, tt.output); err != nil {
+			t.Fatalf("writeTable failed: %v", err)
+		}
+		if buf.String() != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, buf.String())
+		}
+	}
+	if table.Columns[1].Name != "Name" {
+		t.Error("Expected the table's own columns left alone")
+	}
+}
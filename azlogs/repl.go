@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+	"github.com/codyseavey/tools/azlogs/internal/ui"
+)
+
+// REPL prompts, written to stderr so stdout only carries results
+const (
+	replPrompt         = "kql> "
+	replContinuePrompt = "...> "
+)
+
+// replMaxColumnWidth caps the width of a column in REPL output
+const replMaxColumnWidth = 40
+
+// replSession reads queries line by line and prints their results as text
+type replSession struct {
+	client      querier
+	workspaceID string
+	params      map[string]interface{}
+	history     *azure.History // nil to keep no history
+	out         io.Writer      // Results
+	errOut      io.Writer      // Prompts, timings and errors
+}
+
+// run reads queries from in until EOF or a quit command. A line ending in a
+// backslash continues the query on the next line.
+func (s *replSession) run(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	fmt.Fprint(s.errOut, replPrompt)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if cont, ok := strings.CutSuffix(line, `\`); ok {
+			lines = append(lines, cont)
+			fmt.Fprint(s.errOut, replContinuePrompt)
+			continue
+		}
+		lines = append(lines, line)
+		query := strings.TrimSpace(strings.Join(lines, "\n"))
+		lines = nil
+
+		switch query {
+		case "":
+		case ":q", ":quit", "quit", "exit":
+			return
+		case ":history":
+			s.printHistory()
+		case ":help":
+			fmt.Fprintln(s.errOut, "Enter a KQL query on one line, or end lines with \\ to continue it.")
+			fmt.Fprintln(s.errOut, "Commands: :history (recent queries), :quit (or Ctrl+D)")
+		default:
+			s.runQuery(query)
+		}
+		fmt.Fprint(s.errOut, replPrompt)
+	}
+	fmt.Fprintln(s.errOut)
+}
+
+// runQuery runs one query and prints its first result table. Ctrl+C cancels
+// the query without leaving the REPL.
+func (s *replSession) runQuery(query string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	result, err := s.client.QueryWithParameters(ctx, query, nil, s.params)
+	entry := azure.HistoryEntry{
+		Query:      query,
+		Workspace:  s.workspaceID,
+		ExecutedAt: start,
+		Duration:   time.Since(start).String(),
+		WasSuccess: err == nil,
+	}
+	if err != nil {
+		entry.ErrorMsg = err.Error()
+		fmt.Fprintf(s.errOut, "Error: %v\n", err)
+	} else {
+		entry.RowCount = result.RowCount
+		if len(result.Tables) > 0 {
+			writeAligned(s.out, result.Tables[0], replMaxColumnWidth)
+		}
+		fmt.Fprintf(s.errOut, "(%d rows in %s)\n", result.RowCount, result.Duration.Round(time.Millisecond))
+		if result.Truncated {
+			fmt.Fprintf(s.errOut, "Warning: results truncated at %d rows\n", result.RowCount)
+		}
+		if result.IsPartial() {
+			fmt.Fprintf(s.errOut, "Warning: partial results, the data is incomplete: %s\n", result.PartialError)
+		}
+	}
+
+	if s.history != nil {
+		s.history.Add(entry)
+		if err := s.history.Save(); err != nil {
+			fmt.Fprintf(s.errOut, "Warning: failed to save history: %v\n", err)
+		}
+	}
+}
+
+// printHistory lists the most recent queries, oldest first
+func (s *replSession) printHistory() {
+	if s.history == nil {
+		return
+	}
+	recent := s.history.GetRecent(10)
+	for i := len(recent) - 1; i >= 0; i-- {
+		fmt.Fprintf(s.errOut, "%s  %s\n", recent[i].ExecutedAt.Format("15:04:05"),
+			strings.ReplaceAll(recent[i].Query, "\n", " "))
+	}
+}
+
+// writeAligned writes a result table as text columns padded to a common
+// width, truncating values longer than maxWidth
+func writeAligned(w io.Writer, table azure.Table, maxWidth int) {
+	header := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		header[i] = col.Name
+	}
+	rows := make([][]string, len(table.Rows))
+	for r, row := range table.Rows {
+		rows[r] = make([]string, len(table.Columns))
+		for i, col := range table.Columns {
+			if i < len(row) {
+				rows[r][i] = formatValue(row[i], col.Type)
+			}
+		}
+	}
+	io.WriteString(w, ui.AlignedText(header, rows, maxWidth))
+}
+
+// runREPL connects to the workspace and reads queries from stdin
+func runREPL(workspaceID string, params map[string]interface{}, authMethod azure.AuthMethod, config *azure.Config) int {
+	client, err := newQueryClient(workspaceID, authMethod, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	history := azure.NewHistory(1000)
+	history.Load()
+
+	fmt.Fprintf(os.Stderr, "azlogs %s - querying %s. Type :help for commands, Ctrl+D to exit.\n", version, workspaceID)
+	session := &replSession{
+		client:      client,
+		workspaceID: workspaceID,
+		params:      params,
+		history:     history,
+		out:         os.Stdout,
+		errOut:      os.Stderr,
+	}
+	session.run(os.Stdin)
+	return 0
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// recordingQuerier returns one row per query and records the queries run
+type recordingQuerier struct {
+	queries []string
+}
+
+func (r *recordingQuerier) QueryWithParameters(ctx context.Context, query string, timespan *azure.TimeSpan, params map[string]interface{}) (*azure.QueryResult, error) {
+	r.queries = append(r.queries, query)
+	return &azure.QueryResult{
+		RowCount: 1,
+		Tables: []azure.Table{{
+			Columns: []azure.Column{{Name: "Name", Type: "string"}, {Name: "Count", Type: "long"}},
+			Rows:    [][]interface{}{{"web-1", 42.0}},
+		}},
+	}, nil
+}
+
+func TestReplSession_Run(t *testing.T) {
+	client := &recordingQuerier{}
+	var out, errOut bytes.Buffer
+	session := &replSession{client: client, out: &out, errOut: &errOut}
+
+	session.run(strings.NewReader("T | take 1\n\nT \\\n| count\n:quit\nNotRun\n"))
+
+	expected := []string{"T | take 1", "T \n| count"}
+	if len(client.queries) != len(expected) {
+		t.Fatalf("Expected queries %q, got %q", expected, client.queries)
+	}
+	for i := range expected {
+		if client.queries[i] != expected[i] {
+			t.Errorf("Expected query %q, got %q", expected[i], client.queries[i])
+		}
+	}
+	if strings.Contains(out.String(), replPrompt) {
+		t.Error("Expected prompts on stderr, not in the results")
+	}
+}
+
+func TestWriteAligned(t *testing.T) {
+	table := azure.Table{
+		Columns: []azure.Column{{Name: "Name", Type: "string"}, {Name: "Message", Type: "string"}},
+		Rows: [][]interface{}{
+			{"web-1", "short"},
+			{"a-much-longer-name", "line one\nline two"},
+		},
+	}
+
+	var buf bytes.Buffer
+	writeAligned(&buf, table, 10)
+
+	expected := "Name        Message\n" +
+		"----------  ----------\n" +
+		"web-1       short\n" +
+		"a-much-lo…  line one …\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
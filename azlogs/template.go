@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/template"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+	"github.com/codyseavey/tools/azlogs/internal/ui"
+)
+
+// formatTemplate writes results with the --template text/template
+const formatTemplate = "template"
+
+// templateFuncs are the functions available to --template templates
+var templateFuncs = template.FuncMap{
+	// json encodes a value as JSON, e.g. to quote a field in JSON output
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// default returns def when the value is empty: {{default "-" .Level}}
+	"default": func(def, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+}
+
+// templateResult is the data a --template-result template runs with
+type templateResult struct {
+	Columns []string
+	Rows    []map[string]interface{}
+}
+
+// parseOutputTemplate parses a --template value. Unknown column names are
+// an error rather than printing "<no value>"
+func parseOutputTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// templateValue returns a cell as templates see it: its own value rather
+// than its TSV text, so numbers compare as numbers and dynamic values keep
+// their fields. Whole long and int values are int64, null is "" and
+// strings have terminal escape sequences stripped
+func templateValue(v interface{}, colType string) interface{} {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return ui.StripANSI(val)
+	case float64:
+		if (colType == "long" || colType == "int") && val == math.Trunc(val) {
+			return int64(val)
+		}
+	}
+	return v
+}
+
+// writeTemplate writes a result table with a template, run once per row
+// with the columns as fields, or once with the whole result if whole is set.
+// A newline follows each row unless the template ends with one
+func writeTemplate(w io.Writer, table azure.Table, tmpl *template.Template, whole bool) error {
+	rows := make([]map[string]interface{}, len(table.Rows))
+	for i, row := range table.Rows {
+		rows[i] = make(map[string]interface{}, len(table.Columns))
+		for j, col := range table.Columns {
+			var cell interface{}
+			if j < len(row) {
+				cell = row[j]
+			}
+			rows[i][col.Name] = templateValue(cell, col.Type)
+		}
+	}
+
+	if whole {
+		columns := make([]string, len(table.Columns))
+		for i, col := range table.Columns {
+			columns[i] = col.Name
+		}
+		return tmpl.Execute(w, templateResult{Columns: columns, Rows: rows})
+	}
+
+	newline := !strings.HasSuffix(tmpl.Root.String(), "\n")
+	for i, row := range rows {
+		if err := tmpl.Execute(w, row); err != nil {
+			return fmt.Errorf("row %d: %w", i+1, err)
+		}
+		if newline {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
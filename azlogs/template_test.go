@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+func TestWriteTemplate(t *testing.T) {
+	table := azure.Table{
+		Columns: []azure.Column{
+			{Name: "Name", Type: "string"},
+			{Name: "Count", Type: "long"},
+			{Name: "Level", Type: "string"},
+			{Name: "Props", Type: "dynamic"},
+		},
+		Rows: [][]interface{}{
+			{"a", 3.0, "Error", map[string]interface{}{"region": "westeurope"}},
+			{`b "quoted"`, nil, nil, nil},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		whole    bool
+		expected string
+	}{
+		{"per row", `{{.Name}}: {{.Count}} {{default "-" .Level}}`, false, "a: 3 Error\nb \"quoted\":  -\n"},
+		{"trailing newline kept", "{{.Name}}\n", false, "a\nb \"quoted\"\n"},
+		{"json", `{"name":{{json .Name}}}`, false, `{"name":"a"}` + "\n" + `{"name":"b \"quoted\""}` + "\n"},
+		{"raw values", `{{if .Props}}{{.Props.region}} {{json .Count}}{{end}}`, false, "westeurope 3\n\n"},
+		{"whole result", `{{len .Rows}} rows of {{index .Columns 0}}{{range .Rows}} {{.Name}}{{end}}`, true, `2 rows of Name a b "quoted"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseOutputTemplate(tt.text)
+			if err != nil {
+				t.Fatalf("parseOutputTemplate failed: %v", err)
+			}
+			var buf bytes.Buffer
+			output := outputOptions{format: formatTemplate, template: tmpl, templateWhole: tt.whole}
+			if err := writeTable(&buf, table, output); err != nil {
+				t.Fatalf("writeTable failed: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestWriteTemplate_UnknownColumn(t *testing.T) {
+	tmpl, err := parseOutputTemplate("{{.Nmae}}")
+	if err != nil {
+		t.Fatalf("parseOutputTemplate failed: %v", err)
+	}
+	table := azure.Table{Columns: []azure.Column{{Name: "Name", Type: "string"}}, Rows: [][]interface{}{{"a"}}}
+	if err := writeTemplate(&bytes.Buffer{}, table, tmpl, false); err == nil {
+		t.Error("Expected an error for a column the result doesn't have")
+	}
+	if _, err := parseOutputTemplate("{{.Name"); err == nil {
+		t.Error("Expected an error for an invalid template")
+	}
+}
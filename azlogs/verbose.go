@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// defaultLogFile is where the interactive UI logs with --verbose, as the
+// full-screen UI owns the terminal
+func defaultLogFile() string {
+	return filepath.Join(azure.ConfigDir(), "azlogs.log")
+}
+
+// setupVerboseLog enables diagnostic logging to logFile, or to stderr when
+// logFile is empty. It returns a function that closes the log file.
+func setupVerboseLog(logFile string) (func(), error) {
+	var w io.Writer = os.Stderr
+	closeLog := func() {}
+	if logFile != "" {
+		if dir := filepath.Dir(logFile); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, err
+			}
+		}
+		f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		w = f
+		closeLog = func() { f.Close() }
+	}
+
+	azure.SetLogger(azure.NewVerboseLogger(w))
+	azure.Logger().Debug("azlogs starting", "version", version, "config_dir", azure.ConfigDir())
+	return closeLog, nil
+}
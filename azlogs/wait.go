@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// errNoRows is returned when --wait-for-results gives up
+var errNoRows = errors.New("no rows returned before the wait timed out")
+
+// querier runs a parameterized query; implemented by azure.QueryClient
+type querier interface {
+	QueryWithParameters(ctx context.Context, query string, timespan *azure.TimeSpan, params map[string]interface{}) (*azure.QueryResult, error)
+}
+
+// queryUntilRows runs a query, retrying every interval while it returns no
+// rows until maxWait has passed. With maxWait 0 the query runs once.
+// Progress is reported to progress. The last result is returned along with
+// errNoRows if rows never appeared.
+func queryUntilRows(ctx context.Context, client querier, query string, params map[string]interface{}, maxWait, interval time.Duration, progress io.Writer) (*azure.QueryResult, error) {
+	deadline := time.Now().Add(maxWait)
+	for attempt := 1; ; attempt++ {
+		result, err := client.QueryWithParameters(ctx, query, nil, params)
+		if err != nil || maxWait <= 0 || result.RowCount > 0 {
+			return result, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return result, errNoRows
+		}
+		wait := interval
+		if wait > remaining {
+			wait = remaining
+		}
+		fmt.Fprintf(progress, "No rows yet (attempt %d), retrying in %s (%s left)...\n",
+			attempt, wait.Round(time.Second), remaining.Round(time.Second))
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/codyseavey/tools/azlogs/internal/azure"
+)
+
+// fakeQuerier returns the given row counts in turn
+type fakeQuerier struct {
+	rowCounts []int
+	calls     int
+}
+
+func (f *fakeQuerier) QueryWithParameters(ctx context.Context, query string, timespan *azure.TimeSpan, params map[string]interface{}) (*azure.QueryResult, error) {
+	n := f.rowCounts[len(f.rowCounts)-1]
+	if f.calls < len(f.rowCounts) {
+		n = f.rowCounts[f.calls]
+	}
+	f.calls++
+	return &azure.QueryResult{RowCount: n}, nil
+}
+
+func TestQueryUntilRows(t *testing.T) {
+	tests := []struct {
+		name      string
+		rowCounts []int
+		maxWait   time.Duration
+		wantCalls int
+		wantErr   error
+	}{
+		{"rows on first try", []int{3}, time.Second, 1, nil},
+		{"rows after retries", []int{0, 0, 2}, time.Second, 3, nil},
+		{"no waiting by default", []int{0}, 0, 1, nil},
+		{"gives up after max wait", []int{0}, 25 * time.Millisecond, 0, errNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &fakeQuerier{rowCounts: tt.rowCounts}
+			_, err := queryUntilRows(context.Background(), q, "T", nil, tt.maxWait, 10*time.Millisecond, io.Discard)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantCalls > 0 && q.calls != tt.wantCalls {
+				t.Errorf("Expected %d calls, got %d", tt.wantCalls, q.calls)
+			}
+		})
+	}
+}
+
+func TestQueryUntilRows_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	q := &fakeQuerier{rowCounts: []int{0}}
+	if _, err := queryUntilRows(ctx, q, "T", nil, time.Minute, time.Minute, io.Discard); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
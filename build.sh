@@ -160,8 +160,16 @@ build_binary() {
 
     log_info "Building $name..."
 
+    # Stamp tools that report their build with --version
+    local ldflags="-s -w"
+    if [ -d "internal/buildinfo" ]; then
+        local pkg
+        pkg="$(go list -m)/internal/buildinfo"
+        ldflags="$ldflags -X $pkg.Commit=$(git rev-parse HEAD 2>/dev/null) -X $pkg.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+    fi
+
     GOOS="$TARGET_OS" GOARCH="$TARGET_ARCH" go build \
-        -ldflags="-s -w" \
+        -ldflags="$ldflags" \
         -o "$output_path" \
         "$src_path"
 
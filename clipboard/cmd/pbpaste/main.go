@@ -13,7 +13,7 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/codyseavey/tools/clipboard/internal/clipboard"
+	"github.com/codyseavey/tools/clipboard/clipboard"
 )
 
 func main() {